@@ -1,27 +1,46 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
+	"slices"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/Krokozabra213/test_api/internal/admin"
+	"github.com/Krokozabra213/test_api/internal/bots"
 	"github.com/Krokozabra213/test_api/internal/business"
 	"github.com/Krokozabra213/test_api/internal/config"
+	graphqlhandler "github.com/Krokozabra213/test_api/internal/delivery/graphql"
+	grpchandler "github.com/Krokozabra213/test_api/internal/delivery/grpc"
 	handler "github.com/Krokozabra213/test_api/internal/delivery/http"
+	"github.com/Krokozabra213/test_api/internal/delivery/http/middleware"
+	"github.com/Krokozabra213/test_api/internal/events"
+	"github.com/Krokozabra213/test_api/internal/outbox"
 	"github.com/Krokozabra213/test_api/internal/repository/postgres"
 	"github.com/Krokozabra213/test_api/internal/server"
+	"github.com/Krokozabra213/test_api/internal/webhook"
+	"github.com/Krokozabra213/test_api/pkg/blobstore"
 	postgresclient "github.com/Krokozabra213/test_api/pkg/database/postgres-client"
 	"github.com/Krokozabra213/test_api/pkg/logger"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
 )
 
 const (
 	configFile      = "configs/main.yml"
 	envFile         = ".env"
 	shutdownTimeout = 5 * time.Second
+
+	// eventBufferSize is the number of events a live subscriber may lag behind.
+	eventBufferSize = 64
 )
 
 func main() {
@@ -33,17 +52,20 @@ func main() {
 
 func run() error {
 	// Config
-	cfg, err := config.Init(configFile, envFile)
+	loader := config.NewLoader(config.File(configFile), config.Env(envFile), config.Flags(os.Args[1:]))
+	cfg, err := loader.Load()
+	if errors.Is(err, pflag.ErrHelp) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
-	if cfg.App.Environment == "local" {
-		cfg.Postgres.Host = "localhost"
-	}
+	localOverrides(cfg)
 
 	// Logger
-	log := logger.Init(cfg.App.Environment)
+	log, logLevel := logger.Init(cfg.App.Environment)
+	setLogLevel(logLevel, cfg)
 	log.Info("initialized config", "config", cfg.LogValue())
 	log.Info("starting application")
 
@@ -59,8 +81,17 @@ func run() error {
 		cfg.Postgres.MaxIdleConns,
 		cfg.Postgres.ConnMaxLifetime,
 	)
+	pgConfig = pgConfig.WithURL(cfg.Postgres.URL).
+		WithSessionParams(cfg.Postgres.ApplicationName, cfg.Postgres.StatementTimeout, cfg.Postgres.TargetSessionAttrs).
+		WithReplicas(cfg.Postgres.Replicas).
+		WithRetry(cfg.Postgres.ConnectAttempts, cfg.Postgres.ConnectBackoff, cfg.Postgres.ConnectMaxBackoff).
+		WithHealthCheck(cfg.Postgres.HealthCheckInterval).
+		WithTxRetry(cfg.Postgres.TxAttempts, cfg.Postgres.TxBackoff, cfg.Postgres.TxMaxBackoff)
 
-	db, err := postgresclient.New(pgConfig)
+	// Waiting for the database is interrupted by the shutdown signals
+	connectCtx, stopConnect := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	db, err := postgresclient.New(connectCtx, pgConfig, log)
+	stopConnect()
 	if err != nil {
 		return err
 	}
@@ -71,26 +102,165 @@ func run() error {
 	}()
 	log.Info("connected to postgres")
 
+	// Blob storage
+	blobStore, err := newBlobStore(cfg.Storage)
+	if err != nil {
+		return err
+	}
+	log.Info("initialized blob storage", "driver", cfg.Storage.Driver)
+
 	// Dependencies
 	repo := postgres.NewPostgresRepository(db)
-	biz := business.New(log, repo, repo)
+	broker := events.NewBroker(log, eventBufferSize)
+	bizConfig := business.Config{
+		Attachments: business.AttachmentLimits{
+			MaxFileSize:  int64(cfg.Attachments.MaxFileMegabytes) << 20,
+			MaxFiles:     cfg.Attachments.MaxFiles,
+			AllowedTypes: cfg.Attachments.AllowedTypes,
+		},
+		MaxPins:            cfg.Chats.MaxPins,
+		RestoreRetention:   cfg.Chats.RestoreRetention,
+		PurgeBatchSize:     cfg.Chats.PurgeBatchSize,
+		BotCallbackTimeout: cfg.Bots.CallbackTimeout,
+	}
+	// Webhook and bot callback URLs reach internal addresses only in the allowed networks
+	allowedNetworks := make([]netip.Prefix, 0, len(cfg.Webhooks.AllowedNetworks))
+	for _, network := range cfg.Webhooks.AllowedNetworks {
+		allowedNetworks = append(allowedNetworks, netip.MustParsePrefix(network))
+	}
+	subscriberClient := webhook.NewHTTPClient(allowedNetworks)
+	biz := business.New(log, repo, repo, repo, repo, repo, repo, repo, blobStore, bots.NewHTTPCaller(subscriberClient), bizConfig)
+	dispatcher := webhook.NewDispatcher(log, repo, subscriberClient, webhook.Config{
+		PollInterval:    cfg.Webhooks.PollInterval,
+		BatchSize:       cfg.Webhooks.BatchSize,
+		Timeout:         cfg.Webhooks.Timeout,
+		MaxAttempts:     cfg.Webhooks.MaxAttempts,
+		RetryBackoff:    cfg.Webhooks.RetryBackoff,
+		MaxRetryBackoff: cfg.Webhooks.MaxRetryBackoff,
+		DisableAfter:    cfg.Webhooks.DisableAfter,
+	})
+	// Webhook deliveries are queued first: if that fails the event is retried
+	// before live subscribers see it
+	publishers := outbox.Publishers{dispatcher, broker}
+	relay := outbox.NewRelay(log, repo, publishers, outbox.Config{
+		PollInterval:    cfg.Outbox.PollInterval,
+		BatchSize:       cfg.Outbox.BatchSize,
+		MaxAttempts:     cfg.Outbox.MaxAttempts,
+		RetryBackoff:    cfg.Outbox.RetryBackoff,
+		MaxRetryBackoff: cfg.Outbox.MaxRetryBackoff,
+		Retention:       cfg.Outbox.Retention,
+		PurgeInterval:   cfg.Outbox.PurgeInterval,
+	})
+
+	// Background workers, stopped before the database is closed
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	defer func() {
+		stopWorkers()
+		workers.Wait()
+		biz.WaitCallbacks()
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		biz.RunChatPurger(workersCtx, cfg.Chats.PurgeInterval)
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		relay.Run(workersCtx)
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		dispatcher.Run(workersCtx)
+	}()
 
 	// Router
 	router := http.NewServeMux()
-	handler.New(router, log, biz)
+	routeLimits := make(map[string]handler.RouteLimits, len(cfg.HTTP.Routes.Overrides))
+	for _, override := range cfg.HTTP.Routes.Overrides {
+		routeLimits[override.Pattern] = handler.RouteLimits{
+			Timeout:      override.Timeout,
+			MaxBodyBytes: override.MaxBodyBytes,
+		}
+	}
+	handler.New(router, log, biz, handler.Options{
+		Deprecation: handler.Deprecation{
+			DeprecatedAt: cfg.HTTP.LegacyRoutes.DeprecatedAt,
+			SunsetAt:     cfg.HTTP.LegacyRoutes.SunsetAt,
+		},
+		Limits: handler.RouteLimits{
+			Timeout:      cfg.HTTP.Routes.Timeout,
+			MaxBodyBytes: cfg.HTTP.Routes.MaxBodyBytes,
+		},
+		RouteLimits: routeLimits,
+		Readiness: map[string]handler.ReadinessCheck{
+			"postgres": db.Ready,
+		},
+	})
+	graphqlhandler.New(router, log, biz, broker, graphqlhandler.Limits{
+		MaxDepth:      cfg.GraphQL.MaxDepth,
+		MaxComplexity: cfg.GraphQL.MaxComplexity,
+	})
+
+	var httpHandler http.Handler = router
+	if cfg.HTTP.Compression.Enabled {
+		httpHandler = middleware.Compress(cfg.HTTP.Compression.MinSize)(httpHandler)
+	}
+	cors := middleware.NewCORS(corsOptions(cfg.HTTP.CORS), router)
+	httpHandler = cors.Middleware()(httpHandler)
 
 	// Server
-	srv := server.NewServer(cfg, router)
+	srv, err := server.NewServer(cfg, httpHandler, log)
+	if err != nil {
+		return err
+	}
+
+	// gRPC server
+	grpcServer := grpc.NewServer()
+	grpchandler.New(grpcServer, log, biz, broker)
+	grpcSrv := server.NewGRPCServer(cfg, grpcServer)
+
+	// Config reload
+	watcher := loader.Watch(configFile, cfg, func(prev, next *config.Config) {
+		localOverrides(next)
+		applyConfig(log, prev, next, logLevel, db, cors)
+	}, func(err error) {
+		log.Error("config reload rejected", "error", err)
+	})
+
+	// Admin server
+	var adminSrv *server.Server
+	if cfg.Admin.Enabled {
+		adminSrv = server.NewAdminServer(cfg, admin.New(log, logLevel, watcher))
+	}
 
-	// Start server in goroutine
-	errCh := make(chan error, 1)
+	// Start servers in goroutines
+	errCh := make(chan error, 3)
 	go func() {
 		log.Info("server started", "address", srv.Addr())
 		if err := srv.Run(); !errors.Is(err, http.ErrServerClosed) {
 			errCh <- err
 		}
-		close(errCh)
 	}()
+	go func() {
+		log.Info("grpc server started", "address", grpcSrv.Addr())
+		if err := grpcSrv.Run(); err != nil {
+			errCh <- err
+		}
+	}()
+	if adminSrv != nil {
+		go func() {
+			log.Info("admin server started", "address", adminSrv.Addr())
+			if err := adminSrv.Run(); !errors.Is(err, http.ErrServerClosed) {
+				errCh <- err
+			}
+		}()
+	}
 
 	// Wait for shutdown signal or server error
 	quit := make(chan os.Signal, 1)
@@ -105,6 +275,12 @@ func run() error {
 	}
 
 	// Graceful shutdown
+	grpcSrv.ShutDown(shutdownTimeout)
+	if adminSrv != nil {
+		if err := adminSrv.ShutDown(shutdownTimeout); err != nil {
+			log.Error("admin server shutdown error", "error", err)
+		}
+	}
 	if err := srv.ShutDown(shutdownTimeout); err != nil {
 		log.Error("server shutdown error", "error", err)
 		return err
@@ -113,3 +289,89 @@ func run() error {
 	log.Info("application stopped")
 	return nil
 }
+
+// localOverrides points local runs at the database published on localhost.
+func localOverrides(cfg *config.Config) {
+	if cfg.App.Environment == "local" {
+		cfg.Postgres.Host = "localhost"
+	}
+}
+
+// setLogLevel applies the configured log level, if any, over the environment default.
+func setLogLevel(level *slog.LevelVar, cfg *config.Config) {
+	if cfg.Log.Level == "" {
+		level.Set(logger.DefaultLevel(cfg.App.Environment))
+		return
+	}
+	var configured slog.Level
+	if err := configured.UnmarshalText([]byte(cfg.Log.Level)); err == nil {
+		level.Set(configured)
+	}
+}
+
+// corsOptions converts the CORS config to the middleware policy.
+func corsOptions(cfg config.CORSConfig) middleware.CORSOptions {
+	return middleware.CORSOptions{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   cfg.AllowedHeaders,
+		ExposedHeaders:   cfg.ExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}
+}
+
+// applyConfig applies the reloadable settings of a changed config file and
+// reports the changed settings that need a restart.
+func applyConfig(log *slog.Logger, prev, next *config.Config, level *slog.LevelVar,
+	db *postgresclient.PostgresClient, cors *middleware.CORS,
+) {
+	changed := config.Diff(prev, next)
+	if len(changed) == 0 {
+		return
+	}
+
+	var applied, restart []string
+	for _, key := range changed {
+		if config.Reloadable(key) {
+			applied = append(applied, key)
+		} else {
+			restart = append(restart, key)
+		}
+	}
+
+	if slices.Contains(changed, "log.level") {
+		setLogLevel(level, next)
+	}
+	if err := db.SetPool(next.Postgres.MaxOpenConns, next.Postgres.MaxIdleConns, next.Postgres.ConnMaxLifetime); err != nil {
+		log.Error("failed to resize database pool", "error", err)
+	}
+	cors.SetOptions(corsOptions(next.HTTP.CORS))
+
+	if len(applied) > 0 {
+		log.Info("config reloaded", "applied", applied)
+	}
+	if len(restart) > 0 {
+		log.Warn("config changes require restart", "settings", restart)
+	}
+}
+
+// newBlobStore creates attachment storage for the configured driver.
+func newBlobStore(cfg config.StorageConfig) (blobstore.BlobStore, error) {
+	switch cfg.Driver {
+	case blobstore.DriverLocal:
+		return blobstore.NewLocalStore(cfg.LocalDir)
+	case blobstore.DriverS3:
+		s3Config := blobstore.NewS3Config(
+			cfg.S3.Endpoint,
+			cfg.S3.Region,
+			cfg.S3.Bucket,
+			cfg.S3.AccessKey,
+			cfg.S3.SecretKey,
+			cfg.S3.UsePathStyle,
+		)
+		return blobstore.NewS3Store(s3Config, nil)
+	default:
+		return nil, fmt.Errorf("%w: %q", blobstore.ErrUnknownDriver, cfg.Driver)
+	}
+}
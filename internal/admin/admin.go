@@ -0,0 +1,151 @@
+// Package admin provides operator endpoints served on a separate listener:
+// profiling, runtime metrics, build info, effective config and log level.
+package admin
+
+import (
+	"encoding/json"
+	"expvar"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	handler "github.com/Krokozabra213/test_api/internal/delivery/http"
+)
+
+// Admin serves the operator endpoints.
+type Admin struct {
+	log    *slog.Logger
+	level  *slog.LevelVar
+	config slog.LogValuer
+}
+
+// New creates the admin handler. The config is reported through its LogValue,
+// which leaves out secrets. level controls the application logger.
+func New(log *slog.Logger, level *slog.LevelVar, config slog.LogValuer) http.Handler {
+	a := &Admin{
+		log:    log,
+		level:  level,
+		config: config,
+	}
+
+	router := http.NewServeMux()
+	router.HandleFunc("GET /debug/pprof/", pprof.Index)
+	router.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	router.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	router.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	router.HandleFunc("POST /debug/pprof/symbol", pprof.Symbol)
+	router.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+	router.Handle("GET /debug/vars", expvar.Handler())
+	router.Handle("GET /buildinfo", a.BuildInfo())
+	router.Handle("GET /config", a.Config())
+	router.Handle("GET /loglevel", a.GetLogLevel())
+	router.Handle("PUT /loglevel", a.SetLogLevel())
+	return router
+}
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path"`
+	Version   string            `json:"version"`
+	Settings  map[string]string `json:"settings"`
+}
+
+// BuildInfo reports the module version and VCS stamp of the binary.
+func (a *Admin) BuildInfo() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info := BuildInfo{GoVersion: runtime.Version(), Settings: map[string]string{}}
+		if build, ok := debug.ReadBuildInfo(); ok {
+			info.Path = build.Path
+			info.Version = build.Main.Version
+			for _, setting := range build.Settings {
+				if strings.HasPrefix(setting.Key, "vcs") || setting.Key == "GOOS" || setting.Key == "GOARCH" {
+					info.Settings[setting.Key] = setting.Value
+				}
+			}
+		}
+		a.respond(w, http.StatusOK, info)
+	}
+}
+
+// Config reports the effective config.
+func (a *Admin) Config() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a.respond(w, http.StatusOK, valueOf(a.config.LogValue()))
+	}
+}
+
+// LogLevel is the body of the log level endpoints.
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+// GetLogLevel reports the current log level.
+func (a *Admin) GetLogLevel() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a.respond(w, http.StatusOK, LogLevel{Level: a.level.Level().String()})
+	}
+}
+
+// SetLogLevel changes the log level, e.g. to "debug" or "warn".
+// The level lasts until the next restart.
+func (a *Admin) SetLogLevel() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body LogLevel
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&body); err != nil {
+			a.respondError(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(body.Level)); err != nil {
+			a.respondError(w, http.StatusBadRequest, "invalid level, want debug, info, warn or error")
+			return
+		}
+
+		previous := a.level.Level()
+		a.level.Set(level)
+		a.log.Warn("log level changed",
+			slog.String("from", previous.String()),
+			slog.String("to", level.String()),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+		a.respond(w, http.StatusOK, LogLevel{Level: level.String()})
+	}
+}
+
+// respond sends JSON response with logging on error.
+func (a *Admin) respond(w http.ResponseWriter, statusCode int, data any) {
+	if err := handler.JSONResp(w, statusCode, data); err != nil {
+		a.log.Error("failed to send response", "error", err)
+	}
+}
+
+// respondError sends JSON error response with logging on error.
+func (a *Admin) respondError(w http.ResponseWriter, statusCode int, message string) {
+	if err := handler.JSONError(w, statusCode, message); err != nil {
+		a.log.Error("failed to send error response", "error", err)
+	}
+}
+
+// valueOf converts a slog value into JSON-friendly data, keeping groups as objects.
+func valueOf(v slog.Value) any {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := make(map[string]any, len(v.Group()))
+		for _, attr := range v.Group() {
+			group[attr.Key] = valueOf(attr.Value)
+		}
+		return group
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339)
+	default:
+		return v.Any()
+	}
+}
@@ -0,0 +1,88 @@
+package admin
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct{}
+
+func (testConfig) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("env", "test"),
+		slog.Group("http",
+			slog.String("http_address", "0.0.0.0:8080"),
+			slog.Duration("read_timeout", 10*time.Second),
+		),
+	)
+}
+
+func newTestAdmin() (http.Handler, *slog.LevelVar) {
+	level := new(slog.LevelVar)
+	return New(slog.New(slog.DiscardHandler), level, testConfig{}), level
+}
+
+func serve(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func TestLogLevel(t *testing.T) {
+	h, level := newTestAdmin()
+
+	rec := serve(h, http.MethodGet, "/loglevel", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"INFO"}`, rec.Body.String())
+
+	rec = serve(h, http.MethodPut, "/loglevel", `{"level":"debug"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"DEBUG"}`, rec.Body.String())
+	assert.Equal(t, slog.LevelDebug, level.Level())
+
+	rec = serve(h, http.MethodPut, "/loglevel", `{"level":"verbose"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, slog.LevelDebug, level.Level())
+}
+
+func TestConfig(t *testing.T) {
+	h, _ := newTestAdmin()
+
+	rec := serve(h, http.MethodGet, "/config", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"env":"test","http":{"http_address":"0.0.0.0:8080","read_timeout":"10s"}}`, rec.Body.String())
+}
+
+func TestBuildInfo(t *testing.T) {
+	h, _ := newTestAdmin()
+
+	rec := serve(h, http.MethodGet, "/buildinfo", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var info BuildInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	assert.NotEmpty(t, info.GoVersion)
+}
+
+func TestDebugEndpoints(t *testing.T) {
+	h, _ := newTestAdmin()
+
+	rec := serve(h, http.MethodGet, "/debug/vars", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"memstats"`)
+
+	rec = serve(h, http.MethodGet, "/debug/pprof/", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "goroutine")
+
+	rec = serve(h, http.MethodGet, "/debug/pprof/goroutine?debug=1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "goroutine profile")
+}
@@ -0,0 +1,107 @@
+// Package bots calls HTTP callback bots with the slash commands they handle.
+package bots
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/internal/webhook"
+)
+
+// Request headers sent with every callback. The signature is computed
+// the same way as for webhooks, keyed with the bot callback secret.
+const (
+	HeaderTimestamp = "X-Bot-Timestamp"
+	HeaderSignature = "X-Bot-Signature"
+)
+
+// replyBodyLimit bounds the callback response a bot may send.
+const replyBodyLimit = 64 << 10
+
+// ErrNoCallback is returned for bots without a callback URL.
+var ErrNoCallback = errors.New("bot has no callback url")
+
+// CallbackRequest is the body posted to a bot callback.
+type CallbackRequest struct {
+	BotID   int64          `json:"bot_id"`
+	Command domain.Command `json:"command"`
+}
+
+// CallbackReply is the optional body of a callback response. A non-empty
+// text is posted to the chat on behalf of the bot.
+type CallbackReply struct {
+	Text string `json:"text"`
+}
+
+// HTTPCaller posts commands to bot callback URLs.
+type HTTPCaller struct {
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewHTTPCaller creates a new caller. A nil httpClient falls back to webhook.NewHTTPClient
+// without allowed internal networks; callers bound each call with the context.
+func NewHTTPCaller(httpClient *http.Client) *HTTPCaller {
+	if httpClient == nil {
+		httpClient = webhook.NewHTTPClient(nil)
+	}
+
+	return &HTTPCaller{
+		httpClient: httpClient,
+		now:        time.Now,
+	}
+}
+
+// Call posts the command to the bot and returns the reply text, empty if the bot has nothing to say.
+func (c *HTTPCaller) Call(ctx context.Context, bot domain.Bot, cmd domain.Command) (string, error) {
+	if bot.CallbackURL == nil {
+		return "", ErrNoCallback
+	}
+
+	body, err := json.Marshal(CallbackRequest{BotID: bot.ID, Command: cmd})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, *bot.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	timestamp := c.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, webhook.Sign(bot.CallbackSecret, timestamp, body))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		return "", nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, replyBodyLimit))
+	if err != nil || len(bytes.TrimSpace(data)) == 0 {
+		return "", err
+	}
+
+	var reply CallbackReply
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return "", fmt.Errorf("decode reply: %w", err)
+	}
+	return reply.Text, nil
+}
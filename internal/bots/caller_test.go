@@ -0,0 +1,64 @@
+package bots
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPCaller_Call(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if !webhook.Verify("secret", timestamp, body, r.Header.Get(HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req CallbackRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.Command.Args == "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		json.NewEncoder(w).Encode(CallbackReply{Text: "Weather in " + req.Command.Args + ": sunny"})
+	}))
+	t.Cleanup(receiver.Close)
+
+	bot := domain.Bot{ID: 3, CallbackURL: &receiver.URL, CallbackSecret: "secret"}
+	caller := NewHTTPCaller(webhook.NewHTTPClient([]netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}))
+
+	reply, err := caller.Call(context.Background(), bot, domain.Command{Name: "weather", Args: "Moscow", ChatID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "Weather in Moscow: sunny", reply)
+
+	reply, err = caller.Call(context.Background(), bot, domain.Command{Name: "weather", ChatID: 1})
+	require.NoError(t, err)
+	assert.Empty(t, reply)
+
+	bot.CallbackSecret = "wrong"
+	_, err = caller.Call(context.Background(), bot, domain.Command{Name: "weather", Args: "Moscow"})
+	assert.ErrorContains(t, err, "401")
+
+	// The receiver listens on loopback, which is refused unless allowed
+	_, err = NewHTTPCaller(nil).Call(context.Background(), bot, domain.Command{Name: "weather", Args: "Moscow"})
+	assert.ErrorIs(t, err, webhook.ErrForbiddenAddress)
+}
+
+func TestHTTPCaller_NoCallback(t *testing.T) {
+	_, err := NewHTTPCaller(nil).Call(context.Background(), domain.Bot{ID: 1}, domain.Command{Name: "x"})
+	assert.ErrorIs(t, err, ErrNoCallback)
+}
@@ -0,0 +1,204 @@
+// Package business implements core application logic.
+package business
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/internal/repository/postgres"
+	"github.com/Krokozabra213/test_api/pkg/blobstore"
+)
+
+const (
+	// sniffLen is the number of bytes http.DetectContentType looks at.
+	sniffLen = 512
+	// discardTimeout bounds cleanup of blobs left over by a failed upload or a purge.
+	discardTimeout = 10 * time.Second
+)
+
+// GetAttachment retrieves attachment metadata within the given chat.
+func (b *Business) GetAttachment(ctx context.Context, chatID, attachmentID int64) (*domain.Attachment, error) {
+	const op = "business.GetAttachment"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("chat_id", chatID),
+		slog.Int64("attachment_id", attachmentID),
+	)
+	log.Info("starting GetAttachment process")
+
+	attachment, err := b.messageProvider.GetAttachment(ctx, chatID, attachmentID)
+	if err != nil {
+		log.Error("failed to get attachment", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, ErrInternal
+	}
+	log.Info("getAttachment success")
+
+	return attachment, nil
+}
+
+// OpenAttachment streams attachment content starting at offset.
+// A negative length reads until the end of the file.
+func (b *Business) OpenAttachment(ctx context.Context, attachment *domain.Attachment, offset, length int64) (io.ReadCloser, error) {
+	const op = "business.OpenAttachment"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("attachment_id", attachment.ID),
+		slog.Int64("offset", offset),
+		slog.Int64("length", length),
+	)
+
+	body, err := b.blobStore.Get(ctx, attachment.StorageKey, offset, length)
+	if err != nil {
+		log.Error("failed to open attachment", slog.String("error", err.Error()))
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, ErrTimeout
+		}
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, ErrInternal
+	}
+
+	return body, nil
+}
+
+// storeAttachments validates uploads against configured limits and writes them to blob storage.
+// On failure every blob written so far is removed.
+func (b *Business) storeAttachments(ctx context.Context, chatID int64, uploads []domain.AttachmentUpload) ([]domain.Attachment, error) {
+	if len(uploads) == 0 {
+		return nil, nil
+	}
+	if len(uploads) > b.cfg.Attachments.MaxFiles {
+		return nil, ErrTooManyAttachments
+	}
+	for _, upload := range uploads {
+		if upload.Size > b.cfg.Attachments.MaxFileSize {
+			return nil, ErrAttachmentTooLarge
+		}
+	}
+
+	attachments := make([]domain.Attachment, 0, len(uploads))
+	for _, upload := range uploads {
+		attachment, err := b.storeAttachment(ctx, chatID, upload)
+		if err != nil {
+			b.discardAttachments(ctx, attachments)
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, nil
+}
+
+// storeAttachment detects the content type, then streams the upload into blob storage
+// computing its SHA-256 on the way.
+func (b *Business) storeAttachment(ctx context.Context, chatID int64, upload domain.AttachmentUpload) (domain.Attachment, error) {
+	reader := bufio.NewReaderSize(upload.Body, sniffLen)
+	head, err := reader.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return domain.Attachment{}, fmt.Errorf("%w: read upload: %w", ErrInternal, err)
+	}
+
+	contentType := detectContentType(head)
+	if !slices.Contains(b.cfg.Attachments.AllowedTypes, contentType) {
+		return domain.Attachment{}, ErrAttachmentType
+	}
+
+	key, err := newStorageKey(chatID)
+	if err != nil {
+		return domain.Attachment{}, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	hash := sha256.New()
+	counter := &countingReader{r: io.LimitReader(reader, upload.Size)}
+	err = b.blobStore.Put(ctx, key, io.TeeReader(counter, hash), upload.Size, contentType)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return domain.Attachment{}, ErrTimeout
+		}
+		return domain.Attachment{}, fmt.Errorf("%w: put blob: %w", ErrInternal, err)
+	}
+
+	if counter.n != upload.Size {
+		b.discardAttachments(ctx, []domain.Attachment{{StorageKey: key}})
+		return domain.Attachment{}, fmt.Errorf("%w: upload truncated: got %d of %d bytes", ErrInternal, counter.n, upload.Size)
+	}
+
+	return domain.NewAttachment(chatID, upload.Filename, contentType, upload.Size,
+		hex.EncodeToString(hash.Sum(nil)), key), nil
+}
+
+// discardAttachments removes blobs of attachments that were never persisted.
+func (b *Business) discardAttachments(ctx context.Context, attachments []domain.Attachment) {
+	keys := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.StorageKey)
+	}
+	b.deleteBlobs(ctx, keys)
+}
+
+// deleteBlobs removes blobs by key, logging failures. It runs detached from ctx
+// so a cancelled request or shutdown still cleans up after itself.
+func (b *Business) deleteBlobs(ctx context.Context, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), discardTimeout)
+	defer cancel()
+
+	for _, key := range keys {
+		if err := b.blobStore.Delete(cleanupCtx, key); err != nil {
+			b.log.Error("failed to delete attachment blob",
+				slog.String("key", key),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// detectContentType sniffs the media type from content, ignoring whatever the client declared.
+func detectContentType(head []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// newStorageKey generates a unique, unguessable blob key scoped by chat.
+func newStorageKey(chatID int64) (string, error) {
+	name, err := randomHex(16)
+	if err != nil {
+		return "", fmt.Errorf("generate storage key: %w", err)
+	}
+	return fmt.Sprintf("chats/%d/%s", chatID, name), nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
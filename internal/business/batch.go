@@ -0,0 +1,79 @@
+// Package business implements core application logic.
+package business
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/internal/repository/postgres"
+)
+
+// GetChats retrieves the existing chats among the given IDs. Missing and deleted chats are skipped.
+func (b *Business) GetChats(ctx context.Context, chatIDs []int64) ([]domain.Chat, error) {
+	const op = "business.GetChats"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int("chats", len(chatIDs)),
+	)
+	log.Info("starting GetChats process")
+
+	chats, err := b.chatProvider.GetChatsByIDs(ctx, chatIDs)
+	if err != nil {
+		log.Error("failed to get chats", slog.String("error", err.Error()))
+		return nil, batchError(err)
+	}
+	log.Info("getChats success")
+
+	return chats, nil
+}
+
+// ListChatMembers retrieves members of the given chats.
+func (b *Business) ListChatMembers(ctx context.Context, chatIDs []int64) ([]domain.ChatMember, error) {
+	const op = "business.ListChatMembers"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int("chats", len(chatIDs)),
+	)
+	log.Info("starting ListChatMembers process")
+
+	members, err := b.readStateProvider.GetChatMembers(ctx, chatIDs)
+	if err != nil {
+		log.Error("failed to get chat members", slog.String("error", err.Error()))
+		return nil, batchError(err)
+	}
+	log.Info("listChatMembers success")
+
+	return members, nil
+}
+
+// ListMessagesPage retrieves up to limit newest messages of every given chat,
+// older than beforeID when it is set.
+func (b *Business) ListMessagesPage(ctx context.Context, chatIDs []int64, limit int, beforeID int64) ([]domain.Message, error) {
+	const op = "business.ListMessagesPage"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int("chats", len(chatIDs)),
+		slog.Int("limit", limit),
+		slog.Int64("before_id", beforeID),
+	)
+	log.Info("starting ListMessagesPage process")
+
+	messages, err := b.messageProvider.GetMessagesPage(ctx, chatIDs, limit, beforeID)
+	if err != nil {
+		log.Error("failed to get messages", slog.String("error", err.Error()))
+		return nil, batchError(err)
+	}
+	log.Info("listMessagesPage success")
+
+	return messages, nil
+}
+
+// batchError maps repository errors of batch lookups.
+func batchError(err error) error {
+	if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+		return ErrTimeout
+	}
+	return ErrInternal
+}
@@ -0,0 +1,202 @@
+// Package business implements core application logic.
+package business
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/internal/repository/postgres"
+)
+
+// Bot credential sizes in random bytes.
+const (
+	botTokenBytes  = 32
+	botSecretBytes = 24
+)
+
+// CreateBot creates a bot account and returns it with its API token and, for callback
+// bots, the secret signing callbacks. Neither is retrievable later.
+func (b *Business) CreateBot(ctx context.Context, input domain.CreateBotInput) (*domain.CreatedBot, error) {
+	const op = "business.CreateBot"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.String("name", input.Name),
+		slog.Any("commands", input.Commands),
+	)
+	log.Info("starting CreateBot process")
+
+	token, err := randomHex(botTokenBytes)
+	if err != nil {
+		log.Error("failed to generate bot token", slog.String("error", err.Error()))
+		return nil, ErrInternal
+	}
+	var secret string
+	if input.CallbackURL != nil {
+		if secret, err = randomHex(botSecretBytes); err != nil {
+			log.Error("failed to generate callback secret", slog.String("error", err.Error()))
+			return nil, ErrInternal
+		}
+	}
+
+	// Serializable, so that a bot created concurrently cannot take the commands
+	// between the check and the insert; the conflicting one runs again.
+	var bot *domain.Bot
+	opts := postgres.TxOptions{Isolation: sql.LevelSerializable}
+	err = b.unitOfWork.Atomic(ctx, opts, func(ctx context.Context) error {
+		for _, command := range input.Commands {
+			if err := b.ensureCommandFree(ctx, log, command); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		bot, err = b.botProvider.SaveBot(ctx, domain.NewBot(input.Name, hashToken(token), input.CallbackURL, secret, input.Commands))
+		return err
+	})
+	if errors.Is(err, ErrCommandConflict) || errors.Is(err, ErrTimeout) || errors.Is(err, ErrInternal) {
+		return nil, err
+	}
+	if err != nil {
+		log.Error("failed to save bot", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		if errors.Is(err, postgres.ErrDuplicate) {
+			return nil, ErrBotNameTaken
+		}
+		return nil, ErrInternal
+	}
+	log.Info("createBot success", slog.Int64("bot_id", bot.ID))
+
+	return &domain.CreatedBot{Bot: *bot, Token: token, CallbackSecret: secret}, nil
+}
+
+// ListBots retrieves all bot accounts.
+func (b *Business) ListBots(ctx context.Context) ([]domain.Bot, error) {
+	const op = "business.ListBots"
+	log := b.log.With(slog.String("op", op))
+	log.Info("starting ListBots process")
+
+	bots, err := b.botProvider.GetBots(ctx)
+	if err != nil {
+		log.Error("failed to get bots", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		return nil, ErrInternal
+	}
+	log.Info("listBots success")
+
+	return bots, nil
+}
+
+// DeleteBot removes a bot account. Messages it posted remain in chats.
+func (b *Business) DeleteBot(ctx context.Context, botID int64) error {
+	const op = "business.DeleteBot"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("bot_id", botID),
+	)
+	log.Info("starting DeleteBot process")
+
+	err := b.botProvider.DeleteBot(ctx, botID)
+	if err != nil {
+		log.Error("failed to delete bot", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return ErrTimeout
+		}
+		if errors.Is(err, postgres.ErrNotFound) {
+			return ErrBotNotFound
+		}
+		return ErrInternal
+	}
+	log.Info("deleteBot success")
+
+	return nil
+}
+
+// AuthenticateBot resolves a bot by its API token.
+func (b *Business) AuthenticateBot(ctx context.Context, token string) (*domain.Bot, error) {
+	const op = "business.AuthenticateBot"
+	log := b.log.With(slog.String("op", op))
+
+	bot, err := b.botProvider.GetBotByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		if errors.Is(err, postgres.ErrNotFound) {
+			log.Warn("rejected bot token")
+			return nil, ErrInvalidBotToken
+		}
+		log.Error("failed to get bot", slog.String("error", err.Error()))
+		return nil, ErrInternal
+	}
+
+	return bot, nil
+}
+
+// CreateBotMessage posts a message to the chat on behalf of the bot.
+// Commands in bot messages are not dispatched, so bots cannot trigger each other.
+func (b *Business) CreateBotMessage(ctx context.Context, bot *domain.Bot, chatID int64, text string, uploads []domain.AttachmentUpload) (*domain.Message, error) {
+	const op = "business.CreateBotMessage"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("chat_id", chatID),
+		slog.Int64("bot_id", bot.ID),
+		slog.Int("attachments", len(uploads)),
+	)
+	log.Info("starting CreateBotMessage process")
+
+	message, err := b.saveMessage(ctx, log, chatID, &bot.ID, text, uploads)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("createBotMessage success")
+
+	return message, nil
+}
+
+// ensureCommandFree checks that no in-process handler or other bot handles the command.
+func (b *Business) ensureCommandFree(ctx context.Context, log *slog.Logger, command string) error {
+	if _, ok := b.commands[command]; ok {
+		return ErrCommandConflict
+	}
+
+	_, err := b.botProvider.GetBotByCommand(ctx, command)
+	if err == nil {
+		return ErrCommandConflict
+	}
+	if errors.Is(err, postgres.ErrNotFound) {
+		return nil
+	}
+	if errors.Is(err, postgres.ErrTransaction) {
+		// Retried by the unit of work
+		return err
+	}
+
+	log.Error("failed to check command", slog.String("error", err.Error()))
+	if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+		return ErrTimeout
+	}
+	return ErrInternal
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,147 @@
+// Package business implements core application logic.
+package business
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/internal/repository/postgres"
+)
+
+// CommandHandler handles a slash command in-process and returns the reply text.
+// An empty reply posts nothing.
+type CommandHandler func(ctx context.Context, cmd domain.Command) (string, error)
+
+type registeredCommand struct {
+	description string
+	handler     CommandHandler
+}
+
+// RegisterCommand adds an in-process slash command handler replying on behalf of the system bot.
+// It must be called before the business starts serving requests.
+func (b *Business) RegisterCommand(name, description string, handler CommandHandler) error {
+	if err := domain.ValidateCommandName(name); err != nil {
+		return err
+	}
+	if _, ok := b.commands[name]; ok {
+		return fmt.Errorf("%w: /%s", ErrCommandConflict, name)
+	}
+
+	b.commands[name] = registeredCommand{description: description, handler: handler}
+	return nil
+}
+
+// WaitCallbacks blocks until in-flight calls to callback bots finish.
+func (b *Business) WaitCallbacks() {
+	b.callbacks.Wait()
+}
+
+func (b *Business) registerBuiltinCommands() {
+	b.commands["help"] = registeredCommand{description: "list available commands", handler: b.helpCommand}
+	b.commands["stats"] = registeredCommand{description: "show chat statistics", handler: b.statsCommand}
+}
+
+// runCommand dispatches a command to its in-process handler or to the callback bot handling it.
+// The message carrying the command is already saved, so failures are only logged.
+func (b *Business) runCommand(ctx context.Context, log *slog.Logger, cmd domain.Command) {
+	log = log.With(slog.String("command", cmd.Name))
+
+	if command, ok := b.commands[cmd.Name]; ok {
+		reply, err := command.handler(ctx, cmd)
+		if err != nil {
+			log.Error("failed to handle command", slog.String("error", err.Error()))
+			return
+		}
+		b.postSystemReply(ctx, log, cmd.ChatID, reply)
+		return
+	}
+
+	bot, err := b.botProvider.GetBotByCommand(ctx, cmd.Name)
+	if errors.Is(err, postgres.ErrNotFound) {
+		b.postSystemReply(ctx, log, cmd.ChatID, fmt.Sprintf("Unknown command /%s. Send /help for the list of commands.", cmd.Name))
+		return
+	}
+	if err != nil {
+		log.Error("failed to find command bot", slog.String("error", err.Error()))
+		return
+	}
+
+	// The bot is called in the background so a slow bot does not hold up the sender
+	b.callbacks.Add(1)
+	go func() {
+		defer b.callbacks.Done()
+		b.callBot(context.WithoutCancel(ctx), log.With(slog.Int64("bot_id", bot.ID)), *bot, cmd)
+	}()
+}
+
+func (b *Business) callBot(ctx context.Context, log *slog.Logger, bot domain.Bot, cmd domain.Command) {
+	callCtx, cancel := context.WithTimeout(ctx, b.cfg.BotCallbackTimeout)
+	defer cancel()
+
+	reply, err := b.botCaller.Call(callCtx, bot, cmd)
+	if err != nil {
+		log.Warn("failed to call bot", slog.String("error", err.Error()))
+		return
+	}
+	b.postReply(ctx, log, bot.ID, cmd.ChatID, reply)
+}
+
+func (b *Business) postSystemReply(ctx context.Context, log *slog.Logger, chatID int64, text string) {
+	bot, err := b.botProvider.GetBotByName(ctx, domain.SystemBotName)
+	if err != nil {
+		log.Error("failed to get system bot", slog.String("error", err.Error()))
+		return
+	}
+	b.postReply(ctx, log, bot.ID, chatID, text)
+}
+
+func (b *Business) postReply(ctx context.Context, log *slog.Logger, botID, chatID int64, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if err := (domain.CreateMessageInput{Text: text}).Validate(); err != nil {
+		log.Warn("dropping invalid command reply", slog.String("error", err.Error()))
+		return
+	}
+
+	if _, err := b.saveMessage(ctx, log, chatID, &botID, text, nil); err != nil {
+		return
+	}
+	log.Info("posted command reply", slog.Int64("bot_id", botID))
+}
+
+func (b *Business) helpCommand(ctx context.Context, _ domain.Command) (string, error) {
+	lines := make([]string, 0, len(b.commands))
+	for name, command := range b.commands {
+		lines = append(lines, fmt.Sprintf("/%s — %s", name, command.description))
+	}
+
+	bots, err := b.botProvider.GetBots(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, bot := range bots {
+		for _, name := range bot.Commands {
+			lines = append(lines, fmt.Sprintf("/%s — handled by bot %s", name, bot.Name))
+		}
+	}
+	sort.Strings(lines)
+
+	return "Available commands:\n" + strings.Join(lines, "\n"), nil
+}
+
+func (b *Business) statsCommand(ctx context.Context, cmd domain.Command) (string, error) {
+	stats, err := b.botProvider.GetChatStats(ctx, cmd.ChatID)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Messages: %d\nAttachments: %d\nPinned: %d\nMembers: %d",
+		stats.Messages, stats.Attachments, stats.Pins, stats.Members), nil
+}
@@ -3,36 +3,146 @@ package business
 
 import (
 	"context"
+	"io"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/internal/repository/postgres"
 )
 
 // ChatDBProvider defines methods for chat persistence operations.
 type ChatDBProvider interface {
 	SaveChat(ctx context.Context, title string) (*domain.Chat, error)
 	GetChat(ctx context.Context, chatID int64) (*domain.Chat, error)
-	DeleteChat(ctx context.Context, chatID int64) error
+	GetChatsByIDs(ctx context.Context, chatIDs []int64) ([]domain.Chat, error)
+	DeleteChat(ctx context.Context, chatID int64, ifMatch string) (int64, error)
+	GetChatVersion(ctx context.Context, chatID int64, memberID string) (*domain.ChatVersion, error)
+	ListChats(ctx context.Context, memberID string, limit int) ([]domain.ChatSummary, error)
+	RestoreChat(ctx context.Context, chatID int64, deletedAfter time.Time) (*domain.Chat, error)
+	PurgeDeletedChats(ctx context.Context, deletedBefore time.Time, batchSize int) (int64, []string, error)
 }
 
 // MessageDBProvider defines methods for message persistence operations.
 type MessageDBProvider interface {
-	SaveMessage(ctx context.Context, chatID int64, text string) (*domain.Message, error)
-	GetMessages(ctx context.Context, chatID int64, limit int) ([]domain.Message, error)
+	SaveMessage(ctx context.Context, chatID int64, botID *int64, text string, attachments []domain.Attachment) (*domain.Message, error)
+	GetMessages(ctx context.Context, chatID int64, limit int, lastMessageID int64) ([]domain.Message, error)
+	GetMessagesPage(ctx context.Context, chatIDs []int64, limit int, beforeID int64) ([]domain.Message, error)
+	GetAttachment(ctx context.Context, chatID, attachmentID int64) (*domain.Attachment, error)
+}
+
+// ReadStateDBProvider defines methods for members' read position tracking.
+type ReadStateDBProvider interface {
+	MarkRead(ctx context.Context, chatID int64, memberID string, messageID int64) (*domain.ReadState, error)
+	CountUnread(ctx context.Context, chatID int64, memberID string) (int64, error)
+	GetChatMembers(ctx context.Context, chatIDs []int64) ([]domain.ChatMember, error)
+}
+
+// PinDBProvider defines methods for pinned messages persistence operations.
+type PinDBProvider interface {
+	PinMessage(ctx context.Context, chatID, messageID int64, pinnedBy string, maxPins int, ifMatch string) (*domain.PinnedMessage, bool, error)
+	UnpinMessage(ctx context.Context, chatID, messageID int64, ifMatch string) (*domain.PinnedMessage, error)
+	GetPins(ctx context.Context, chatID int64) ([]domain.PinnedMessage, error)
+}
+
+// WebhookDBProvider defines methods for webhook subscriptions persistence operations.
+type WebhookDBProvider interface {
+	SaveWebhook(ctx context.Context, webhook domain.Webhook) (*domain.Webhook, error)
+	GetWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	GetWebhook(ctx context.Context, webhookID int64) (*domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID int64) error
+	EnableWebhook(ctx context.Context, webhookID int64) (*domain.Webhook, error)
+	GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int) ([]domain.WebhookDelivery, error)
+}
+
+// BotDBProvider defines methods for bot accounts persistence operations.
+type BotDBProvider interface {
+	SaveBot(ctx context.Context, bot domain.Bot) (*domain.Bot, error)
+	GetBots(ctx context.Context) ([]domain.Bot, error)
+	GetBotByName(ctx context.Context, name string) (*domain.Bot, error)
+	GetBotByTokenHash(ctx context.Context, tokenHash string) (*domain.Bot, error)
+	GetBotByCommand(ctx context.Context, command string) (*domain.Bot, error)
+	DeleteBot(ctx context.Context, botID int64) error
+	GetChatStats(ctx context.Context, chatID int64) (*domain.ChatStats, error)
+}
+
+// UnitOfWork runs several provider calls atomically. Calls made with the context
+// passed to fn share a transaction, which runs again on conflicts with concurrent ones.
+type UnitOfWork interface {
+	Atomic(ctx context.Context, opts postgres.TxOptions, fn func(ctx context.Context) error) error
+}
+
+// BotCaller defines methods for handing slash commands over to callback bots.
+type BotCaller interface {
+	Call(ctx context.Context, bot domain.Bot, cmd domain.Command) (string, error)
+}
+
+// BlobStore defines methods for attachment content storage.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Config holds tunable business rules.
+type Config struct {
+	Attachments AttachmentLimits
+	MaxPins     int
+	// RestoreRetention is how long a deleted chat can be restored before it is purged.
+	RestoreRetention time.Duration
+	PurgeBatchSize   int
+	// BotCallbackTimeout bounds a call to a callback bot handling a command.
+	BotCallbackTimeout time.Duration
+}
+
+// AttachmentLimits restricts files uploaded with messages.
+type AttachmentLimits struct {
+	MaxFileSize  int64
+	MaxFiles     int
+	AllowedTypes []string
 }
 
 // Business contains the core business logic and dependencies.
 type Business struct {
-	log             *slog.Logger
-	chatProvider    ChatDBProvider
-	messageProvider MessageDBProvider
+	log               *slog.Logger
+	cfg               Config
+	chatProvider      ChatDBProvider
+	messageProvider   MessageDBProvider
+	readStateProvider ReadStateDBProvider
+	pinProvider       PinDBProvider
+	webhookProvider   WebhookDBProvider
+	botProvider       BotDBProvider
+	unitOfWork        UnitOfWork
+	blobStore         BlobStore
+	botCaller         BotCaller
+
+	// commands holds in-process command handlers, registered before serving requests.
+	commands map[string]registeredCommand
+	// callbacks tracks in-flight calls to callback bots.
+	callbacks sync.WaitGroup
 }
 
 // New creates a new Business instance with the provided dependencies.
-func New(slogger *slog.Logger, chatProvider ChatDBProvider, messageProvider MessageDBProvider) *Business {
-	return &Business{
-		log:             slogger,
-		chatProvider:    chatProvider,
-		messageProvider: messageProvider,
+func New(slogger *slog.Logger, chatProvider ChatDBProvider, messageProvider MessageDBProvider,
+	readStateProvider ReadStateDBProvider, pinProvider PinDBProvider, webhookProvider WebhookDBProvider,
+	botProvider BotDBProvider, unitOfWork UnitOfWork, blobStore BlobStore, botCaller BotCaller, cfg Config,
+) *Business {
+	b := &Business{
+		log:               slogger,
+		cfg:               cfg,
+		chatProvider:      chatProvider,
+		messageProvider:   messageProvider,
+		readStateProvider: readStateProvider,
+		pinProvider:       pinProvider,
+		webhookProvider:   webhookProvider,
+		botProvider:       botProvider,
+		unitOfWork:        unitOfWork,
+		blobStore:         blobStore,
+		botCaller:         botCaller,
+		commands:          make(map[string]registeredCommand),
 	}
+	b.registerBuiltinCommands()
+
+	return b
 }
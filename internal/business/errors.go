@@ -7,5 +7,22 @@ var (
 	ErrTimeout  = errors.New("timeout request")
 	ErrInternal = errors.New("internal service error")
 
-	ErrChatNotFound = errors.New("chat not found")
+	ErrChatNotFound       = errors.New("chat not found")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrMessageNotFound    = errors.New("message not found")
+
+	ErrPinNotFound     = errors.New("pin not found")
+	ErrPinLimitReached = errors.New("pin limit reached")
+
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+	ErrAttachmentType     = errors.New("attachment type not allowed")
+	ErrTooManyAttachments = errors.New("too many attachments")
+
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	ErrBotNotFound     = errors.New("bot not found")
+	ErrBotNameTaken    = errors.New("bot name taken")
+	ErrCommandConflict = errors.New("command already handled")
+	ErrInvalidBotToken = errors.New("invalid bot token")
 )
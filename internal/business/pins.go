@@ -0,0 +1,113 @@
+// Package business implements core application logic.
+package business
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/internal/repository/postgres"
+)
+
+// PinMessage pins a chat message on behalf of the member. Pinning an already
+// pinned message is a no-op that returns the existing pin.
+// A non-empty ifMatch must match the current chat ETag for the pin to be added.
+func (b *Business) PinMessage(ctx context.Context, chatID, messageID int64, memberID, ifMatch string) (*domain.PinnedMessage, error) {
+	const op = "business.PinMessage"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("chat_id", chatID),
+		slog.Int64("message_id", messageID),
+		slog.Bool("conditional", ifMatch != ""),
+	)
+	log.Info("starting PinMessage process")
+
+	if err := b.ensureChat(ctx, log, chatID); err != nil {
+		return nil, err
+	}
+
+	pin, created, err := b.pinProvider.PinMessage(ctx, chatID, messageID, memberID, b.cfg.MaxPins, ifMatch)
+	if err != nil {
+		log.Error("failed to pin message", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		if errors.Is(err, postgres.ErrLimitExceeded) {
+			return nil, ErrPinLimitReached
+		}
+		if errors.Is(err, postgres.ErrVersionMismatch) {
+			return nil, ErrPreconditionFailed
+		}
+		return nil, ErrInternal
+	}
+
+	log.Info("pinMessage success", slog.Bool("created", created))
+
+	return pin, nil
+}
+
+// UnpinMessage removes a pin from the chat.
+// A non-empty ifMatch must match the current chat ETag for the pin to be removed.
+func (b *Business) UnpinMessage(ctx context.Context, chatID, messageID int64, ifMatch string) error {
+	const op = "business.UnpinMessage"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("chat_id", chatID),
+		slog.Int64("message_id", messageID),
+		slog.Bool("conditional", ifMatch != ""),
+	)
+	log.Info("starting UnpinMessage process")
+
+	if err := b.ensureChat(ctx, log, chatID); err != nil {
+		return err
+	}
+
+	_, err := b.pinProvider.UnpinMessage(ctx, chatID, messageID, ifMatch)
+	if err != nil {
+		log.Error("failed to unpin message", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return ErrTimeout
+		}
+		if errors.Is(err, postgres.ErrNotFound) {
+			return ErrPinNotFound
+		}
+		if errors.Is(err, postgres.ErrVersionMismatch) {
+			return ErrPreconditionFailed
+		}
+		return ErrInternal
+	}
+
+	log.Info("unpinMessage success")
+
+	return nil
+}
+
+// ListPins retrieves the chat pins, most recently pinned first.
+func (b *Business) ListPins(ctx context.Context, chatID int64) ([]domain.PinnedMessage, error) {
+	const op = "business.ListPins"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("chat_id", chatID),
+	)
+	log.Info("starting ListPins process")
+
+	if err := b.ensureChat(ctx, log, chatID); err != nil {
+		return nil, err
+	}
+
+	pins, err := b.pinProvider.GetPins(ctx, chatID)
+	if err != nil {
+		log.Error("failed to get pins", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		return nil, ErrInternal
+	}
+	log.Info("listPins success")
+
+	return pins, nil
+}
@@ -0,0 +1,112 @@
+// Package business implements core application logic.
+package business
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/internal/repository/postgres"
+)
+
+// Purge settings used when the config leaves them unset, since a zero batch
+// size would never finish a purge and a zero interval panics the ticker.
+const (
+	defaultPurgeBatchSize = 100
+	defaultPurgeInterval  = time.Hour
+)
+
+// RestoreChat brings back a deleted chat with all its messages, provided it was deleted
+// within the restore retention window. Restoring an active chat returns it unchanged.
+func (b *Business) RestoreChat(ctx context.Context, chatID int64) (*domain.Chat, error) {
+	const op = "business.RestoreChat"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("chat_id", chatID),
+	)
+	log.Info("starting RestoreChat process")
+
+	deletedAfter := time.Now().Add(-b.cfg.RestoreRetention)
+	chat, err := b.chatProvider.RestoreChat(ctx, chatID, deletedAfter)
+	if errors.Is(err, postgres.ErrNotFound) {
+		chat, err = b.chatProvider.GetChat(ctx, chatID)
+	}
+	if err != nil {
+		log.Error("failed to restore chat", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrChatNotFound
+		}
+		return nil, ErrInternal
+	}
+	log.Info("restoreChat success")
+
+	return chat, nil
+}
+
+// PurgeDeletedChats permanently removes chats deleted longer than the restore retention ago,
+// batch by batch, together with their attachment blobs. Returns the number of purged chats.
+func (b *Business) PurgeDeletedChats(ctx context.Context) (int64, error) {
+	const op = "business.PurgeDeletedChats"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Duration("retention", b.cfg.RestoreRetention),
+	)
+
+	batchSize := b.cfg.PurgeBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultPurgeBatchSize
+	}
+
+	deletedBefore := time.Now().Add(-b.cfg.RestoreRetention)
+	var total int64
+	for {
+		purged, storageKeys, err := b.chatProvider.PurgeDeletedChats(ctx, deletedBefore, batchSize)
+		if err != nil {
+			log.Error("failed to purge deleted chats",
+				slog.Int64("purged", total),
+				slog.String("error", err.Error()),
+			)
+			if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+				return total, ErrTimeout
+			}
+			return total, ErrInternal
+		}
+
+		b.deleteBlobs(ctx, storageKeys)
+		total += purged
+
+		if purged < int64(batchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Info("purged deleted chats", slog.Int64("purged", total))
+	}
+	return total, nil
+}
+
+// RunChatPurger purges deleted chats past retention at startup and then every interval
+// until ctx is done.
+func (b *Business) RunChatPurger(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultPurgeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	_, _ = b.PurgeDeletedChats(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = b.PurgeDeletedChats(ctx)
+		}
+	}
+}
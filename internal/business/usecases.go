@@ -32,55 +32,119 @@ func (b *Business) CreateChat(ctx context.Context, title string) (*domain.Chat,
 	return chat, nil
 }
 
-// DeleteChat removes a chat by its ID.
-func (b *Business) DeleteChat(ctx context.Context, chatID int64) error {
+// DeleteChat removes a chat by its ID and returns the number of messages deleted with it.
+// A non-empty ifMatch must match the current chat ETag for the deletion to happen.
+func (b *Business) DeleteChat(ctx context.Context, chatID int64, ifMatch string) (int64, error) {
 	const op = "business.DeleteChat"
 	log := b.log.With(
 		slog.String("op", op),
 		slog.Int64("chat_id", chatID),
+		slog.Bool("conditional", ifMatch != ""),
 	)
 	log.Info("starting DeleteChat process")
 
-	err := b.chatProvider.DeleteChat(ctx, chatID)
+	messages, err := b.chatProvider.DeleteChat(ctx, chatID, ifMatch)
 	if err != nil {
 		log.Error("failed to delete chat", slog.String("error", err.Error()))
 		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
-			return ErrTimeout
+			return 0, ErrTimeout
 		}
-		return ErrInternal
+		if errors.Is(err, postgres.ErrNotFound) {
+			return 0, ErrChatNotFound
+		}
+		if errors.Is(err, postgres.ErrVersionMismatch) {
+			return 0, ErrPreconditionFailed
+		}
+		return 0, ErrInternal
 	}
-	log.Info("deleteChat success")
+	log.Info("deleteChat success", slog.Int64("messages", messages))
 
-	return nil
+	return messages, nil
 }
 
-// CreateMessage adds a new message to the specified chat.
-func (b *Business) CreateMessage(ctx context.Context, chatID int64, text string) (*domain.Message, error) {
+// ChatVersion retrieves the current chat version used for entity tags.
+// A non-empty memberID adds the member's read position to the version.
+func (b *Business) ChatVersion(ctx context.Context, chatID int64, memberID string) (*domain.ChatVersion, error) {
+	const op = "business.ChatVersion"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("chat_id", chatID),
+	)
+
+	version, err := b.chatProvider.GetChatVersion(ctx, chatID, memberID)
+	if err != nil {
+		log.Error("failed to get chat version", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrChatNotFound
+		}
+		return nil, ErrInternal
+	}
+
+	return version, nil
+}
+
+// CreateMessage adds a new message with optional file attachments to the specified chat.
+// A message starting with a slash command is dispatched to the command handler.
+func (b *Business) CreateMessage(ctx context.Context, chatID int64, text string, uploads []domain.AttachmentUpload) (*domain.Message, error) {
 	const op = "business.CreateMessage"
 	log := b.log.With(
 		slog.String("op", op),
 		slog.Int64("chat_id", chatID),
+		slog.Int("attachments", len(uploads)),
 	)
 	log.Info("starting CreateMessage process")
 
-	message, err := b.messageProvider.SaveMessage(ctx, chatID, text)
+	message, err := b.saveMessage(ctx, log, chatID, nil, text, uploads)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("createMessage success")
+
+	if cmd, ok := domain.ParseCommand(text); ok {
+		cmd.ChatID = chatID
+		cmd.MessageID = message.ID
+		b.runCommand(ctx, log, cmd)
+	}
+
+	return message, nil
+}
+
+// saveMessage stores the attachments and saves the message with them.
+func (b *Business) saveMessage(ctx context.Context, log *slog.Logger, chatID int64, botID *int64,
+	text string, uploads []domain.AttachmentUpload,
+) (*domain.Message, error) {
+	attachments, err := b.storeAttachments(ctx, chatID, uploads)
+	if err != nil {
+		log.Error("failed to store attachments", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	message, err := b.messageProvider.SaveMessage(ctx, chatID, botID, text, attachments)
 	if err != nil {
 		log.Error("failed to create message", slog.String("error", err.Error()))
+		b.discardAttachments(ctx, attachments)
 		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
 			return nil, ErrTimeout
 		}
-		if errors.Is(err, postgres.ErrValidation) {
+		if errors.Is(err, postgres.ErrValidation) || errors.Is(err, postgres.ErrNotFound) {
 			return nil, ErrChatNotFound
 		}
 		return nil, ErrInternal
 	}
-	log.Info("createMessage success")
 
 	return message, nil
 }
 
 // ReadChatMessages retrieves a chat with its messages up to the given limit.
-func (b *Business) ReadChatMessages(ctx context.Context, chatID int64, limit int) (*domain.ChatMessageOutput, error) {
+// When memberID is set, the output includes the member's unread counter.
+// When version is set, the messages are never older than it: a replica that
+// has not replicated its last message yet is bypassed for the primary.
+func (b *Business) ReadChatMessages(ctx context.Context, chatID int64, memberID string, limit int,
+	version *domain.ChatVersion,
+) (*domain.ChatMessageOutput, error) {
 	const op = "business.ReadChatMessages"
 	log := b.log.With(
 		slog.String("op", op),
@@ -101,7 +165,11 @@ func (b *Business) ReadChatMessages(ctx context.Context, chatID int64, limit int
 		return nil, ErrInternal
 	}
 
-	messages, err := b.messageProvider.GetMessages(ctx, chatID, limit)
+	var lastMessageID int64
+	if version != nil {
+		lastMessageID = version.LastMessageID
+	}
+	messages, err := b.messageProvider.GetMessages(ctx, chatID, limit, lastMessageID)
 	if err != nil {
 		log.Error("failed to get messages", slog.String("error", err.Error()))
 		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
@@ -109,8 +177,142 @@ func (b *Business) ReadChatMessages(ctx context.Context, chatID int64, limit int
 		}
 		return nil, ErrInternal
 	}
+
+	var unreadCount *int64
+	if memberID != "" {
+		count, err := b.readStateProvider.CountUnread(ctx, chatID, memberID)
+		if err != nil {
+			log.Error("failed to count unread messages", slog.String("error", err.Error()))
+			if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+				return nil, ErrTimeout
+			}
+			return nil, ErrInternal
+		}
+		unreadCount = &count
+	}
+
+	pinned, err := b.pinProvider.GetPins(ctx, chatID)
+	if err != nil {
+		log.Error("failed to get pins", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		return nil, ErrInternal
+	}
 	log.Info("read messages success")
 
-	output := domain.NewChatMessageOutput(chat.ID, chat.Title, chat.CreatedAt, messages)
+	output := domain.NewChatMessageOutput(chat.ID, chat.Title, chat.CreatedAt, unreadCount, pinned, messages)
 	return output, nil
 }
+
+// ListChatMessages retrieves up to limit newest messages of the chat,
+// older than beforeID when it is set.
+func (b *Business) ListChatMessages(ctx context.Context, chatID int64, limit int, beforeID int64) ([]domain.Message, error) {
+	const op = "business.ListChatMessages"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("chat_id", chatID),
+		slog.Int("limit", limit),
+		slog.Int64("before_id", beforeID),
+	)
+	log.Info("starting ListChatMessages process")
+
+	_, err := b.chatProvider.GetChat(ctx, chatID)
+	if err != nil {
+		log.Error("failed to get chat", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrChatNotFound
+		}
+		return nil, ErrInternal
+	}
+
+	messages, err := b.messageProvider.GetMessagesPage(ctx, []int64{chatID}, limit, beforeID)
+	if err != nil {
+		log.Error("failed to get messages", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		return nil, ErrInternal
+	}
+	log.Info("listChatMessages success")
+
+	return messages, nil
+}
+
+// ListChats retrieves the newest chats up to the given limit.
+// When memberID is set, every chat includes the member's unread counter.
+func (b *Business) ListChats(ctx context.Context, memberID string, limit int) ([]domain.ChatSummary, error) {
+	const op = "business.ListChats"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.String("member_id", memberID),
+		slog.Int("limit", limit),
+	)
+	log.Info("starting ListChats process")
+
+	chats, err := b.chatProvider.ListChats(ctx, memberID, limit)
+	if err != nil {
+		log.Error("failed to list chats", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		return nil, ErrInternal
+	}
+	log.Info("listChats success")
+
+	return chats, nil
+}
+
+// MarkChatRead advances the member's read position in the chat up to the given message.
+// Zero messageID marks the latest message as read.
+func (b *Business) MarkChatRead(ctx context.Context, chatID int64, memberID string, messageID int64) (*domain.ReadState, error) {
+	const op = "business.MarkChatRead"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("chat_id", chatID),
+		slog.String("member_id", memberID),
+		slog.Int64("message_id", messageID),
+	)
+	log.Info("starting MarkChatRead process")
+
+	if err := b.ensureChat(ctx, log, chatID); err != nil {
+		return nil, err
+	}
+
+	state, err := b.readStateProvider.MarkRead(ctx, chatID, memberID, messageID)
+	if err != nil {
+		log.Error("failed to mark chat read", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		if errors.Is(err, postgres.ErrValidation) {
+			return nil, ErrChatNotFound
+		}
+		return nil, ErrInternal
+	}
+	log.Info("markChatRead success")
+
+	return state, nil
+}
+
+// ensureChat checks that the chat exists and is not deleted.
+func (b *Business) ensureChat(ctx context.Context, log *slog.Logger, chatID int64) error {
+	_, err := b.chatProvider.GetChat(ctx, chatID)
+	if err != nil {
+		log.Error("failed to get chat", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return ErrTimeout
+		}
+		if errors.Is(err, postgres.ErrNotFound) {
+			return ErrChatNotFound
+		}
+		return ErrInternal
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+// Package business implements core application logic.
+package business
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/internal/repository/postgres"
+)
+
+// CreateWebhook subscribes an endpoint to chat events. A chat-scoped webhook requires the chat to exist.
+func (b *Business) CreateWebhook(ctx context.Context, input domain.CreateWebhookInput) (*domain.Webhook, error) {
+	const op = "business.CreateWebhook"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Any("event_types", input.EventTypes),
+	)
+	log.Info("starting CreateWebhook process")
+
+	if input.ChatID != nil {
+		if err := b.ensureChat(ctx, log, *input.ChatID); err != nil {
+			return nil, err
+		}
+	}
+
+	webhook, err := b.webhookProvider.SaveWebhook(ctx, domain.NewWebhook(input.URL, input.Secret, input.EventTypes, input.ChatID))
+	if err != nil {
+		log.Error("failed to save webhook", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		return nil, ErrInternal
+	}
+	log.Info("createWebhook success", slog.Int64("webhook_id", webhook.ID))
+
+	return webhook, nil
+}
+
+// ListWebhooks retrieves all webhooks.
+func (b *Business) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	const op = "business.ListWebhooks"
+	log := b.log.With(slog.String("op", op))
+	log.Info("starting ListWebhooks process")
+
+	webhooks, err := b.webhookProvider.GetWebhooks(ctx)
+	if err != nil {
+		log.Error("failed to get webhooks", slog.String("error", err.Error()))
+		if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+			return nil, ErrTimeout
+		}
+		return nil, ErrInternal
+	}
+	log.Info("listWebhooks success")
+
+	return webhooks, nil
+}
+
+// GetWebhook retrieves a webhook by ID.
+func (b *Business) GetWebhook(ctx context.Context, webhookID int64) (*domain.Webhook, error) {
+	const op = "business.GetWebhook"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("webhook_id", webhookID),
+	)
+	log.Info("starting GetWebhook process")
+
+	webhook, err := b.webhookProvider.GetWebhook(ctx, webhookID)
+	if err != nil {
+		log.Error("failed to get webhook", slog.String("error", err.Error()))
+		return nil, webhookError(err)
+	}
+	log.Info("getWebhook success")
+
+	return webhook, nil
+}
+
+// DeleteWebhook removes a webhook together with its delivery history.
+func (b *Business) DeleteWebhook(ctx context.Context, webhookID int64) error {
+	const op = "business.DeleteWebhook"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("webhook_id", webhookID),
+	)
+	log.Info("starting DeleteWebhook process")
+
+	if err := b.webhookProvider.DeleteWebhook(ctx, webhookID); err != nil {
+		log.Error("failed to delete webhook", slog.String("error", err.Error()))
+		return webhookError(err)
+	}
+	log.Info("deleteWebhook success")
+
+	return nil
+}
+
+// EnableWebhook re-enables a webhook disabled after repeated failures.
+// Deliveries still pending are retried.
+func (b *Business) EnableWebhook(ctx context.Context, webhookID int64) (*domain.Webhook, error) {
+	const op = "business.EnableWebhook"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("webhook_id", webhookID),
+	)
+	log.Info("starting EnableWebhook process")
+
+	webhook, err := b.webhookProvider.EnableWebhook(ctx, webhookID)
+	if err != nil {
+		log.Error("failed to enable webhook", slog.String("error", err.Error()))
+		return nil, webhookError(err)
+	}
+	log.Info("enableWebhook success")
+
+	return webhook, nil
+}
+
+// ListWebhookDeliveries retrieves the latest deliveries of a webhook with their attempts.
+func (b *Business) ListWebhookDeliveries(ctx context.Context, webhookID int64, limit int) ([]domain.WebhookDelivery, error) {
+	const op = "business.ListWebhookDeliveries"
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int64("webhook_id", webhookID),
+	)
+	log.Info("starting ListWebhookDeliveries process")
+
+	if _, err := b.webhookProvider.GetWebhook(ctx, webhookID); err != nil {
+		log.Error("failed to get webhook", slog.String("error", err.Error()))
+		return nil, webhookError(err)
+	}
+
+	deliveries, err := b.webhookProvider.GetWebhookDeliveries(ctx, webhookID, limit)
+	if err != nil {
+		log.Error("failed to get webhook deliveries", slog.String("error", err.Error()))
+		return nil, webhookError(err)
+	}
+	log.Info("listWebhookDeliveries success")
+
+	return deliveries, nil
+}
+
+// webhookError maps repository errors of webhook operations to business errors.
+func webhookError(err error) error {
+	if errors.Is(err, postgres.ErrCtxCancelled) || errors.Is(err, postgres.ErrCtxDeadline) {
+		return ErrTimeout
+	}
+	if errors.Is(err, postgres.ErrNotFound) {
+		return ErrWebhookNotFound
+	}
+	return ErrInternal
+}
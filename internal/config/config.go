@@ -2,10 +2,9 @@ package config
 
 import (
 	"log/slog"
-	"os"
 	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
@@ -16,35 +15,146 @@ const (
 	defaultHTTPWriteTimeout       = 10 * time.Second
 	defaultHTTPReadTimeout        = 10 * time.Second
 	defaultHTTPMaxHeaderMegabytes = 1
+	defaultHTTPLegacyDeprecatedAt = "2026-10-19T00:00:00Z"
+	defaultHTTPLegacySunsetAt     = "2027-04-19T00:00:00Z"
+	defaultHTTPCompressionEnabled = true
+	defaultHTTPCompressionMinSize = 1024
+	defaultHTTPTLSMinVersion      = "1.2"
+	defaultHTTPTLSClientAuth      = "none"
+	defaultHTTPTLSReloadInterval  = 10 * time.Second
+	defaultHTTPCORSMaxAge         = 10 * time.Minute
+	defaultHTTPRouteTimeout       = 10 * time.Second
+	defaultHTTPRouteMaxBodyBytes  = 1 << 20
+	defaultHTTPUploadMaxBodyBytes = 64 << 20
+
+	defaultGRPCHost = "0.0.0.0"
+	defaultGRPCPort = "9090"
+
+	defaultAdminEnabled = true
+	defaultAdminHost    = "127.0.0.1"
+	defaultAdminPort    = "6060"
+
+	defaultGraphQLMaxDepth      = 8
+	defaultGraphQLMaxComplexity = 2000
 
 	defaultSSLMode         = "disable"
 	defaultMaxOpenConns    = 25
 	defaultMaxIdleConns    = 5
 	defaultConnMaxLifetime = 5 * time.Minute
+	defaultApplicationName = "chat_api"
+
+	defaultConnectAttempts     = 10
+	defaultConnectBackoff      = 500 * time.Millisecond
+	defaultConnectMaxBackoff   = 10 * time.Second
+	defaultHealthCheckInterval = 5 * time.Second
+
+	defaultTxAttempts   = 3
+	defaultTxBackoff    = 20 * time.Millisecond
+	defaultTxMaxBackoff = 500 * time.Millisecond
+
+	defaultAttachmentsMaxFileMegabytes = 10
+	defaultAttachmentsMaxFiles         = 5
+
+	defaultChatsMaxPins          = 10
+	defaultChatsRestoreRetention = 30 * 24 * time.Hour
+	defaultChatsPurgeInterval    = time.Hour
+	defaultChatsPurgeBatchSize   = 100
+
+	defaultOutboxPollInterval    = time.Second
+	defaultOutboxBatchSize       = 100
+	defaultOutboxMaxAttempts     = 10
+	defaultOutboxRetryBackoff    = time.Second
+	defaultOutboxMaxRetryBackoff = 5 * time.Minute
+	defaultOutboxRetention       = 7 * 24 * time.Hour
+	defaultOutboxPurgeInterval   = time.Hour
+
+	defaultWebhooksPollInterval    = time.Second
+	defaultWebhooksBatchSize       = 50
+	defaultWebhooksTimeout         = 10 * time.Second
+	defaultWebhooksMaxAttempts     = 8
+	defaultWebhooksRetryBackoff    = 10 * time.Second
+	defaultWebhooksMaxRetryBackoff = time.Hour
+	defaultWebhooksDisableAfter    = 20
+
+	defaultBotsCallbackTimeout = 5 * time.Second
+
+	defaultStorageDriver   = "local"
+	defaultStorageLocalDir = "data/attachments"
+	defaultS3Region        = "us-east-1"
 )
 
+// defaultAttachmentsAllowedTypes lists media types accepted when none are configured.
+var defaultAttachmentsAllowedTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+	"text/plain",
+}
+
 type (
 	Config struct {
-		App      AppConfig
-		HTTP     HTTPConfig
-		Postgres PostgresConfig
+		App         AppConfig
+		Log         LogConfig
+		HTTP        HTTPConfig
+		GRPC        GRPCConfig
+		Admin       AdminConfig
+		GraphQL     GraphQLConfig
+		Postgres    PostgresConfig
+		Chats       ChatsConfig
+		Outbox      OutboxConfig
+		Webhooks    WebhooksConfig
+		Bots        BotsConfig
+		Attachments AttachmentsConfig
+		Storage     StorageConfig
 	}
 
 	AppConfig struct {
-		AppSecretKey string
-		Environment  string
+		AppSecretKey string `mapstructure:"secretKey"`
+		Environment  string `mapstructure:"environment"`
+	}
+
+	LogConfig struct {
+		// Level overrides the environment default: debug, info, warn or error.
+		Level string `mapstructure:"level"`
 	}
 
 	PostgresConfig struct {
-		Host            string
-		Port            string
-		User            string
-		Password        string
-		DBName          string
+		// URL is a postgres:// URL or key=value DSN that replaces the
+		// connection fields below, e.g. DATABASE_URL of a hosting platform.
+		URL             string        `mapstructure:"url"`
+		Host            string        `mapstructure:"host"`
+		Port            string        `mapstructure:"port"`
+		User            string        `mapstructure:"user"`
+		Password        string        `mapstructure:"password"`
+		DBName          string        `mapstructure:"dbName"`
 		SSLMode         string        `mapstructure:"sslMode"`
 		MaxOpenConns    int           `mapstructure:"maxOpenConns"`
 		MaxIdleConns    int           `mapstructure:"maxIdleConns"`
 		ConnMaxLifetime time.Duration `mapstructure:"connMaxLifetime"`
+		// ApplicationName and StatementTimeout apply to every connection;
+		// zero StatementTimeout keeps the server setting.
+		ApplicationName  string        `mapstructure:"applicationName"`
+		StatementTimeout time.Duration `mapstructure:"statementTimeout"`
+		// TargetSessionAttrs picks the primary among the hosts of the URL,
+		// e.g. "read-write".
+		TargetSessionAttrs string `mapstructure:"targetSessionAttrs"`
+		// Replicas are URLs or DSNs of read replicas that serve chat and
+		// message reads, falling back to the primary.
+		Replicas []string `mapstructure:"replicas"`
+		// ConnectAttempts bounds the startup connection attempts, waiting
+		// ConnectBackoff after the first failure, doubled up to ConnectMaxBackoff.
+		ConnectAttempts   int           `mapstructure:"connectAttempts"`
+		ConnectBackoff    time.Duration `mapstructure:"connectBackoff"`
+		ConnectMaxBackoff time.Duration `mapstructure:"connectMaxBackoff"`
+		// HealthCheckInterval is how often connections are pinged; zero disables the monitor.
+		HealthCheckInterval time.Duration `mapstructure:"healthCheckInterval"`
+		// TxAttempts bounds the runs of a transaction failed on a conflict with
+		// another one, waiting TxBackoff after the first, doubled up to TxMaxBackoff.
+		TxAttempts   int           `mapstructure:"txAttempts"`
+		TxBackoff    time.Duration `mapstructure:"txBackoff"`
+		TxMaxBackoff time.Duration `mapstructure:"txMaxBackoff"`
 	}
 
 	HTTPConfig struct {
@@ -53,108 +163,351 @@ type (
 		ReadTimeout        time.Duration `mapstructure:"readTimeout"`
 		WriteTimeout       time.Duration `mapstructure:"writeTimeout"`
 		MaxHeaderMegabytes int           `mapstructure:"maxHeaderBytes"`
+		// LegacyRoutes announces retirement of the unversioned API routes.
+		LegacyRoutes LegacyRoutesConfig `mapstructure:"legacyRoutes"`
+		// Compression encodes responses negotiated via Accept-Encoding.
+		Compression CompressionConfig `mapstructure:"compression"`
+		// TLS serves HTTPS instead of plain HTTP when enabled.
+		TLS TLSConfig `mapstructure:"tls"`
+		// H2C serves HTTP/2 without TLS alongside HTTP/1.1, for internal
+		// traffic behind a TLS-terminating proxy. Ignored with TLS enabled.
+		H2C bool `mapstructure:"h2c"`
+		// CORS lets browser front-ends of other origins call the API.
+		CORS CORSConfig `mapstructure:"cors"`
+		// Routes bounds request handling of the REST routes.
+		Routes RoutesConfig `mapstructure:"routes"`
 	}
-)
 
-func newCfg() Config {
-	cfg := Config{
-		App:      AppConfig{},
-		Postgres: PostgresConfig{},
-		HTTP:     HTTPConfig{},
+	RoutesConfig struct {
+		// Timeout and MaxBodyBytes apply to routes without an override; zero disables them.
+		Timeout      time.Duration `mapstructure:"timeout"`
+		MaxBodyBytes int64         `mapstructure:"maxBodyBytes"`
+		// Overrides replace both limits of a route.
+		Overrides []RouteLimitsConfig `mapstructure:"overrides"`
 	}
-	return cfg
-}
 
-// Init loads config from file and environment variables.
-// Priority: defaults -> config file -> env vars
-func Init(configfile, envfile string) (*Config, error) {
-	populateDefault()
+	RouteLimitsConfig struct {
+		// Pattern is the route pattern without version prefix, e.g. "POST /chats/{id}/messages".
+		Pattern      string        `mapstructure:"pattern"`
+		Timeout      time.Duration `mapstructure:"timeout"`
+		MaxBodyBytes int64         `mapstructure:"maxBodyBytes"`
+	}
 
-	if err := parseConfigFile(configfile); err != nil {
-		return nil, err
+	CORSConfig struct {
+		// AllowedOrigins may contain one "*" wildcard each, e.g. "https://*.example.com".
+		// Empty disables CORS. A bare "*" cannot be combined with AllowCredentials.
+		AllowedOrigins   []string      `mapstructure:"allowedOrigins"`
+		AllowedMethods   []string      `mapstructure:"allowedMethods"`
+		AllowedHeaders   []string      `mapstructure:"allowedHeaders"`
+		ExposedHeaders   []string      `mapstructure:"exposedHeaders"`
+		AllowCredentials bool          `mapstructure:"allowCredentials"`
+		MaxAge           time.Duration `mapstructure:"maxAge"`
 	}
 
-	cfg := newCfg()
+	TLSConfig struct {
+		Enabled  bool   `mapstructure:"enabled"`
+		CertFile string `mapstructure:"certFile"`
+		KeyFile  string `mapstructure:"keyFile"`
+		// MinVersion is "1.2" or "1.3"; empty means "1.2".
+		MinVersion string `mapstructure:"minVersion"`
+		// CipherSuites are Go names of TLS 1.2 suites, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256.
+		// Empty keeps the Go defaults; TLS 1.3 suites are not configurable.
+		CipherSuites []string `mapstructure:"cipherSuites"`
+		// ClientCAFile is a PEM bundle of CAs that sign client certificates.
+		ClientCAFile string `mapstructure:"clientCAFile"`
+		// ClientAuth is "none", "optional" (verify if presented) or "require".
+		ClientAuth string `mapstructure:"clientAuth"`
+		// ReloadInterval is how often the files are checked for changes on handshakes.
+		ReloadInterval time.Duration `mapstructure:"reloadInterval"`
+	}
 
-	if err := unmarshal(&cfg); err != nil {
-		return nil, err
+	CompressionConfig struct {
+		Enabled bool `mapstructure:"enabled"`
+		// MinSize is the body size in bytes below which responses are sent as is.
+		MinSize int `mapstructure:"minSize"`
 	}
 
-	if err := setFromEnv(envfile, &cfg); err != nil {
-		return nil, err
+	LegacyRoutesConfig struct {
+		DeprecatedAt time.Time `mapstructure:"deprecatedAt"`
+		SunsetAt     time.Time `mapstructure:"sunsetAt"`
 	}
-	return &cfg, nil
-}
 
-func populateDefault() {
-	// http config
-	viper.SetDefault("http.host", defaultHTTPHost)
-	viper.SetDefault("http.port", defaultHTTPPort)
-	viper.SetDefault("http.maxHeaderMegabytes", defaultHTTPMaxHeaderMegabytes)
-	viper.SetDefault("http.readTimeout", defaultHTTPReadTimeout)
-	viper.SetDefault("http.writeTimeout", defaultHTTPWriteTimeout)
+	GRPCConfig struct {
+		Host string `mapstructure:"host"`
+		Port string `mapstructure:"port"`
+	}
 
-	// postgres config
-	viper.SetDefault("postgres.sslMode", defaultSSLMode)
-	viper.SetDefault("postgres.maxOpenConns", defaultMaxOpenConns)
-	viper.SetDefault("postgres.maxIdleConns", defaultMaxIdleConns)
-	viper.SetDefault("postgres.connMaxLifetime", defaultConnMaxLifetime)
-}
+	// AdminConfig is the listener of profiling and runtime controls.
+	// It binds to loopback by default and has no authentication.
+	AdminConfig struct {
+		Enabled bool   `mapstructure:"enabled"`
+		Host    string `mapstructure:"host"`
+		Port    string `mapstructure:"port"`
+	}
 
-func parseConfigFile(configPath string) error {
-	viper.SetConfigFile(configPath)
-	if err := viper.ReadInConfig(); err != nil {
-		return err
+	GraphQLConfig struct {
+		MaxDepth      int `mapstructure:"maxDepth"`
+		MaxComplexity int `mapstructure:"maxComplexity"`
 	}
 
-	return nil
-}
+	ChatsConfig struct {
+		MaxPins          int           `mapstructure:"maxPins"`
+		RestoreRetention time.Duration `mapstructure:"restoreRetention"`
+		PurgeInterval    time.Duration `mapstructure:"purgeInterval"`
+		PurgeBatchSize   int           `mapstructure:"purgeBatchSize"`
+	}
 
-// setFromEnv loads sensitive data from environment variables
-func setFromEnv(envpath string, cfg *Config) error {
-	err := godotenv.Load(envpath)
-	if err != nil {
-		return err
+	OutboxConfig struct {
+		PollInterval    time.Duration `mapstructure:"pollInterval"`
+		BatchSize       int           `mapstructure:"batchSize"`
+		MaxAttempts     int           `mapstructure:"maxAttempts"`
+		RetryBackoff    time.Duration `mapstructure:"retryBackoff"`
+		MaxRetryBackoff time.Duration `mapstructure:"maxRetryBackoff"`
+		// Retention is how long published and dead-lettered events are kept.
+		Retention     time.Duration `mapstructure:"retention"`
+		PurgeInterval time.Duration `mapstructure:"purgeInterval"`
 	}
 
-	cfg.App.Environment = os.Getenv("ENV")
-	cfg.App.AppSecretKey = os.Getenv("APP_SECRET")
+	WebhooksConfig struct {
+		PollInterval    time.Duration `mapstructure:"pollInterval"`
+		BatchSize       int           `mapstructure:"batchSize"`
+		Timeout         time.Duration `mapstructure:"timeout"`
+		MaxAttempts     int           `mapstructure:"maxAttempts"`
+		RetryBackoff    time.Duration `mapstructure:"retryBackoff"`
+		MaxRetryBackoff time.Duration `mapstructure:"maxRetryBackoff"`
+		DisableAfter    int           `mapstructure:"disableAfter"`
+		// AllowedNetworks are internal networks (CIDR) that webhook and bot callback
+		// URLs may reach; other private, loopback and link-local addresses are refused.
+		AllowedNetworks []string `mapstructure:"allowedNetworks"`
+	}
 
-	cfg.Postgres.Host = os.Getenv("POSTGRES_HOST")
-	cfg.Postgres.Port = os.Getenv("POSTGRES_PORT")
-	cfg.Postgres.User = os.Getenv("POSTGRES_USER")
-	cfg.Postgres.DBName = os.Getenv("POSTGRES_DB")
-	cfg.Postgres.Password = os.Getenv("POSTGRES_PASSWORD")
+	BotsConfig struct {
+		CallbackTimeout time.Duration `mapstructure:"callbackTimeout"`
+	}
 
-	return nil
-}
+	AttachmentsConfig struct {
+		MaxFileMegabytes int      `mapstructure:"maxFileMegabytes"`
+		MaxFiles         int      `mapstructure:"maxFiles"`
+		AllowedTypes     []string `mapstructure:"allowedTypes"`
+	}
 
-func unmarshal(cfg *Config) error {
-	if err := viper.UnmarshalKey("http", &cfg.HTTP); err != nil {
-		return err
+	StorageConfig struct {
+		Driver   string   `mapstructure:"driver"`
+		LocalDir string   `mapstructure:"localDir"`
+		S3       S3Config `mapstructure:"s3"`
 	}
 
-	if err := viper.UnmarshalKey("postgres", &cfg.Postgres); err != nil {
-		return err
+	S3Config struct {
+		Endpoint     string `mapstructure:"endpoint"`
+		Region       string `mapstructure:"region"`
+		Bucket       string `mapstructure:"bucket"`
+		UsePathStyle bool   `mapstructure:"usePathStyle"`
+		AccessKey    string `mapstructure:"accessKey"`
+		SecretKey    string `mapstructure:"secretKey"`
 	}
+)
 
-	return nil
+func newCfg() Config {
+	cfg := Config{
+		App:         AppConfig{},
+		Log:         LogConfig{},
+		Postgres:    PostgresConfig{},
+		HTTP:        HTTPConfig{},
+		GRPC:        GRPCConfig{},
+		Admin:       AdminConfig{},
+		GraphQL:     GraphQLConfig{},
+		Chats:       ChatsConfig{},
+		Outbox:      OutboxConfig{},
+		Webhooks:    WebhooksConfig{},
+		Bots:        BotsConfig{},
+		Attachments: AttachmentsConfig{},
+		Storage:     StorageConfig{},
+	}
+	return cfg
+}
+
+// Init loads config from file, environment variables and command-line flags
+// such as "--postgres.maxOpenConns=50". The .env file is optional.
+// Priority: defaults -> config file -> env vars -> flags
+func Init(configfile, envfile string, args []string) (*Config, error) {
+	return NewLoader(File(configfile), Env(envfile), Flags(args)).Load()
+}
+
+func populateDefault(v *viper.Viper) {
+	// http config
+	v.SetDefault("http.host", defaultHTTPHost)
+	v.SetDefault("http.port", defaultHTTPPort)
+	v.SetDefault("http.maxHeaderMegabytes", defaultHTTPMaxHeaderMegabytes)
+	v.SetDefault("http.readTimeout", defaultHTTPReadTimeout)
+	v.SetDefault("http.writeTimeout", defaultHTTPWriteTimeout)
+	v.SetDefault("http.legacyRoutes.deprecatedAt", defaultHTTPLegacyDeprecatedAt)
+	v.SetDefault("http.legacyRoutes.sunsetAt", defaultHTTPLegacySunsetAt)
+	v.SetDefault("http.compression.enabled", defaultHTTPCompressionEnabled)
+	v.SetDefault("http.compression.minSize", defaultHTTPCompressionMinSize)
+	v.SetDefault("http.tls.minVersion", defaultHTTPTLSMinVersion)
+	v.SetDefault("http.tls.clientAuth", defaultHTTPTLSClientAuth)
+	v.SetDefault("http.tls.reloadInterval", defaultHTTPTLSReloadInterval)
+	v.SetDefault("http.cors.allowedMethods", []string{"GET", "POST", "PUT", "DELETE"})
+	v.SetDefault("http.cors.allowedHeaders", []string{"Content-Type", "Authorization", "X-Member-ID", "If-Match", "If-None-Match"})
+	v.SetDefault("http.cors.exposedHeaders", []string{"ETag", "Last-Modified", "X-Deleted-Messages", "Deprecation", "Sunset", "Link"})
+	v.SetDefault("http.cors.maxAge", defaultHTTPCORSMaxAge)
+	v.SetDefault("http.routes.timeout", defaultHTTPRouteTimeout)
+	v.SetDefault("http.routes.maxBodyBytes", defaultHTTPRouteMaxBodyBytes)
+	v.SetDefault("http.routes.overrides", []map[string]any{
+		// Uploads of attachments
+		{"pattern": "POST /chats/{id}/messages", "timeout": "60s", "maxBodyBytes": defaultHTTPUploadMaxBodyBytes},
+		// Attachment downloads stream for as long as the client reads
+		{"pattern": "GET /chats/{id}/attachments/{attachmentID}", "timeout": "0s", "maxBodyBytes": 0},
+	})
+
+	// grpc config
+	v.SetDefault("grpc.host", defaultGRPCHost)
+	v.SetDefault("grpc.port", defaultGRPCPort)
+
+	// admin config
+	v.SetDefault("admin.enabled", defaultAdminEnabled)
+	v.SetDefault("admin.host", defaultAdminHost)
+	v.SetDefault("admin.port", defaultAdminPort)
+
+	// graphql config
+	v.SetDefault("graphql.maxDepth", defaultGraphQLMaxDepth)
+	v.SetDefault("graphql.maxComplexity", defaultGraphQLMaxComplexity)
+
+	// postgres config
+	v.SetDefault("postgres.sslMode", defaultSSLMode)
+	v.SetDefault("postgres.maxOpenConns", defaultMaxOpenConns)
+	v.SetDefault("postgres.maxIdleConns", defaultMaxIdleConns)
+	v.SetDefault("postgres.connMaxLifetime", defaultConnMaxLifetime)
+	v.SetDefault("postgres.applicationName", defaultApplicationName)
+	v.SetDefault("postgres.connectAttempts", defaultConnectAttempts)
+	v.SetDefault("postgres.connectBackoff", defaultConnectBackoff)
+	v.SetDefault("postgres.connectMaxBackoff", defaultConnectMaxBackoff)
+	v.SetDefault("postgres.healthCheckInterval", defaultHealthCheckInterval)
+	v.SetDefault("postgres.txAttempts", defaultTxAttempts)
+	v.SetDefault("postgres.txBackoff", defaultTxBackoff)
+	v.SetDefault("postgres.txMaxBackoff", defaultTxMaxBackoff)
+
+	// chats config
+	v.SetDefault("chats.maxPins", defaultChatsMaxPins)
+	v.SetDefault("chats.restoreRetention", defaultChatsRestoreRetention)
+	v.SetDefault("chats.purgeInterval", defaultChatsPurgeInterval)
+	v.SetDefault("chats.purgeBatchSize", defaultChatsPurgeBatchSize)
+
+	// outbox config
+	v.SetDefault("outbox.pollInterval", defaultOutboxPollInterval)
+	v.SetDefault("outbox.batchSize", defaultOutboxBatchSize)
+	v.SetDefault("outbox.maxAttempts", defaultOutboxMaxAttempts)
+	v.SetDefault("outbox.retryBackoff", defaultOutboxRetryBackoff)
+	v.SetDefault("outbox.maxRetryBackoff", defaultOutboxMaxRetryBackoff)
+	v.SetDefault("outbox.retention", defaultOutboxRetention)
+	v.SetDefault("outbox.purgeInterval", defaultOutboxPurgeInterval)
+
+	// webhooks config
+	v.SetDefault("webhooks.pollInterval", defaultWebhooksPollInterval)
+	v.SetDefault("webhooks.batchSize", defaultWebhooksBatchSize)
+	v.SetDefault("webhooks.timeout", defaultWebhooksTimeout)
+	v.SetDefault("webhooks.maxAttempts", defaultWebhooksMaxAttempts)
+	v.SetDefault("webhooks.retryBackoff", defaultWebhooksRetryBackoff)
+	v.SetDefault("webhooks.maxRetryBackoff", defaultWebhooksMaxRetryBackoff)
+	v.SetDefault("webhooks.disableAfter", defaultWebhooksDisableAfter)
+
+	// bots config
+	v.SetDefault("bots.callbackTimeout", defaultBotsCallbackTimeout)
+
+	// attachments config
+	v.SetDefault("attachments.maxFileMegabytes", defaultAttachmentsMaxFileMegabytes)
+	v.SetDefault("attachments.maxFiles", defaultAttachmentsMaxFiles)
+	v.SetDefault("attachments.allowedTypes", defaultAttachmentsAllowedTypes)
+
+	// storage config
+	v.SetDefault("storage.driver", defaultStorageDriver)
+	v.SetDefault("storage.localDir", defaultStorageLocalDir)
+	v.SetDefault("storage.s3.region", defaultS3Region)
+}
+
+func unmarshal(v *viper.Viper, cfg *Config) error {
+	// Dates are RFC 3339 strings, on top of the default duration and slice hooks
+	return v.Unmarshal(cfg, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		mapstructure.StringToTimeHookFunc(time.RFC3339),
+	)))
 }
 
 func (c *Config) LogValue() slog.Value {
 	return slog.GroupValue(
 		slog.String("env", c.App.Environment),
+		slog.String("log_level", c.Log.Level),
 		slog.Group("http",
 			slog.String("http_address", c.HTTP.Host+":"+c.HTTP.Port),
 			slog.Duration("read_timeout", c.HTTP.ReadTimeout),
 			slog.Duration("write_timeout", c.HTTP.WriteTimeout),
 			slog.Int("maxHeaderMegabytes", c.HTTP.MaxHeaderMegabytes),
+			slog.Time("legacy_deprecated_at", c.HTTP.LegacyRoutes.DeprecatedAt),
+			slog.Time("legacy_sunset_at", c.HTTP.LegacyRoutes.SunsetAt),
+			slog.Bool("compression_enabled", c.HTTP.Compression.Enabled),
+			slog.Int("compression_min_size", c.HTTP.Compression.MinSize),
+			slog.Bool("tls_enabled", c.HTTP.TLS.Enabled),
+			slog.String("tls_min_version", c.HTTP.TLS.MinVersion),
+			slog.String("tls_client_auth", c.HTTP.TLS.ClientAuth),
+			slog.Bool("h2c", c.HTTP.H2C),
+			slog.Any("cors_allowed_origins", c.HTTP.CORS.AllowedOrigins),
+			slog.Duration("route_timeout", c.HTTP.Routes.Timeout),
+			slog.Int64("route_max_body_bytes", c.HTTP.Routes.MaxBodyBytes),
+		),
+		slog.Group("grpc",
+			slog.String("grpc_address", c.GRPC.Host+":"+c.GRPC.Port),
+		),
+		slog.Group("admin",
+			slog.Bool("enabled", c.Admin.Enabled),
+			slog.String("admin_address", c.Admin.Host+":"+c.Admin.Port),
+		),
+		slog.Group("graphql",
+			slog.Int("max_depth", c.GraphQL.MaxDepth),
+			slog.Int("max_complexity", c.GraphQL.MaxComplexity),
 		),
 		slog.Group("postgres",
 			slog.String("host", c.Postgres.Host),
 			slog.String("port", c.Postgres.Port),
 			slog.String("db", c.Postgres.DBName),
 			slog.Int("max_conns", c.Postgres.MaxOpenConns),
+			slog.Bool("url_set", c.Postgres.URL != ""),
+			slog.String("application_name", c.Postgres.ApplicationName),
+			slog.Duration("statement_timeout", c.Postgres.StatementTimeout),
+			slog.Int("replicas", len(c.Postgres.Replicas)),
+			slog.Int("connect_attempts", c.Postgres.ConnectAttempts),
+			slog.Duration("health_check_interval", c.Postgres.HealthCheckInterval),
+			slog.Int("tx_attempts", c.Postgres.TxAttempts),
+		),
+		slog.Group("chats",
+			slog.Int("max_pins", c.Chats.MaxPins),
+			slog.Duration("restore_retention", c.Chats.RestoreRetention),
+			slog.Duration("purge_interval", c.Chats.PurgeInterval),
+		),
+		slog.Group("outbox",
+			slog.Duration("poll_interval", c.Outbox.PollInterval),
+			slog.Int("batch_size", c.Outbox.BatchSize),
+			slog.Int("max_attempts", c.Outbox.MaxAttempts),
+			slog.Duration("retention", c.Outbox.Retention),
+		),
+		slog.Group("webhooks",
+			slog.Duration("timeout", c.Webhooks.Timeout),
+			slog.Int("max_attempts", c.Webhooks.MaxAttempts),
+			slog.Int("disable_after", c.Webhooks.DisableAfter),
+		),
+		slog.Group("bots",
+			slog.Duration("callback_timeout", c.Bots.CallbackTimeout),
+		),
+		slog.Group("attachments",
+			slog.Int("max_file_megabytes", c.Attachments.MaxFileMegabytes),
+			slog.Int("max_files", c.Attachments.MaxFiles),
+			slog.Any("allowed_types", c.Attachments.AllowedTypes),
+		),
+		slog.Group("storage",
+			slog.String("driver", c.Storage.Driver),
+			slog.String("local_dir", c.Storage.LocalDir),
+			slog.String("s3_endpoint", c.Storage.S3.Endpoint),
+			slog.String("s3_bucket", c.Storage.S3.Bucket),
 		),
 	)
 }
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfigFile = "../../configs/main.yml"
+
+// setRequiredEnv sets the settings that have no value in the config file.
+func setRequiredEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("POSTGRES_HOST", "postgres")
+	t.Setenv("POSTGRES_PORT", "5432")
+	t.Setenv("POSTGRES_USER", "user")
+	t.Setenv("POSTGRES_DB", "chat")
+}
+
+func initConfig(t *testing.T, args ...string) (*Config, error) {
+	t.Helper()
+	return Init(testConfigFile, filepath.Join(t.TempDir(), ".env"), args)
+}
+
+func TestInit_WithoutEnvFile(t *testing.T) {
+	setRequiredEnv(t)
+
+	cfg, err := initConfig(t)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres", cfg.Postgres.Host)
+	assert.Equal(t, 25, cfg.Postgres.MaxOpenConns)
+	assert.Equal(t, 10*time.Second, cfg.HTTP.ReadTimeout)
+	assert.Len(t, cfg.HTTP.Routes.Overrides, 2)
+}
+
+func TestInit_EnvAndFlags(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("CHAT_POSTGRES_HOST", "primary")
+	t.Setenv("CHAT_POSTGRES_MAX_OPEN_CONNS", "50")
+	t.Setenv("CHAT_HTTP_READ_TIMEOUT", "30s")
+	t.Setenv("CHAT_HTTP_CORS_ALLOWED_ORIGINS", "https://a.example.com,https://b.example.com")
+	t.Setenv("CHAT_HTTP_TLS_CLIENT_CA_FILE", "ca.pem")
+
+	cfg, err := initConfig(t, "--http.readTimeout=45s", "--storage.s3.usePathStyle=false")
+	require.NoError(t, err)
+	assert.Equal(t, "primary", cfg.Postgres.Host, "prefixed variables win over legacy ones")
+	assert.Equal(t, 50, cfg.Postgres.MaxOpenConns)
+	assert.Equal(t, 45*time.Second, cfg.HTTP.ReadTimeout, "flags win over variables")
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cfg.HTTP.CORS.AllowedOrigins)
+	assert.Equal(t, "ca.pem", cfg.HTTP.TLS.ClientCAFile)
+	assert.False(t, cfg.Storage.S3.UsePathStyle)
+}
+
+func TestInit_SecretFiles(t *testing.T) {
+	setRequiredEnv(t)
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "postgres_password")
+	require.NoError(t, os.WriteFile(secret, []byte("from-file\n"), 0o600))
+	t.Setenv("POSTGRES_PASSWORD_FILE", secret)
+	t.Setenv("CHAT_STORAGE_S3_SECRET_KEY_FILE", filepath.Join(dir, "missing"))
+
+	_, err := initConfig(t)
+	require.ErrorContains(t, err, "storage.s3.secretKey: CHAT_STORAGE_S3_SECRET_KEY_FILE")
+
+	t.Setenv("CHAT_STORAGE_S3_SECRET_KEY_FILE", "")
+	cfg, err := initConfig(t)
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", cfg.Postgres.Password)
+
+	t.Setenv("POSTGRES_PASSWORD", "from-env")
+	cfg, err = initConfig(t)
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", cfg.Postgres.Password, "variables win over files")
+}
+
+func TestInit_Invalid(t *testing.T) {
+	t.Setenv("POSTGRES_HOST", "")
+	t.Setenv("CHAT_HTTP_WRITE_TIMEOUT", "0s")
+
+	_, err := initConfig(t)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "postgres.host: must be set without postgres.url")
+	assert.Contains(t, err.Error(), "http.writeTimeout: must be positive")
+
+	_, err = initConfig(t, "--unknown=1")
+	assert.Error(t, err)
+}
+
+func TestSettingEnvNames(t *testing.T) {
+	names := func(key string) []string {
+		for _, s := range settings {
+			if s.key == key {
+				return s.envNames()
+			}
+		}
+		t.Fatalf("no setting %q", key)
+		return nil
+	}
+
+	assert.Equal(t, []string{"CHAT_POSTGRES_DB_NAME", "POSTGRES_DB"}, names("postgres.dbName"))
+	assert.Equal(t, []string{"CHAT_HTTP_TLS_CLIENT_CA_FILE"}, names("http.tls.clientCAFile"))
+	assert.Equal(t, []string{"CHAT_HTTP_H2C"}, names("http.h2c"))
+	assert.Equal(t, []string{"CHAT_APP_SECRET_KEY", "APP_SECRET"}, names("app.secretKey"))
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Source supplies settings to a Loader.
+type Source interface {
+	// Load returns the settings by key, such as "postgres.maxOpenConns".
+	// Keys may be dotted or nested maps; values may be strings.
+	Load() (map[string]any, error)
+}
+
+// SourceFunc adapts a function to a Source.
+type SourceFunc func() (map[string]any, error)
+
+// Load calls f.
+func (f SourceFunc) Load() (map[string]any, error) {
+	return f()
+}
+
+// Loader builds configs from defaults overridden by its sources in order,
+// so each source takes precedence over the ones before it. Loaders share no
+// state, and every Load reads the sources anew.
+type Loader struct {
+	sources []Source
+}
+
+// NewLoader creates a loader of the sources, lowest precedence first.
+func NewLoader(sources ...Source) *Loader {
+	return &Loader{sources: sources}
+}
+
+// Load reads the sources and returns the validated config.
+func (l *Loader) Load() (*Config, error) {
+	v := viper.New()
+	populateDefault(v)
+
+	for _, source := range l.sources {
+		settings, err := source.Load()
+		if err != nil {
+			return nil, err
+		}
+		if err := v.MergeConfigMap(nest(settings)); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := newCfg()
+	if err := unmarshal(v, &cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+	return &cfg, nil
+}
+
+// nest expands dotted keys into nested maps, so that a source overrides
+// single settings instead of whole sections.
+func nest(settings map[string]any) map[string]any {
+	nested := make(map[string]any, len(settings))
+	for key, value := range settings {
+		path := strings.Split(key, ".")
+		section := nested
+		for _, part := range path[:len(path)-1] {
+			next, ok := section[part].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				section[part] = next
+			}
+			section = next
+		}
+
+		last := path[len(path)-1]
+		if inner, ok := value.(map[string]any); ok {
+			inner = nest(inner)
+			if existing, ok := section[last].(map[string]any); ok {
+				merge(existing, inner)
+				continue
+			}
+			value = inner
+		}
+		section[last] = value
+	}
+	return nested
+}
+
+// merge copies the settings of src into dst, descending into sections both have.
+func merge(dst, src map[string]any) {
+	for key, value := range src {
+		if inner, ok := value.(map[string]any); ok {
+			if existing, ok := dst[key].(map[string]any); ok {
+				merge(existing, inner)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// required are the settings that have no default.
+var required = Map{
+	"postgres.host":   "postgres",
+	"postgres.port":   "5432",
+	"postgres.user":   "user",
+	"postgres.dbName": "chat",
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoader_Precedence(t *testing.T) {
+	file := writeFile(t, "main.yml", `
+http:
+  readTimeout: 20s
+  writeTimeout: 20s
+postgres:
+  maxOpenConns: 30
+  maxIdleConns: 3
+`)
+	t.Setenv("CHAT_HTTP_WRITE_TIMEOUT", "30s")
+	t.Setenv("CHAT_POSTGRES_MAX_OPEN_CONNS", "40")
+
+	cfg, err := NewLoader(required, File(file), Env(""), Flags([]string{"--postgres.maxOpenConns=50"})).Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, defaultHTTPPort, cfg.HTTP.Port, "default")
+	assert.Equal(t, 20*time.Second, cfg.HTTP.ReadTimeout, "file over default")
+	assert.Equal(t, 3, cfg.Postgres.MaxIdleConns, "file over default")
+	assert.Equal(t, 30*time.Second, cfg.HTTP.WriteTimeout, "env over file")
+	assert.Equal(t, 50, cfg.Postgres.MaxOpenConns, "flags over env")
+	assert.Equal(t, defaultConnMaxLifetime, cfg.Postgres.ConnMaxLifetime, "sources override single keys")
+}
+
+func TestLoader_SourceOrder(t *testing.T) {
+	cfg, err := NewLoader(required,
+		Map{"postgres": map[string]any{"maxOpenConns": 10, "maxIdleConns": 2}},
+		Map{"postgres.maxOpenConns": "20"},
+	).Load()
+	require.NoError(t, err)
+	assert.Equal(t, 20, cfg.Postgres.MaxOpenConns)
+	assert.Equal(t, 2, cfg.Postgres.MaxIdleConns)
+}
+
+func TestLoader_Independent(t *testing.T) {
+	first, err := NewLoader(required, Map{"http.port": "8081"}).Load()
+	require.NoError(t, err)
+	second, err := NewLoader(required).Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "8081", first.HTTP.Port)
+	assert.Equal(t, defaultHTTPPort, second.HTTP.Port)
+}
+
+func TestLoader_EnvFile(t *testing.T) {
+	envfile := writeFile(t, ".env", "POSTGRES_HOST=from-file\nCHAT_GRPC_PORT=9191\n")
+	t.Setenv("POSTGRES_HOST", "from-env")
+
+	cfg, err := NewLoader(required, Env(envfile)).Load()
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", cfg.Postgres.Host, "environment over .env")
+	assert.Equal(t, "9191", cfg.GRPC.Port)
+}
+
+func TestNest(t *testing.T) {
+	nested := nest(map[string]any{
+		"http.cors.maxAge": "1m",
+		"http":             map[string]any{"port": "80", "cors.allowCredentials": true},
+	})
+	assert.Equal(t, map[string]any{
+		"http": map[string]any{
+			"port": "80",
+			"cors": map[string]any{"maxAge": "1m", "allowCredentials": true},
+		},
+	}, nested)
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// reloadableKeys are the settings applied at runtime when the config file
+// changes. Keys ending in "." cover a whole section. Changes to any other
+// setting take effect after a restart.
+var reloadableKeys = []string{
+	"log.level",
+	"postgres.maxOpenConns",
+	"postgres.maxIdleConns",
+	"postgres.connMaxLifetime",
+	"http.cors.",
+}
+
+// Reloadable reports whether a change of the key is applied without restart.
+func Reloadable(key string) bool {
+	return slices.ContainsFunc(reloadableKeys, func(reloadable string) bool {
+		if strings.HasSuffix(reloadable, ".") {
+			return strings.HasPrefix(key, reloadable)
+		}
+		return key == reloadable
+	})
+}
+
+// Watcher keeps the config in effect for a watched file.
+type Watcher struct {
+	current atomic.Pointer[Config]
+	pending atomic.Pointer[[]string]
+}
+
+// Watch loads the config anew whenever the file changes. A reloaded config
+// that fails validation is passed to onError and the current one stays in
+// effect; otherwise onReload gets the previous and the new config. Once
+// onReload returns, the reloadable settings of the new config become current
+// and the other changed settings are reported as pending a restart.
+func (l *Loader) Watch(file string, current *Config, onReload func(prev, next *Config), onError func(error)) *Watcher {
+	w := &Watcher{}
+	w.current.Store(current)
+
+	// The watcher instance only tracks the file; its own reads are unused
+	v := viper.New()
+	v.SetConfigFile(file)
+	v.OnConfigChange(func(event fsnotify.Event) {
+		next, err := l.Load()
+		if err != nil {
+			onError(fmt.Errorf("reload %s: %w", event.Name, err))
+			return
+		}
+		prev := w.current.Load()
+		onReload(prev, next)
+		applied, pending := Apply(prev, next)
+		w.current.Store(applied)
+		w.pending.Store(&pending)
+	})
+	v.WatchConfig()
+	return w
+}
+
+// Current returns the config in effect.
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// Pending lists the settings changed in the file that take effect after a restart.
+func (w *Watcher) Pending() []string {
+	if pending := w.pending.Load(); pending != nil {
+		return *pending
+	}
+	return nil
+}
+
+// LogValue reports the config in effect and the settings pending a restart.
+func (w *Watcher) LogValue() slog.Value {
+	attrs := w.Current().LogValue().Group()
+	if pending := w.Pending(); len(pending) > 0 {
+		attrs = append(attrs, slog.Any("pending_restart", pending))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Apply returns prev with the reloadable settings of next and lists the
+// other settings that differ between them, which need a restart.
+func Apply(prev, next *Config) (*Config, []string) {
+	applied := *prev
+	appliedValue, nextValue := reflect.ValueOf(&applied).Elem(), reflect.ValueOf(*next)
+	var pending []string
+	for _, key := range Diff(prev, next) {
+		if !Reloadable(key) {
+			pending = append(pending, key)
+			continue
+		}
+		index := settingIndex(key)
+		appliedValue.FieldByIndex(index).Set(nextValue.FieldByIndex(index))
+	}
+	return &applied, pending
+}
+
+// settingIndex returns the Config field index of a known key.
+func settingIndex(key string) []int {
+	i := slices.IndexFunc(settings, func(s setting) bool { return s.key == key })
+	return settings[i].index
+}
+
+// Diff lists the keys, such as "postgres.maxOpenConns", whose values differ
+// between the configs. Values are not reported, so secrets stay out of logs.
+func Diff(prev, next *Config) []string {
+	var changed []string
+	prevValue, nextValue := reflect.ValueOf(*prev), reflect.ValueOf(*next)
+	for _, s := range settings {
+		if !reflect.DeepEqual(prevValue.FieldByIndex(s.index).Interface(), nextValue.FieldByIndex(s.index).Interface()) {
+			changed = append(changed, s.key)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	prev := newCfg()
+	next := newCfg()
+	assert.Empty(t, Diff(&prev, &next))
+
+	next.Log.Level = "debug"
+	next.Postgres.MaxOpenConns = 50
+	next.Postgres.Password = "secret"
+	next.HTTP.CORS.AllowedOrigins = []string{"https://app.example.com"}
+	next.HTTP.ReadTimeout = time.Minute
+
+	assert.Equal(t, []string{
+		"http.cors.allowedOrigins",
+		"http.readTimeout",
+		"log.level",
+		"postgres.maxOpenConns",
+		"postgres.password",
+	}, Diff(&prev, &next))
+}
+
+func TestApply(t *testing.T) {
+	prev := newCfg()
+	next := newCfg()
+	next.Log.Level = "debug"
+	next.HTTP.CORS.AllowedOrigins = []string{"https://app.example.com"}
+	next.HTTP.ReadTimeout = time.Minute
+	next.Postgres.Password = "secret"
+
+	applied, pending := Apply(&prev, &next)
+	assert.Equal(t, "debug", applied.Log.Level)
+	assert.Equal(t, []string{"https://app.example.com"}, applied.HTTP.CORS.AllowedOrigins)
+	assert.Equal(t, prev.HTTP.ReadTimeout, applied.HTTP.ReadTimeout, "kept until restart")
+	assert.Equal(t, prev.Postgres.Password, applied.Postgres.Password, "kept until restart")
+	assert.Equal(t, []string{"http.readTimeout", "postgres.password"}, pending)
+	assert.Equal(t, []string{"http.cors.allowedOrigins", "log.level"}, Diff(&prev, applied))
+
+	_, pending = Apply(applied, &prev)
+	assert.Empty(t, pending, "reverted file needs no restart")
+}
+
+func TestReloadable(t *testing.T) {
+	assert.True(t, Reloadable("log.level"))
+	assert.True(t, Reloadable("postgres.maxOpenConns"))
+	assert.True(t, Reloadable("http.cors.allowedOrigins"))
+	assert.False(t, Reloadable("http.readTimeout"))
+	assert.False(t, Reloadable("postgres.password"))
+	assert.False(t, Reloadable("http.corsExtra"))
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix starts the environment variable of every setting, e.g.
+// CHAT_POSTGRES_MAX_OPEN_CONNS for postgres.maxOpenConns.
+const EnvPrefix = "CHAT_"
+
+// legacyEnv are the variables read before every setting had a prefixed one.
+// They keep working and lose to the prefixed variables.
+var legacyEnv = map[string]string{
+	"app.environment":      "ENV",
+	"app.secretKey":        "APP_SECRET",
+	"postgres.url":         "DATABASE_URL",
+	"postgres.host":        "POSTGRES_HOST",
+	"postgres.port":        "POSTGRES_PORT",
+	"postgres.user":        "POSTGRES_USER",
+	"postgres.password":    "POSTGRES_PASSWORD",
+	"postgres.dbName":      "POSTGRES_DB",
+	"storage.s3.accessKey": "S3_ACCESS_KEY",
+	"storage.s3.secretKey": "S3_SECRET_KEY",
+}
+
+// setting is a config value addressable by key, such as "postgres.maxOpenConns".
+type setting struct {
+	key   string
+	index []int
+	typ   reflect.Type
+}
+
+var settings = collectSettings(reflect.TypeFor[Config](), "", nil)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func collectSettings(t reflect.Type, prefix string, index []int) []setting {
+	var collected []setting
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" {
+			name = lowerFirst(field.Name)
+			if prefix == "" {
+				// Top-level sections are unmarshalled by lowercase key
+				name = strings.ToLower(field.Name)
+			}
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			collected = append(collected, collectSettings(field.Type, name, fieldIndex)...)
+			continue
+		}
+		collected = append(collected, setting{key: name, index: fieldIndex, typ: field.Type})
+	}
+	return collected
+}
+
+// scalar reports whether the setting can be given as a single string,
+// which lists of objects such as http.routes.overrides cannot.
+func (s setting) scalar() bool {
+	return s.typ.Kind() != reflect.Slice || s.typ.Elem().Kind() != reflect.Struct
+}
+
+// envNames returns the variables of the setting in order of precedence.
+func (s setting) envNames() []string {
+	parts := strings.Split(s.key, ".")
+	for i, part := range parts {
+		parts[i] = snakeUpper(part)
+	}
+	names := []string{EnvPrefix + strings.Join(parts, "_")}
+	if legacy, ok := legacyEnv[s.key]; ok {
+		names = append(names, legacy)
+	}
+	return names
+}
+
+// File reads the config file, e.g. configs/main.yml.
+func File(path string) Source {
+	return SourceFunc(func() (map[string]any, error) {
+		v := viper.New()
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, err
+		}
+		return v.AllSettings(), nil
+	})
+}
+
+// Env reads the variables of the settings from the environment and the
+// optional .env file; variables already set take precedence over the file.
+// A variable with the "_FILE" suffix names a file holding the value, as
+// Docker secrets are mounted; it is read when the variable itself is unset.
+func Env(envfile string) Source {
+	return SourceFunc(func() (map[string]any, error) {
+		dotenv, err := godotenv.Read(envfile)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("load %s: %w", envfile, err)
+		}
+		lookup := func(name string) string {
+			if value := os.Getenv(name); value != "" {
+				return value
+			}
+			return dotenv[name]
+		}
+
+		values := map[string]any{}
+		var errs []error
+		for _, s := range settings {
+			if !s.scalar() {
+				continue
+			}
+			value, ok, err := lookupEnv(s.envNames(), lookup)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.key, err))
+				continue
+			}
+			if ok {
+				values[s.key] = value
+			}
+		}
+		return values, errors.Join(errs...)
+	})
+}
+
+// lookupEnv returns the value of the first variable set, or else the
+// contents of the file named by the first "_FILE" variable set.
+func lookupEnv(names []string, lookup func(string) string) (string, bool, error) {
+	for _, name := range names {
+		if value := lookup(name); value != "" {
+			return value, true, nil
+		}
+	}
+	for _, name := range names {
+		path := lookup(name + "_FILE")
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	}
+	return "", false, nil
+}
+
+// Flags reads the command-line flags named after the settings, such as
+// "--postgres.maxOpenConns=50". "--help" fails with pflag.ErrHelp after
+// printing the flags.
+func Flags(args []string) Source {
+	return SourceFunc(func() (map[string]any, error) {
+		flags := pflag.NewFlagSet("chat_api", pflag.ContinueOnError)
+		for _, s := range settings {
+			if s.scalar() {
+				flags.String(s.key, "", "overrides "+s.key)
+			}
+		}
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		values := map[string]any{}
+		flags.Visit(func(flag *pflag.Flag) {
+			values[flag.Name] = flag.Value.String()
+		})
+		return values, nil
+	})
+}
+
+// Map is an in-memory source, e.g. Map{"postgres.maxOpenConns": 50}.
+type Map map[string]any
+
+// Load returns the map.
+func (m Map) Load() (map[string]any, error) {
+	return m, nil
+}
+
+// snakeUpper converts a camel case key part, such as "clientCAFile",
+// to "CLIENT_CA_FILE".
+func snakeUpper(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Validate checks the config values and reports every invalid field at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	if c.Log.Level != "" {
+		var level slog.Level
+		check(level.UnmarshalText([]byte(c.Log.Level)) == nil,
+			"log.level", "unknown level %q, want debug, info, warn or error", c.Log.Level)
+	}
+
+	checkPort := func(key, port string) {
+		n, err := strconv.Atoi(port)
+		check(err == nil && n >= 0 && n <= 65535, key, "invalid port %q", port)
+	}
+
+	checkPort("http.port", c.HTTP.Port)
+	check(c.HTTP.ReadTimeout > 0, "http.readTimeout", "must be positive")
+	check(c.HTTP.WriteTimeout > 0, "http.writeTimeout", "must be positive")
+	check(c.HTTP.Compression.MinSize >= 0, "http.compression.minSize", "must not be negative")
+	check(c.HTTP.Routes.Timeout >= 0, "http.routes.timeout", "must not be negative")
+	check(c.HTTP.Routes.MaxBodyBytes >= 0, "http.routes.maxBodyBytes", "must not be negative")
+	for i, override := range c.HTTP.Routes.Overrides {
+		key := fmt.Sprintf("http.routes.overrides[%d]", i)
+		check(override.Pattern != "", key+".pattern", "must be set")
+		check(override.Timeout >= 0, key+".timeout", "must not be negative")
+		check(override.MaxBodyBytes >= 0, key+".maxBodyBytes", "must not be negative")
+	}
+	for i, origin := range c.HTTP.CORS.AllowedOrigins {
+		check(strings.Count(origin, "*") <= 1,
+			fmt.Sprintf("http.cors.allowedOrigins[%d]", i), "at most one wildcard allowed in %q", origin)
+	}
+	// Credentials for any origin would let every site act on behalf of the user
+	check(!c.HTTP.CORS.AllowCredentials || !slices.Contains(c.HTTP.CORS.AllowedOrigins, "*"),
+		"http.cors.allowCredentials", "must not be set with \"*\" in allowedOrigins")
+	check(c.HTTP.CORS.MaxAge >= 0, "http.cors.maxAge", "must not be negative")
+	if c.HTTP.TLS.Enabled {
+		check(c.HTTP.TLS.CertFile != "", "http.tls.certFile", "must be set with TLS enabled")
+		check(c.HTTP.TLS.KeyFile != "", "http.tls.keyFile", "must be set with TLS enabled")
+		check(slices.Contains([]string{"", "1.2", "1.3"}, c.HTTP.TLS.MinVersion),
+			"http.tls.minVersion", "unknown version %q, want 1.2 or 1.3", c.HTTP.TLS.MinVersion)
+		check(slices.Contains([]string{"", "none", "optional", "require"}, c.HTTP.TLS.ClientAuth),
+			"http.tls.clientAuth", "unknown mode %q, want none, optional or require", c.HTTP.TLS.ClientAuth)
+	}
+	deprecatedAt, sunsetAt := c.HTTP.LegacyRoutes.DeprecatedAt, c.HTTP.LegacyRoutes.SunsetAt
+	check(deprecatedAt.IsZero() || sunsetAt.IsZero() || sunsetAt.After(deprecatedAt),
+		"http.legacyRoutes.sunsetAt", "must be after deprecatedAt")
+
+	checkPort("grpc.port", c.GRPC.Port)
+	check(c.GraphQL.MaxDepth > 0, "graphql.maxDepth", "must be positive")
+	check(c.GraphQL.MaxComplexity > 0, "graphql.maxComplexity", "must be positive")
+	if c.Admin.Enabled {
+		checkPort("admin.port", c.Admin.Port)
+	}
+
+	if c.Postgres.URL == "" {
+		check(c.Postgres.Host != "", "postgres.host", "must be set without postgres.url")
+		checkPort("postgres.port", c.Postgres.Port)
+		check(c.Postgres.User != "", "postgres.user", "must be set without postgres.url")
+		check(c.Postgres.DBName != "", "postgres.dbName", "must be set without postgres.url")
+	} else {
+		check(validDSN(c.Postgres.URL), "postgres.url", "want postgres:// url or key=value pairs")
+	}
+	for i, replica := range c.Postgres.Replicas {
+		check(validDSN(replica), fmt.Sprintf("postgres.replicas[%d]", i), "want postgres:// url or key=value pairs")
+	}
+	check(c.Postgres.StatementTimeout >= 0, "postgres.statementTimeout", "must not be negative")
+	check(c.Postgres.ConnectAttempts > 0, "postgres.connectAttempts", "must be positive")
+	check(c.Postgres.ConnectBackoff >= 0, "postgres.connectBackoff", "must not be negative")
+	check(c.Postgres.ConnectMaxBackoff >= c.Postgres.ConnectBackoff,
+		"postgres.connectMaxBackoff", "must not be less than connectBackoff")
+	check(c.Postgres.HealthCheckInterval >= 0, "postgres.healthCheckInterval", "must not be negative")
+	check(c.Postgres.TxAttempts > 0, "postgres.txAttempts", "must be positive")
+	check(c.Postgres.TxBackoff > 0, "postgres.txBackoff", "must be positive")
+	check(c.Postgres.TxMaxBackoff >= c.Postgres.TxBackoff,
+		"postgres.txMaxBackoff", "must not be less than txBackoff")
+	check(slices.Contains([]string{"", "any", "read-write", "read-only", "primary", "standby", "prefer-standby"},
+		c.Postgres.TargetSessionAttrs), "postgres.targetSessionAttrs", "unknown value %q", c.Postgres.TargetSessionAttrs)
+
+	check(c.Postgres.MaxOpenConns > 0, "postgres.maxOpenConns", "must be positive")
+	check(c.Postgres.MaxIdleConns >= 0 && c.Postgres.MaxIdleConns <= c.Postgres.MaxOpenConns,
+		"postgres.maxIdleConns", "must be between 0 and maxOpenConns")
+	check(c.Postgres.ConnMaxLifetime >= 0, "postgres.connMaxLifetime", "must not be negative")
+
+	check(c.Chats.MaxPins > 0, "chats.maxPins", "must be positive")
+	check(c.Chats.PurgeInterval > 0, "chats.purgeInterval", "must be positive")
+	check(c.Chats.PurgeBatchSize > 0, "chats.purgeBatchSize", "must be positive")
+	check(c.Outbox.PollInterval > 0, "outbox.pollInterval", "must be positive")
+	check(c.Outbox.BatchSize > 0, "outbox.batchSize", "must be positive")
+	check(c.Outbox.MaxAttempts > 0, "outbox.maxAttempts", "must be positive")
+	check(c.Outbox.Retention > 0, "outbox.retention", "must be positive")
+	check(c.Outbox.PurgeInterval > 0, "outbox.purgeInterval", "must be positive")
+	check(c.Webhooks.PollInterval > 0, "webhooks.pollInterval", "must be positive")
+	check(c.Webhooks.BatchSize > 0, "webhooks.batchSize", "must be positive")
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout", "must be positive")
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.maxAttempts", "must be positive")
+	for _, network := range c.Webhooks.AllowedNetworks {
+		_, err := netip.ParsePrefix(network)
+		check(err == nil, "webhooks.allowedNetworks", "invalid network %q, want CIDR", network)
+	}
+	check(c.Bots.CallbackTimeout > 0, "bots.callbackTimeout", "must be positive")
+	check(c.Attachments.MaxFileMegabytes > 0, "attachments.maxFileMegabytes", "must be positive")
+	check(c.Attachments.MaxFiles > 0, "attachments.maxFiles", "must be positive")
+
+	switch c.Storage.Driver {
+	case "local":
+		check(c.Storage.LocalDir != "", "storage.localDir", "must be set for the local driver")
+	case "s3":
+		check(c.Storage.S3.Bucket != "", "storage.s3.bucket", "must be set for the s3 driver")
+	default:
+		check(false, "storage.driver", "unknown driver %q, want local or s3", c.Storage.Driver)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validDSN reports whether the value looks like a postgres URL or key=value DSN.
+// Values are not reported, as they may hold passwords.
+func validDSN(dsn string) bool {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return true
+	}
+	return !strings.Contains(dsn, "://") && strings.Contains(dsn, "=")
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	setRequiredEnv(t)
+	loaded, err := initConfig(t)
+	require.NoError(t, err)
+	cfg := *loaded
+	require.NoError(t, cfg.Validate())
+
+	cfg.Log.Level = "verbose"
+	cfg.HTTP.ReadTimeout = 0
+	cfg.HTTP.CORS.AllowedOrigins = []string{"https://*.*.example.com", "*"}
+	cfg.HTTP.CORS.AllowCredentials = true
+	cfg.Postgres.MaxIdleConns = 30
+	cfg.HTTP.TLS.Enabled = true
+	cfg.Storage.Driver = "gcs"
+	cfg.Webhooks.AllowedNetworks = []string{"10.0.0.1"}
+	cfg.Webhooks.Timeout = 0
+	cfg.Bots.CallbackTimeout = 0
+	cfg.Chats.MaxPins = 0
+	cfg.GraphQL.MaxDepth = 0
+	cfg.GraphQL.MaxComplexity = 0
+	cfg.HTTP.Routes.Overrides = []RouteLimitsConfig{{Pattern: "POST /chats", Timeout: -1, MaxBodyBytes: -1}}
+
+	err = cfg.Validate()
+	require.Error(t, err)
+	for _, key := range []string{"log.level", "http.readTimeout", "http.cors.allowedOrigins[0]", "http.cors.allowCredentials", "postgres.maxIdleConns",
+		"http.tls.certFile", "storage.driver", "webhooks.allowedNetworks",
+		"webhooks.timeout", "bots.callbackTimeout", "chats.maxPins", "graphql.maxDepth", "graphql.maxComplexity",
+		"http.routes.overrides[0].timeout", "http.routes.overrides[0].maxBodyBytes"} {
+		assert.Contains(t, err.Error(), key+":")
+	}
+}
@@ -0,0 +1,54 @@
+// Package graphqlhandler provides the GraphQL API over chats and messages.
+package graphqlhandler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/Krokozabra213/test_api/internal/business"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Error codes reported in the "code" extension of GraphQL errors.
+const (
+	codeBadUserInput = "BAD_USER_INPUT"
+	codeNotFound     = "NOT_FOUND"
+	codeTimeout      = "TIMEOUT"
+	codeInternal     = "INTERNAL"
+)
+
+// Error messages
+const (
+	ErrRequestTimeout = "request timeout"
+	ErrInternal       = "internal server error"
+	ErrChatNotFound   = "chat not found"
+	ErrInvalidFirst   = "first should be between 1 and 100"
+	ErrInvalidCursor  = "before should be a positive message id"
+)
+
+// presentError maps business errors to GraphQL errors with a code extension.
+func (r *Resolver) presentError(ctx context.Context, err error) *gqlerror.Error {
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) {
+		return graphql.DefaultErrorPresenter(ctx, err)
+	}
+
+	switch {
+	case errors.Is(err, business.ErrChatNotFound):
+		return newError(ctx, codeNotFound, ErrChatNotFound)
+	case errors.Is(err, business.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return newError(ctx, codeTimeout, ErrRequestTimeout)
+	default:
+		r.log.Error("unexpected graphql error", "error", err)
+		return newError(ctx, codeInternal, ErrInternal)
+	}
+}
+
+func newError(ctx context.Context, code, message string) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message:    message,
+		Path:       graphql.GetPath(ctx),
+		Extensions: map[string]any{"code": code},
+	}
+}
@@ -0,0 +1,82 @@
+// Package graphqlhandler provides the GraphQL API over chats and messages.
+package graphqlhandler
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+// Path is where the GraphQL endpoint is served, including WebSocket subscriptions.
+const Path = "/graphql"
+
+// keepAlivePingInterval keeps idle subscription connections open through proxies.
+const keepAlivePingInterval = 15 * time.Second
+
+// Limit constraints
+const (
+	defaultFirst = 20
+	minFirst     = 1
+	maxFirst     = 100
+)
+
+// Limits holds query cost limits.
+type Limits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// New creates the GraphQL server and registers it on the router.
+func New(router *http.ServeMux, log *slog.Logger, business Business, subscriber Subscriber, limits Limits) {
+	resolver := &Resolver{
+		log:        log,
+		business:   business,
+		subscriber: subscriber,
+	}
+
+	schemaConfig := Config{Resolvers: resolver}
+	// List fields cost as much as the items they may return
+	schemaConfig.Complexity.Query.Chats = func(childComplexity int, first *int64) int {
+		return childComplexity * int(firstOrDefault(first))
+	}
+	schemaConfig.Complexity.Chat.Messages = func(childComplexity int, first *int64, _ *int64) int {
+		return childComplexity * int(firstOrDefault(first))
+	}
+
+	srv := handler.New(NewExecutableSchema(schemaConfig))
+	srv.AddTransport(transport.Websocket{KeepAlivePingInterval: keepAlivePingInterval})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	srv.Use(depthLimit{max: limits.MaxDepth})
+	srv.Use(extension.FixedComplexityLimit(limits.MaxComplexity))
+	srv.SetErrorPresenter(resolver.presentError)
+
+	router.Handle(Path, withLoaders(business, clearDeadlines(srv)))
+}
+
+// clearDeadlines lifts the server read and write timeouts from WebSocket
+// connections, which stay open for the lifetime of a subscription.
+func clearDeadlines(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			controller := http.NewResponseController(w)
+			controller.SetReadDeadline(time.Time{})
+			controller.SetWriteDeadline(time.Time{})
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func firstOrDefault(first *int64) int64 {
+	if first == nil {
+		return defaultFirst
+	}
+	return *first
+}
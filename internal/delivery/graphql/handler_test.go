@@ -0,0 +1,210 @@
+package graphqlhandler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBusiness serves chats 1..3 with five messages each and counts batch calls.
+type fakeBusiness struct {
+	chatCalls, memberCalls, messageCalls atomic.Int32
+}
+
+func (f *fakeBusiness) ListChats(_ context.Context, _ string, limit int) ([]domain.ChatSummary, error) {
+	chats := []domain.ChatSummary{{ID: 3, Title: "c"}, {ID: 2, Title: "b"}, {ID: 1, Title: "a"}}
+	return chats[:min(limit, len(chats))], nil
+}
+
+func (f *fakeBusiness) GetChats(_ context.Context, chatIDs []int64) ([]domain.Chat, error) {
+	f.chatCalls.Add(1)
+	var chats []domain.Chat
+	for _, id := range chatIDs {
+		if id >= 1 && id <= 3 {
+			chats = append(chats, domain.Chat{ID: id, Title: string(rune('a' + id - 1))})
+		}
+	}
+	return chats, nil
+}
+
+func (f *fakeBusiness) ListChatMembers(_ context.Context, chatIDs []int64) ([]domain.ChatMember, error) {
+	f.memberCalls.Add(1)
+	var members []domain.ChatMember
+	for _, id := range chatIDs {
+		members = append(members, domain.ChatMember{ChatID: id, MemberID: "alice"})
+	}
+	return members, nil
+}
+
+func (f *fakeBusiness) ListMessagesPage(_ context.Context, chatIDs []int64, limit int, beforeID int64) ([]domain.Message, error) {
+	f.messageCalls.Add(1)
+	var messages []domain.Message
+	for _, chatID := range chatIDs {
+		count := 0
+		for id := chatID*10 + 5; id > chatID*10 && count < limit; id-- {
+			if beforeID > 0 && id >= beforeID {
+				continue
+			}
+			messages = append(messages, domain.Message{ID: id, ChatID: chatID, Text: "hi"})
+			count++
+		}
+	}
+	return messages, nil
+}
+
+func newTestClient(t *testing.T, broker *events.Broker) (*client.Client, *fakeBusiness) {
+	t.Helper()
+
+	biz := &fakeBusiness{}
+	router := http.NewServeMux()
+	New(router, slog.New(slog.DiscardHandler), biz, broker, Limits{MaxDepth: 5, MaxComplexity: 500})
+
+	return client.New(router, client.Path(Path)), biz
+}
+
+func TestGraphQL_BatchesNestedLookups(t *testing.T) {
+	c, biz := newTestClient(t, events.NewBroker(slog.New(slog.DiscardHandler), 1))
+
+	var resp struct {
+		Chats []struct {
+			ID       int64
+			Members  []struct{ MemberID string }
+			Messages struct {
+				Nodes []struct {
+					ID   int64
+					Chat struct{ Title string }
+				}
+				HasMore   bool
+				EndCursor *int64
+			}
+		}
+	}
+	c.MustPost(`{
+		chats {
+			id
+			members { memberId }
+			messages(first: 2) { nodes { id chat { title } } hasMore endCursor }
+		}
+	}`, &resp)
+
+	require.Len(t, resp.Chats, 3)
+	chat := resp.Chats[2]
+	assert.Equal(t, int64(1), chat.ID)
+	assert.Equal(t, "alice", chat.Members[0].MemberID)
+	require.Len(t, chat.Messages.Nodes, 2)
+	assert.Equal(t, int64(15), chat.Messages.Nodes[0].ID)
+	assert.Equal(t, "a", chat.Messages.Nodes[0].Chat.Title)
+	assert.True(t, chat.Messages.HasMore)
+	assert.Equal(t, int64(14), *chat.Messages.EndCursor)
+
+	assert.Equal(t, int32(1), biz.memberCalls.Load())
+	assert.Equal(t, int32(1), biz.messageCalls.Load())
+	assert.Equal(t, int32(1), biz.chatCalls.Load())
+}
+
+func TestGraphQL_Chat(t *testing.T) {
+	c, _ := newTestClient(t, events.NewBroker(slog.New(slog.DiscardHandler), 1))
+
+	var resp struct {
+		Chat *struct {
+			Messages struct {
+				Nodes     []struct{ ID int64 }
+				HasMore   bool
+				EndCursor *int64
+			}
+		}
+	}
+	c.MustPost(`{ chat(id: 2) { messages(first: 3, before: 23) { nodes { id } hasMore endCursor } } }`, &resp)
+	require.NotNil(t, resp.Chat)
+	assert.Len(t, resp.Chat.Messages.Nodes, 2)
+	assert.False(t, resp.Chat.Messages.HasMore)
+
+	var missing struct{ Chat *struct{ ID int64 } }
+	c.MustPost(`{ chat(id: 9) { id } }`, &missing)
+	assert.Nil(t, missing.Chat)
+}
+
+func TestGraphQL_Limits(t *testing.T) {
+	c, _ := newTestClient(t, events.NewBroker(slog.New(slog.DiscardHandler), 1))
+
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{
+			name:  "depth",
+			query: `{ chats { messages { nodes { chat { messages { nodes { id } } } } } } }`,
+			code:  "DEPTH_LIMIT_EXCEEDED",
+		},
+		{
+			name:  "complexity",
+			query: `{ chats(first: 100) { messages(first: 100) { nodes { id } } } }`,
+			code:  "COMPLEXITY_LIMIT_EXCEEDED",
+		},
+		{
+			name:  "invalid first",
+			query: `{ chat(id: 1) { messages(first: 0) { hasMore } } }`,
+			code:  codeBadUserInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := c.RawPost(tt.query)
+			require.NoError(t, err)
+
+			var errs []struct{ Extensions map[string]any }
+			require.NoError(t, json.Unmarshal(resp.Errors, &errs))
+			require.NotEmpty(t, errs)
+			assert.Equal(t, tt.code, errs[0].Extensions["code"])
+		})
+	}
+}
+
+func TestGraphQL_MessageAdded(t *testing.T) {
+	broker := events.NewBroker(slog.New(slog.DiscardHandler), 4)
+	c, _ := newTestClient(t, broker)
+
+	sub := c.Websocket(`subscription { messageAdded(chatId: 1) { id text chat { title } } }`)
+	defer sub.Close()
+
+	payload, err := json.Marshal(domain.Message{ID: 42, ChatID: 1, Text: "hello"})
+	require.NoError(t, err)
+
+	// The subscription is registered asynchronously; publish until the message arrives
+	received := make(chan error, 1)
+	var resp struct {
+		MessageAdded struct {
+			ID   int64
+			Text string
+			Chat struct{ Title string }
+		}
+	}
+	go func() { received <- sub.Next(&resp) }()
+
+	deadline := time.After(5 * time.Second)
+	for done := false; !done; {
+		broker.Publish(context.Background(), domain.Event{Type: domain.EventMessageCreated, ChatID: 1, Payload: payload})
+		select {
+		case err := <-received:
+			require.NoError(t, err)
+			done = true
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no message received")
+		}
+	}
+	assert.Equal(t, int64(42), resp.MessageAdded.ID)
+	assert.Equal(t, "hello", resp.MessageAdded.Text)
+	assert.Equal(t, "a", resp.MessageAdded.Chat.Title)
+}
@@ -0,0 +1,73 @@
+// Package graphqlhandler provides the GraphQL API over chats and messages.
+package graphqlhandler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// depthLimit rejects operations nesting fields deeper than max.
+// Introspection fields are not counted so tooling keeps working.
+type depthLimit struct {
+	max int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = depthLimit{}
+
+// ExtensionName implements graphql.HandlerExtension.
+func (depthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+// Validate implements graphql.HandlerExtension.
+func (d depthLimit) Validate(graphql.ExecutableSchema) error {
+	if d.max < 1 {
+		return fmt.Errorf("max depth should be positive, got %d", d.max)
+	}
+	return nil
+}
+
+// MutateOperationContext implements graphql.OperationContextMutator.
+func (d depthLimit) MutateOperationContext(_ context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	if opCtx.Operation == nil {
+		return nil
+	}
+
+	if depth := selectionDepth(opCtx.Operation.SelectionSet); depth > d.max {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.max)
+		err.Extensions = map[string]any{"code": "DEPTH_LIMIT_EXCEEDED"}
+		return err
+	}
+	return nil
+}
+
+// selectionDepth returns the deepest field nesting in the selection set.
+func selectionDepth(set ast.SelectionSet) int {
+	depth := 0
+	for _, selection := range set {
+		var nested int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name, "__") {
+				continue
+			}
+			nested = 1 + selectionDepth(selection.SelectionSet)
+		case *ast.InlineFragment:
+			nested = selectionDepth(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			if selection.Definition != nil {
+				nested = selectionDepth(selection.Definition.SelectionSet)
+			}
+		}
+		depth = max(depth, nested)
+	}
+	return depth
+}
@@ -0,0 +1,148 @@
+// Package graphqlhandler provides the GraphQL API over chats and messages.
+package graphqlhandler
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+)
+
+// batchWait is how long a loader collects keys before fetching them at once.
+const batchWait = 2 * time.Millisecond
+
+type loadersKey struct{}
+
+// loader collects keys requested by concurrently running resolvers during
+// a short window and fetches them with a single call.
+type loader[K comparable, V any] struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu    sync.Mutex
+	batch *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	done    chan struct{}
+	results map[K]V
+	err     error
+}
+
+func newLoader[K comparable, V any](ctx context.Context, fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{ctx: ctx, fetch: fetch}
+}
+
+// Load returns the value for the key, or the zero value if the fetch did not return it.
+func (l *loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	b := l.batch
+	if b == nil {
+		b = &batch[K, V]{done: make(chan struct{})}
+		l.batch = b
+		time.AfterFunc(batchWait, func() { l.run(b) })
+	}
+	if !slices.Contains(b.keys, key) {
+		b.keys = append(b.keys, key)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return b.results[key], b.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (l *loader[K, V]) run(b *batch[K, V]) {
+	l.mu.Lock()
+	l.batch = nil
+	l.mu.Unlock()
+
+	b.results, b.err = l.fetch(l.ctx, b.keys)
+	close(b.done)
+}
+
+// messagePage identifies a page of chat messages. Pages with the same
+// limit and cursor are fetched together.
+type messagePage struct {
+	chatID   int64
+	limit    int
+	beforeID int64
+}
+
+// loaders holds per-request loaders, so batches never mix requests.
+type loaders struct {
+	chats    *loader[int64, *domain.Chat]
+	members  *loader[int64, []*domain.ChatMember]
+	messages *loader[messagePage, []*domain.Message]
+}
+
+func newLoaders(ctx context.Context, business Business) *loaders {
+	return &loaders{
+		chats: newLoader(ctx, func(ctx context.Context, chatIDs []int64) (map[int64]*domain.Chat, error) {
+			chats, err := business.GetChats(ctx, chatIDs)
+			if err != nil {
+				return nil, err
+			}
+			results := make(map[int64]*domain.Chat, len(chats))
+			for i := range chats {
+				results[chats[i].ID] = &chats[i]
+			}
+			return results, nil
+		}),
+		members: newLoader(ctx, func(ctx context.Context, chatIDs []int64) (map[int64][]*domain.ChatMember, error) {
+			members, err := business.ListChatMembers(ctx, chatIDs)
+			if err != nil {
+				return nil, err
+			}
+			results := make(map[int64][]*domain.ChatMember, len(chatIDs))
+			for i := range members {
+				results[members[i].ChatID] = append(results[members[i].ChatID], &members[i])
+			}
+			return results, nil
+		}),
+		messages: newLoader(ctx, func(ctx context.Context, pages []messagePage) (map[messagePage][]*domain.Message, error) {
+			type cursor struct {
+				limit    int
+				beforeID int64
+			}
+			groups := make(map[cursor][]int64)
+			for _, page := range pages {
+				key := cursor{page.limit, page.beforeID}
+				groups[key] = append(groups[key], page.chatID)
+			}
+
+			results := make(map[messagePage][]*domain.Message, len(pages))
+			for key, chatIDs := range groups {
+				messages, err := business.ListMessagesPage(ctx, chatIDs, key.limit, key.beforeID)
+				if err != nil {
+					return nil, err
+				}
+				for i := range messages {
+					page := messagePage{messages[i].ChatID, key.limit, key.beforeID}
+					results[page] = append(results[page], &messages[i])
+				}
+			}
+			return results, nil
+		}),
+	}
+}
+
+// withLoaders attaches fresh loaders to every request.
+func withLoaders(business Business, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), loadersKey{}, newLoaders(r.Context(), business))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func loadersFor(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
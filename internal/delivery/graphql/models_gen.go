@@ -0,0 +1,19 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graphqlhandler
+
+import (
+	"github.com/Krokozabra213/test_api/internal/domain"
+)
+
+type MessageConnection struct {
+	Nodes     []*domain.Message `json:"nodes"`
+	HasMore   bool              `json:"hasMore"`
+	EndCursor *int64            `json:"endCursor,omitempty"`
+}
+
+type Query struct {
+}
+
+type Subscription struct {
+}
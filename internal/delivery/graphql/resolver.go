@@ -0,0 +1,31 @@
+// Package graphqlhandler provides the GraphQL API over chats and messages.
+package graphqlhandler
+
+//go:generate go tool gqlgen generate
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+)
+
+// Business defines business layer interface.
+type Business interface {
+	ListChats(ctx context.Context, memberID string, limit int) ([]domain.ChatSummary, error)
+	GetChats(ctx context.Context, chatIDs []int64) ([]domain.Chat, error)
+	ListChatMembers(ctx context.Context, chatIDs []int64) ([]domain.ChatMember, error)
+	ListMessagesPage(ctx context.Context, chatIDs []int64, limit int, beforeID int64) ([]domain.Message, error)
+}
+
+// Subscriber delivers live chat events.
+type Subscriber interface {
+	Subscribe(chatID int64) (<-chan domain.Event, func())
+}
+
+// Resolver resolves GraphQL operations on top of the business layer.
+type Resolver struct {
+	log        *slog.Logger
+	business   Business
+	subscriber Subscriber
+}
@@ -0,0 +1,155 @@
+package graphqlhandler
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+)
+
+// Members is the resolver for the members field.
+func (r *chatResolver) Members(ctx context.Context, obj *domain.Chat) ([]*domain.ChatMember, error) {
+	members, err := loadersFor(ctx).members.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	if members == nil {
+		members = []*domain.ChatMember{}
+	}
+	return members, nil
+}
+
+// Messages is the resolver for the messages field.
+func (r *chatResolver) Messages(ctx context.Context, obj *domain.Chat, first *int64, before *int64) (*MessageConnection, error) {
+	limit := firstOrDefault(first)
+	if limit < minFirst || limit > maxFirst {
+		return nil, newError(ctx, codeBadUserInput, ErrInvalidFirst)
+	}
+	var beforeID int64
+	if before != nil {
+		if *before <= 0 {
+			return nil, newError(ctx, codeBadUserInput, ErrInvalidCursor)
+		}
+		beforeID = *before
+	}
+
+	// One extra message tells whether there are more pages
+	messages, err := loadersFor(ctx).messages.Load(ctx, messagePage{
+		chatID:   obj.ID,
+		limit:    int(limit) + 1,
+		beforeID: beforeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	connection := &MessageConnection{Nodes: []*domain.Message{}}
+	if len(messages) > int(limit) {
+		messages = messages[:limit]
+		connection.HasMore = true
+	}
+	if len(messages) > 0 {
+		connection.Nodes = messages
+		connection.EndCursor = &messages[len(messages)-1].ID
+	}
+	return connection, nil
+}
+
+// Chat is the resolver for the chat field.
+func (r *messageResolver) Chat(ctx context.Context, obj *domain.Message) (*domain.Chat, error) {
+	return loadersFor(ctx).chats.Load(ctx, obj.ChatID)
+}
+
+// Chat is the resolver for the chat field.
+func (r *queryResolver) Chat(ctx context.Context, id int64) (*domain.Chat, error) {
+	return loadersFor(ctx).chats.Load(ctx, id)
+}
+
+// Chats is the resolver for the chats field.
+func (r *queryResolver) Chats(ctx context.Context, first *int64) ([]*domain.Chat, error) {
+	limit := firstOrDefault(first)
+	if limit < minFirst || limit > maxFirst {
+		return nil, newError(ctx, codeBadUserInput, ErrInvalidFirst)
+	}
+
+	summaries, err := r.business.ListChats(ctx, "", int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	chats := make([]*domain.Chat, 0, len(summaries))
+	for _, summary := range summaries {
+		chats = append(chats, &domain.Chat{ID: summary.ID, Title: summary.Title, CreatedAt: summary.CreatedAt})
+	}
+	return chats, nil
+}
+
+// MessageAdded is the resolver for the messageAdded field.
+func (r *subscriptionResolver) MessageAdded(ctx context.Context, chatID int64) (<-chan *domain.Message, error) {
+	// Subscribe before checking the chat so no message slips in between
+	events, unsubscribe := r.subscriber.Subscribe(chatID)
+
+	chats, err := r.business.GetChats(ctx, []int64{chatID})
+	if err != nil {
+		unsubscribe()
+		return nil, err
+	}
+	if len(chats) == 0 {
+		unsubscribe()
+		return nil, newError(ctx, codeNotFound, ErrChatNotFound)
+	}
+
+	messages := make(chan *domain.Message)
+	go func() {
+		defer close(messages)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok || event.Type == domain.EventChatDeleted {
+					return
+				}
+				if event.Type != domain.EventMessageCreated {
+					continue
+				}
+
+				var message domain.Message
+				if err := json.Unmarshal(event.Payload, &message); err != nil {
+					r.log.Error("failed to decode message event", "error", err, "event_id", event.ID)
+					continue
+				}
+				select {
+				case messages <- &message:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return messages, nil
+}
+
+// Chat returns ChatResolver implementation.
+func (r *Resolver) Chat() ChatResolver { return &chatResolver{r} }
+
+// Message returns MessageResolver implementation.
+func (r *Resolver) Message() MessageResolver { return &messageResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+type chatResolver struct{ *Resolver }
+type messageResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
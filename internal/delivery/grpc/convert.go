@@ -0,0 +1,73 @@
+// Package grpchandler provides the gRPC API mirroring the HTTP chat endpoints.
+package grpchandler
+
+import (
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	chatv1 "github.com/Krokozabra213/test_api/pkg/api/chat/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toChat(id int64, title string, createdAt time.Time) *chatv1.Chat {
+	return &chatv1.Chat{
+		Id:        id,
+		Title:     title,
+		CreatedAt: timestamppb.New(createdAt),
+	}
+}
+
+func toMessage(message domain.Message) *chatv1.Message {
+	attachments := make([]*chatv1.Attachment, 0, len(message.Attachments))
+	for _, attachment := range message.Attachments {
+		attachments = append(attachments, &chatv1.Attachment{
+			Id:          attachment.ID,
+			MessageId:   attachment.MessageID,
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			Sha256:      attachment.SHA256,
+			CreatedAt:   timestamppb.New(attachment.CreatedAt),
+		})
+	}
+
+	return &chatv1.Message{
+		Id:          message.ID,
+		ChatId:      message.ChatID,
+		BotId:       message.BotID,
+		Text:        message.Text,
+		CreatedAt:   timestamppb.New(message.CreatedAt),
+		Attachments: attachments,
+	}
+}
+
+func toMessages(messages []domain.Message) []*chatv1.Message {
+	out := make([]*chatv1.Message, 0, len(messages))
+	for _, message := range messages {
+		out = append(out, toMessage(message))
+	}
+	return out
+}
+
+func toPinnedMessages(pins []domain.PinnedMessage) []*chatv1.PinnedMessage {
+	out := make([]*chatv1.PinnedMessage, 0, len(pins))
+	for _, pin := range pins {
+		out = append(out, &chatv1.PinnedMessage{
+			MessageId: pin.MessageID,
+			PinnedBy:  pin.PinnedBy,
+			PinnedAt:  timestamppb.New(pin.PinnedAt),
+			Message:   toMessage(pin.Message),
+		})
+	}
+	return out
+}
+
+func toEvent(event domain.Event) *chatv1.Event {
+	return &chatv1.Event{
+		Id:         event.ID,
+		Type:       string(event.Type),
+		ChatId:     event.ChatID,
+		OccurredAt: timestamppb.New(event.OccurredAt),
+		Payload:    event.Payload,
+	}
+}
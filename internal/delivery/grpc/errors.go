@@ -0,0 +1,91 @@
+// Package grpchandler provides the gRPC API mirroring the HTTP chat endpoints.
+package grpchandler
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Krokozabra213/test_api/internal/business"
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// authorizationKey is the metadata key carrying a bot API token.
+	authorizationKey = "authorization"
+	// bearerPrefix starts the authorization value carrying a bot API token.
+	bearerPrefix = "Bearer "
+)
+
+// Error messages
+const (
+	ErrRequestTimeout     = "request timeout"
+	ErrInternal           = "internal server error"
+	ErrNotFound           = "object not found"
+	ErrInvalidChatID      = "invalid chat id"
+	ErrPreconditionFailed = "precondition failed"
+	ErrInvalidBotToken    = "invalid bot token"
+)
+
+// businessError maps business errors to gRPC statuses.
+func (h *Handler) businessError(err error) error {
+	switch {
+	case errors.Is(err, business.ErrChatNotFound),
+		errors.Is(err, business.ErrMessageNotFound),
+		errors.Is(err, business.ErrBotNotFound):
+		return status.Error(codes.NotFound, ErrNotFound)
+	case errors.Is(err, business.ErrPreconditionFailed):
+		return status.Error(codes.FailedPrecondition, ErrPreconditionFailed)
+	case errors.Is(err, business.ErrInvalidBotToken):
+		return status.Error(codes.Unauthenticated, ErrInvalidBotToken)
+	case errors.Is(err, business.ErrTimeout):
+		return status.Error(codes.DeadlineExceeded, ErrRequestTimeout)
+	default:
+		h.log.Error("unexpected business error", "error", err)
+		return status.Error(codes.Internal, ErrInternal)
+	}
+}
+
+// authenticateBot resolves the bot from a bearer token in the authorization metadata.
+// Returns nil bot if the metadata is absent.
+func (h *Handler) authenticateBot(ctx context.Context) (*domain.Bot, error) {
+	values := metadata.ValueFromIncomingContext(ctx, authorizationKey)
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	token, ok := strings.CutPrefix(values[0], bearerPrefix)
+	token = strings.TrimSpace(token)
+	if !ok || token == "" {
+		return nil, status.Error(codes.Unauthenticated, ErrInvalidBotToken)
+	}
+
+	bot, err := h.business.AuthenticateBot(ctx, token)
+	if err != nil {
+		return nil, h.businessError(err)
+	}
+
+	return bot, nil
+}
+
+// validateChatID checks that the chat ID is positive.
+func validateChatID(chatID int64) error {
+	if chatID <= 0 {
+		return status.Error(codes.InvalidArgument, ErrInvalidChatID)
+	}
+	return nil
+}
+
+// validateMemberID checks the optional member ID.
+func validateMemberID(memberID string) error {
+	if memberID == "" {
+		return nil
+	}
+	if err := domain.ValidateMemberID(memberID); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+// Package grpchandler provides the gRPC API mirroring the HTTP chat endpoints.
+package grpchandler
+
+import (
+	"context"
+	"log/slog"
+
+	handler "github.com/Krokozabra213/test_api/internal/delivery/http"
+	"github.com/Krokozabra213/test_api/internal/domain"
+	chatv1 "github.com/Krokozabra213/test_api/pkg/api/chat/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// Limit constraints
+const (
+	defaultLimit = 20
+	minLimit     = 1
+	maxLimit     = 100
+)
+
+// Subscriber delivers live chat events.
+type Subscriber interface {
+	Subscribe(chatID int64) (<-chan domain.Event, func())
+}
+
+// Handler implements chatv1.ChatServiceServer on top of the business layer.
+type Handler struct {
+	chatv1.UnimplementedChatServiceServer
+
+	log        *slog.Logger
+	business   handler.Business
+	subscriber Subscriber
+}
+
+// New creates a new Handler and registers it with reflection on the server.
+func New(server *grpc.Server, log *slog.Logger, business handler.Business, subscriber Subscriber) {
+	h := &Handler{
+		log:        log,
+		business:   business,
+		subscriber: subscriber,
+	}
+	chatv1.RegisterChatServiceServer(server, h)
+	reflection.Register(server)
+}
+
+// CreateChat handles chat creation.
+func (h *Handler) CreateChat(ctx context.Context, req *chatv1.CreateChatRequest) (*chatv1.Chat, error) {
+	input := domain.CreateChatInput{Title: req.GetTitle()}
+	if err := input.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	input.Sanitize()
+
+	chat, err := h.business.CreateChat(ctx, input.Title)
+	if err != nil {
+		return nil, h.businessError(err)
+	}
+
+	return toChat(chat.ID, chat.Title, chat.CreatedAt), nil
+}
+
+// GetChat handles getting chat with pins and newest messages.
+func (h *Handler) GetChat(ctx context.Context, req *chatv1.GetChatRequest) (*chatv1.GetChatResponse, error) {
+	if err := validateChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+	if err := validateMemberID(req.GetMemberId()); err != nil {
+		return nil, err
+	}
+
+	version, err := h.business.ChatVersion(ctx, req.GetChatId(), req.GetMemberId())
+	if err != nil {
+		return nil, h.businessError(err)
+	}
+
+	output, err := h.business.ReadChatMessages(ctx, req.GetChatId(), req.GetMemberId(), parseLimit(req.GetLimit()), version)
+	if err != nil {
+		return nil, h.businessError(err)
+	}
+
+	return &chatv1.GetChatResponse{
+		Chat:        toChat(output.ID, output.Title, output.CreatedAt),
+		UnreadCount: output.UnreadCount,
+		Pinned:      toPinnedMessages(output.Pinned),
+		Messages:    toMessages(output.Messages),
+		Etag:        version.ETag(),
+	}, nil
+}
+
+// ListMessages handles getting the newest chat messages.
+func (h *Handler) ListMessages(ctx context.Context, req *chatv1.ListMessagesRequest) (*chatv1.ListMessagesResponse, error) {
+	if err := validateChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+	if err := validateMemberID(req.GetMemberId()); err != nil {
+		return nil, err
+	}
+
+	output, err := h.business.ReadChatMessages(ctx, req.GetChatId(), req.GetMemberId(), parseLimit(req.GetLimit()), nil)
+	if err != nil {
+		return nil, h.businessError(err)
+	}
+
+	return &chatv1.ListMessagesResponse{Messages: toMessages(output.Messages)}, nil
+}
+
+// SendMessage handles message creation. A bot API token in the authorization
+// metadata posts the message on behalf of the bot.
+func (h *Handler) SendMessage(ctx context.Context, req *chatv1.SendMessageRequest) (*chatv1.Message, error) {
+	if err := validateChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	bot, err := h.authenticateBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	input := domain.CreateMessageInput{Text: req.GetText()}
+	if err := input.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	input.Sanitize()
+
+	var message *domain.Message
+	if bot != nil {
+		message, err = h.business.CreateBotMessage(ctx, bot, req.GetChatId(), input.Text, nil)
+	} else {
+		message, err = h.business.CreateMessage(ctx, req.GetChatId(), input.Text, nil)
+	}
+	if err != nil {
+		return nil, h.businessError(err)
+	}
+
+	return toMessage(*message), nil
+}
+
+// DeleteChat handles chat deletion, honouring if_match with the chat ETag.
+func (h *Handler) DeleteChat(ctx context.Context, req *chatv1.DeleteChatRequest) (*chatv1.DeleteChatResponse, error) {
+	if err := validateChatID(req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	messages, err := h.business.DeleteChat(ctx, req.GetChatId(), req.GetIfMatch())
+	if err != nil {
+		return nil, h.businessError(err)
+	}
+
+	return &chatv1.DeleteChatResponse{DeletedMessages: messages}, nil
+}
+
+// SubscribeChat streams chat events until the client goes away or the chat is deleted.
+func (h *Handler) SubscribeChat(req *chatv1.SubscribeChatRequest, stream grpc.ServerStreamingServer[chatv1.Event]) error {
+	chatID := req.GetChatId()
+	if err := validateChatID(chatID); err != nil {
+		return err
+	}
+
+	// Subscribe before checking the chat so no event slips in between
+	events, unsubscribe := h.subscriber.Subscribe(chatID)
+	defer unsubscribe()
+
+	ctx := stream.Context()
+	if _, err := h.business.ChatVersion(ctx, chatID, ""); err != nil {
+		return h.businessError(err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(toEvent(event)); err != nil {
+				return err
+			}
+			if event.Type == domain.EventChatDeleted {
+				return nil
+			}
+		}
+	}
+}
+
+// parseLimit applies default and bounds to the requested limit.
+func parseLimit(limit int32) int {
+	if limit == 0 {
+		return defaultLimit
+	}
+	return min(max(int(limit), minLimit), maxLimit)
+}
@@ -0,0 +1,185 @@
+package grpchandler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/business"
+	handler "github.com/Krokozabra213/test_api/internal/delivery/http"
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/internal/events"
+	chatv1 "github.com/Krokozabra213/test_api/pkg/api/chat/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeBusiness serves a single chat; methods not overridden panic through the nil interface.
+type fakeBusiness struct {
+	handler.Business
+	chat domain.Chat
+	bot  domain.Bot
+}
+
+func (f *fakeBusiness) CreateChat(_ context.Context, title string) (*domain.Chat, error) {
+	return &domain.Chat{ID: 2, Title: title, CreatedAt: time.Now()}, nil
+}
+
+func (f *fakeBusiness) ChatVersion(_ context.Context, chatID int64, _ string) (*domain.ChatVersion, error) {
+	if chatID != f.chat.ID {
+		return nil, business.ErrChatNotFound
+	}
+	return &domain.ChatVersion{ChatID: chatID, LastMessageID: 1}, nil
+}
+
+func (f *fakeBusiness) ReadChatMessages(_ context.Context, chatID int64, _ string, limit int,
+	_ *domain.ChatVersion,
+) (*domain.ChatMessageOutput, error) {
+	if chatID != f.chat.ID {
+		return nil, business.ErrChatNotFound
+	}
+	messages := []domain.Message{{ID: 1, ChatID: chatID, Text: "hello"}}
+	return domain.NewChatMessageOutput(chatID, f.chat.Title, f.chat.CreatedAt, nil, nil, messages[:min(limit, 1)]), nil
+}
+
+func (f *fakeBusiness) CreateMessage(_ context.Context, chatID int64, text string, _ []domain.AttachmentUpload) (*domain.Message, error) {
+	return &domain.Message{ID: 5, ChatID: chatID, Text: text}, nil
+}
+
+func (f *fakeBusiness) AuthenticateBot(_ context.Context, token string) (*domain.Bot, error) {
+	if token != "token" {
+		return nil, business.ErrInvalidBotToken
+	}
+	return &f.bot, nil
+}
+
+func (f *fakeBusiness) CreateBotMessage(_ context.Context, bot *domain.Bot, chatID int64, text string, _ []domain.AttachmentUpload) (*domain.Message, error) {
+	return &domain.Message{ID: 6, ChatID: chatID, BotID: &bot.ID, Text: text}, nil
+}
+
+func (f *fakeBusiness) DeleteChat(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, business.ErrTimeout
+}
+
+func newTestClient(t *testing.T, broker *events.Broker) chatv1.ChatServiceClient {
+	t.Helper()
+
+	biz := &fakeBusiness{
+		chat: domain.Chat{ID: 1, Title: "general", CreatedAt: time.Now()},
+		bot:  domain.Bot{ID: 7, Name: "echo"},
+	}
+	server := grpc.NewServer()
+	New(server, slog.New(slog.DiscardHandler), biz, broker)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return chatv1.NewChatServiceClient(conn)
+}
+
+func TestHandler_Unary(t *testing.T) {
+	client := newTestClient(t, events.NewBroker(slog.New(slog.DiscardHandler), 1))
+	ctx := context.Background()
+
+	chat, err := client.CreateChat(ctx, &chatv1.CreateChatRequest{Title: "  news "})
+	require.NoError(t, err)
+	assert.Equal(t, "news", chat.GetTitle())
+
+	_, err = client.CreateChat(ctx, &chatv1.CreateChatRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	got, err := client.GetChat(ctx, &chatv1.GetChatRequest{ChatId: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "general", got.GetChat().GetTitle())
+	assert.Len(t, got.GetMessages(), 1)
+	assert.NotEmpty(t, got.GetEtag())
+
+	_, err = client.GetChat(ctx, &chatv1.GetChatRequest{ChatId: 9})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.ListMessages(ctx, &chatv1.ListMessagesRequest{ChatId: 0})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	message, err := client.SendMessage(ctx, &chatv1.SendMessageRequest{ChatId: 1, Text: "hi"})
+	require.NoError(t, err)
+	assert.Nil(t, message.BotId)
+
+	botCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token")
+	message, err = client.SendMessage(botCtx, &chatv1.SendMessageRequest{ChatId: 1, Text: "beep"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), message.GetBotId())
+
+	badCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer nope")
+	_, err = client.SendMessage(badCtx, &chatv1.SendMessageRequest{ChatId: 1, Text: "beep"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.DeleteChat(ctx, &chatv1.DeleteChatRequest{ChatId: 1})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestHandler_SubscribeChat(t *testing.T) {
+	broker := events.NewBroker(slog.New(slog.DiscardHandler), 4)
+	client := newTestClient(t, broker)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	missing, err := client.SubscribeChat(ctx, &chatv1.SubscribeChatRequest{ChatId: 9})
+	require.NoError(t, err)
+	_, err = missing.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	stream, err := client.SubscribeChat(ctx, &chatv1.SubscribeChatRequest{ChatId: 1})
+	require.NoError(t, err)
+
+	// The subscription is registered asynchronously; publish until the first event arrives
+	received := make(chan *chatv1.Event, 1)
+	go func() {
+		event, err := stream.Recv()
+		if err == nil {
+			received <- event
+		}
+	}()
+	var first *chatv1.Event
+	for first == nil {
+		broker.Publish(ctx, domain.Event{ID: 1, Type: domain.EventMessageCreated, ChatID: 1})
+		select {
+		case first = <-received:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	assert.Equal(t, string(domain.EventMessageCreated), first.GetType())
+
+	broker.Publish(ctx, domain.Event{ID: 2, Type: domain.EventChatDeleted, ChatID: 1})
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if event.GetType() == string(domain.EventChatDeleted) {
+			_, err = stream.Recv()
+			assert.Equal(t, io.EOF, err)
+			break
+		}
+	}
+}
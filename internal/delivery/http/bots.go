@@ -0,0 +1,59 @@
+// Package handler provides HTTP handlers for API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/pkg/request"
+)
+
+// CreateBot handles bot account creation. The response carries the bot
+// credentials, which are never shown again.
+func (h *Handler) CreateBot() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := request.DecodeAndValidate[domain.CreateBotInput](r)
+		if err != nil {
+			h.respondRequestError(w, r, err) // 400 / 413
+			return
+		}
+		body.Sanitize()
+
+		bot, err := h.business.CreateBot(r.Context(), body)
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, bot)
+	}
+}
+
+// ListBots handles listing of bot accounts.
+func (h *Handler) ListBots() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bots, err := h.business.ListBots(r.Context())
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, bots)
+	}
+}
+
+// DeleteBot handles bot account removal.
+func (h *Handler) DeleteBot() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		botID, ok := h.parsePathID(w, r, "id", ErrInvalidBotID)
+		if !ok {
+			return
+		}
+
+		if err := h.business.DeleteBot(r.Context(), botID); err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,158 @@
+// Package handler provides HTTP handlers for API.
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Krokozabra213/test_api/internal/delivery/http/middleware"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Media types of the response encodings.
+const (
+	mediaTypeJSON    = "application/json"
+	mediaTypeMsgPack = "application/msgpack"
+	mediaTypeCBOR    = "application/cbor"
+)
+
+// codec encodes response bodies in one media type. Every codec reads the
+// json struct tags, so DTOs keep the same field names in every encoding.
+type codec struct {
+	contentType string
+	// tag is added to entity tags of responses in other encodings than JSON.
+	tag    string
+	encode func(v any) ([]byte, error)
+}
+
+var cborMode = func() cbor.EncMode {
+	mode, err := cbor.EncOptions{
+		Time:    cbor.TimeRFC3339Nano,
+		TimeTag: cbor.EncTagRequired,
+	}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return mode
+}()
+
+var (
+	jsonCodec = codec{
+		contentType: mediaTypeJSON + "; charset=utf-8",
+		encode: func(v any) ([]byte, error) {
+			var buf bytes.Buffer
+			err := json.NewEncoder(&buf).Encode(v)
+			return buf.Bytes(), err
+		},
+	}
+	msgpackCodec = codec{
+		contentType: mediaTypeMsgPack,
+		tag:         "msgpack",
+		encode: func(v any) ([]byte, error) {
+			var buf bytes.Buffer
+			enc := msgpack.NewEncoder(&buf)
+			enc.SetCustomStructTag("json")
+			err := enc.Encode(v)
+			return buf.Bytes(), err
+		},
+	}
+	cborCodec = codec{
+		contentType: mediaTypeCBOR,
+		tag:         "cbor",
+		encode:      cborMode.Marshal,
+	}
+)
+
+// codecs maps accepted media types to codecs, including msgpack aliases
+// still sent by older client libraries.
+var codecs = map[string]codec{
+	mediaTypeJSON:             jsonCodec,
+	mediaTypeMsgPack:          msgpackCodec,
+	"application/x-msgpack":   msgpackCodec,
+	"application/vnd.msgpack": msgpackCodec,
+	mediaTypeCBOR:             cborCodec,
+}
+
+// negotiateCodec picks the response codec from the Accept header, preferring
+// the highest q-value and JSON among equals. Requests that accept none of the
+// encodings get JSON rather than 406, so errors stay readable.
+func negotiateCodec(r *http.Request) codec {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return jsonCodec
+	}
+
+	best, bestQ := jsonCodec, 0.0
+	jsonQ := -1.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case "*/*", "application/*":
+			jsonQ = max(jsonQ, q)
+		case mediaTypeJSON:
+			jsonQ = q
+		default:
+			if c, ok := codecs[mediaType]; ok && q > bestQ {
+				best, bestQ = c, q
+			}
+		}
+	}
+
+	if bestQ == 0 || jsonQ >= bestQ {
+		return jsonCodec
+	}
+	return best
+}
+
+// writeResponse encodes data with the codec negotiated for the request.
+// An entity tag already set gets the codec added, unless it is JSON.
+func writeResponse(w http.ResponseWriter, r *http.Request, statusCode int, data any) error {
+	c := negotiateCodec(r)
+	body, err := c.encode(data)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", c.contentType)
+	setEncodingHeaders(w, c)
+	w.WriteHeader(statusCode)
+	_, err = w.Write(body)
+	return err
+}
+
+// setEncodingHeaders adds the codec to the entity tag already set, unless it is JSON,
+// and varies the response by Accept.
+func setEncodingHeaders(w http.ResponseWriter, c codec) {
+	header := w.Header()
+	if etag := header.Get("ETag"); etag != "" && c.tag != "" {
+		header.Set("ETag", middleware.TagRepresentation(etag, c.tag))
+	}
+	header.Add("Vary", "Accept")
+}
+
+// representationTag returns the entity tag a response with the given tag gets
+// for the request once encoded and compressed.
+func representationTag(r *http.Request, etag string) string {
+	if c := negotiateCodec(r); c.tag != "" {
+		etag = middleware.TagRepresentation(etag, c.tag)
+	}
+	if coding := middleware.Coding(r.Context()); coding != "" {
+		etag = middleware.TagRepresentation(etag, coding)
+	}
+	return etag
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestNegotiateCodec(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", jsonCodec.contentType},
+		{"*/*", jsonCodec.contentType},
+		{"application/msgpack", mediaTypeMsgPack},
+		{"application/x-msgpack", mediaTypeMsgPack},
+		{"application/cbor", mediaTypeCBOR},
+		{"application/cbor, application/json;q=0.5", mediaTypeCBOR},
+		{"application/cbor;q=0.5, application/json", jsonCodec.contentType},
+		{"application/cbor, */*;q=0.1", mediaTypeCBOR},
+		{"application/cbor, application/json", jsonCodec.contentType},
+		{"application/cbor;q=0", jsonCodec.contentType},
+		{"text/html", jsonCodec.contentType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+			r.Header.Set("Accept", tt.accept)
+			assert.Equal(t, tt.want, negotiateCodec(r).contentType)
+		})
+	}
+}
+
+func TestRespond_NegotiatesEncoding(t *testing.T) {
+	router := newTestRouter(&fakeBusiness{})
+
+	type summary struct {
+		ID    int64  `json:"id" msgpack:"id" cbor:"id"`
+		Title string `json:"title" msgpack:"title" cbor:"title"`
+	}
+
+	rec := serve(router, http.MethodGet, "/v1/chats", http.Header{"Accept": {mediaTypeMsgPack}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, mediaTypeMsgPack, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Values("Vary"), "Accept")
+	var fromMsgPack []summary
+	require.NoError(t, msgpack.Unmarshal(rec.Body.Bytes(), &fromMsgPack))
+	assert.Equal(t, []summary{{ID: 1, Title: "general"}}, fromMsgPack)
+
+	rec = serve(router, http.MethodGet, "/v1/chats", http.Header{"Accept": {mediaTypeCBOR}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, mediaTypeCBOR, rec.Header().Get("Content-Type"))
+	var fromCBOR []summary
+	require.NoError(t, cbor.Unmarshal(rec.Body.Bytes(), &fromCBOR))
+	assert.Equal(t, []summary{{ID: 1, Title: "general"}}, fromCBOR)
+
+	rec = serve(router, http.MethodGet, "/v1/chats", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var fromJSON []summary
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &fromJSON))
+	assert.Equal(t, []summary{{ID: 1, Title: "general"}}, fromJSON)
+}
+
+func TestWriteResponse_SameFieldsInEveryEncoding(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	zero := int64(0)
+	output := domain.NewChatMessageOutput(1, "general", createdAt, &zero, []domain.PinnedMessage{},
+		[]domain.Message{{ID: 1, ChatID: 1, CreatedAt: createdAt}})
+
+	decode := func(accept string, unmarshal func([]byte, any) error) map[string]any {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		require.NoError(t, writeResponse(rec, r, http.StatusOK, output))
+
+		var body map[string]any
+		require.NoError(t, unmarshal(rec.Body.Bytes(), &body))
+		return normalize(body).(map[string]any)
+	}
+	cborMode, err := cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]any(nil))}.DecMode()
+	require.NoError(t, err)
+
+	fromJSON := decode(mediaTypeJSON, json.Unmarshal)
+	assert.Contains(t, fromJSON, "unread_count")
+	assert.Equal(t, []any{}, fromJSON["pinned"])
+	assert.Equal(t, fromJSON, decode(mediaTypeMsgPack, msgpack.Unmarshal), "msgpack")
+	assert.Equal(t, fromJSON, decode(mediaTypeCBOR, cborMode.Unmarshal), "CBOR")
+}
+
+// normalize converts decoded numbers and times to their JSON forms.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = normalize(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = normalize(value)
+		}
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		f, _ := strconv.ParseFloat(fmt.Sprint(v), 64)
+		return f
+	}
+	return v
+}
+
+func TestRespondError_NegotiatesEncoding(t *testing.T) {
+	router := newTestRouter(&fakeBusiness{})
+
+	rec := serve(router, http.MethodGet, "/v1/chats/abc", http.Header{"Accept": {mediaTypeCBOR}})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, mediaTypeCBOR, rec.Header().Get("Content-Type"))
+
+	var body map[string]string
+	require.NoError(t, cbor.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, ErrInvalidChatID, body["error"])
+}
@@ -7,4 +7,24 @@ const (
 	ErrInternal       = "internal server error"
 	ErrNotFound       = "object not found"
 	ErrInvalidChatID  = "invalid chat id"
+
+	ErrPreconditionFailed = "precondition failed"
+
+	ErrInvalidMessageID = "invalid message id"
+	ErrInvalidCursor    = "invalid before cursor"
+	ErrPinLimitReached  = "pin limit reached"
+
+	ErrInvalidAttachmentID = "invalid attachment id"
+	ErrAttachmentTooLarge  = "attachment too large"
+	ErrAttachmentType      = "attachment type not allowed"
+	ErrTooManyAttachments  = "too many attachments"
+	ErrRequestTooLarge     = "request body too large"
+	ErrRangeNotSatisfiable = "requested range not satisfiable"
+
+	ErrInvalidWebhookID = "invalid webhook id"
+
+	ErrInvalidBotID    = "invalid bot id"
+	ErrInvalidBotToken = "invalid bot token"
+	ErrBotNameTaken    = "bot name taken"
+	ErrCommandConflict = "command already handled"
 )
@@ -3,9 +3,13 @@ package handler
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/Krokozabra213/test_api/internal/delivery/http/middleware"
 	"github.com/Krokozabra213/test_api/internal/domain"
 	"github.com/Krokozabra213/test_api/pkg/request"
 )
@@ -13,9 +17,31 @@ import (
 // Business defines business layer interface.
 type Business interface {
 	CreateChat(ctx context.Context, title string) (*domain.Chat, error)
-	DeleteChat(ctx context.Context, chatID int64) error
-	CreateMessage(ctx context.Context, chatID int64, text string) (*domain.Message, error)
-	ReadChatMessages(ctx context.Context, chatID int64, limit int) (*domain.ChatMessageOutput, error)
+	DeleteChat(ctx context.Context, chatID int64, ifMatch string) (int64, error)
+	ChatVersion(ctx context.Context, chatID int64, memberID string) (*domain.ChatVersion, error)
+	CreateMessage(ctx context.Context, chatID int64, text string, uploads []domain.AttachmentUpload) (*domain.Message, error)
+	ReadChatMessages(ctx context.Context, chatID int64, memberID string, limit int,
+		version *domain.ChatVersion) (*domain.ChatMessageOutput, error)
+	ListChatMessages(ctx context.Context, chatID int64, limit int, beforeID int64) ([]domain.Message, error)
+	ListChats(ctx context.Context, memberID string, limit int) ([]domain.ChatSummary, error)
+	MarkChatRead(ctx context.Context, chatID int64, memberID string, messageID int64) (*domain.ReadState, error)
+	PinMessage(ctx context.Context, chatID, messageID int64, memberID, ifMatch string) (*domain.PinnedMessage, error)
+	UnpinMessage(ctx context.Context, chatID, messageID int64, ifMatch string) error
+	ListPins(ctx context.Context, chatID int64) ([]domain.PinnedMessage, error)
+	RestoreChat(ctx context.Context, chatID int64) (*domain.Chat, error)
+	GetAttachment(ctx context.Context, chatID, attachmentID int64) (*domain.Attachment, error)
+	OpenAttachment(ctx context.Context, attachment *domain.Attachment, offset, length int64) (io.ReadCloser, error)
+	CreateWebhook(ctx context.Context, input domain.CreateWebhookInput) (*domain.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	GetWebhook(ctx context.Context, webhookID int64) (*domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID int64) error
+	EnableWebhook(ctx context.Context, webhookID int64) (*domain.Webhook, error)
+	ListWebhookDeliveries(ctx context.Context, webhookID int64, limit int) ([]domain.WebhookDelivery, error)
+	CreateBot(ctx context.Context, input domain.CreateBotInput) (*domain.CreatedBot, error)
+	ListBots(ctx context.Context) ([]domain.Bot, error)
+	DeleteBot(ctx context.Context, botID int64) error
+	AuthenticateBot(ctx context.Context, token string) (*domain.Bot, error)
+	CreateBotMessage(ctx context.Context, bot *domain.Bot, chatID int64, text string, uploads []domain.AttachmentUpload) (*domain.Message, error)
 }
 
 // Handler handles HTTP requests.
@@ -24,16 +50,108 @@ type Handler struct {
 	business Business
 }
 
-// NewHandler creates a new Handler and registers routes.
-func New(router *http.ServeMux, log *slog.Logger, business Business) {
+// route binds a ServeMux pattern to its handler.
+type route struct {
+	pattern string
+	handler http.Handler
+}
+
+// RouteLimits bounds the handling of a route.
+type RouteLimits struct {
+	// Timeout is the deadline of the request context, after which business
+	// calls fail with 504. Zero disables it.
+	Timeout time.Duration
+	// MaxBodyBytes caps the request body, larger bodies get 413. Zero disables it.
+	MaxBodyBytes int64
+}
+
+// Options configures the registered routes.
+type Options struct {
+	// Deprecation is announced on the unversioned legacy routes.
+	Deprecation Deprecation
+	// Limits apply to every route without an entry in RouteLimits.
+	Limits RouteLimits
+	// RouteLimits are keyed by unprefixed route pattern, e.g. "POST /chats/{id}/messages",
+	// and apply to the route in every version and its legacy alias.
+	RouteLimits map[string]RouteLimits
+	// Readiness checks the dependencies by name, e.g. "postgres", on GET /readyz.
+	Readiness map[string]ReadinessCheck
+}
+
+// NewHandler creates a new Handler and registers routes of every API version.
+// The current version is also mounted without a prefix for older clients,
+// with responses carrying the deprecation headers of the options.
+func New(router *http.ServeMux, log *slog.Logger, business Business, opts Options) {
 	handler := &Handler{
 		log:      log,
 		business: business,
 	}
-	router.HandleFunc("POST /chats", handler.CreateChat())
-	router.HandleFunc("POST /chats/{id}/messages", handler.SendMessage())
-	router.HandleFunc("GET /chats/{id}", handler.GetChatMessages())
-	router.HandleFunc("DELETE /chats/{id}", handler.DeleteChat())
+
+	known := make(map[string]bool)
+	for _, version := range handler.versions() {
+		for _, route := range version.routes {
+			known[route.pattern] = true
+			route.handler = handler.chain(route, opts)(route.handler)
+			mount(router, version.prefix, route)
+		}
+	}
+	for _, route := range handler.v1Routes() {
+		route.handler = handler.chain(route, opts)(deprecated(route.handler, currentVersion, opts.Deprecation))
+		mount(router, "", route)
+	}
+	router.Handle("GET "+docsPath, handler.Docs())
+	router.Handle("GET "+livenessPath, handler.Liveness())
+	router.Handle("GET "+readinessPath, handler.Readiness(opts.Readiness))
+
+	for pattern := range opts.RouteLimits {
+		if !known[pattern] {
+			log.Warn("route limits configured for unknown route", slog.String("pattern", pattern))
+		}
+	}
+}
+
+// chain returns the middleware every route runs through: panic recovery
+// outermost, so it also covers the limits, then the route deadline and body cap.
+func (h *Handler) chain(route route, opts Options) middleware.Middleware {
+	limits, ok := opts.RouteLimits[route.pattern]
+	if !ok {
+		limits = opts.Limits
+	}
+	return middleware.Chain(
+		middleware.Recover(h.log, func(w http.ResponseWriter, r *http.Request) {
+			h.respondError(w, r, http.StatusInternalServerError, ErrInternal)
+		}),
+		middleware.Timeout(limits.Timeout),
+		middleware.LimitBody(limits.MaxBodyBytes),
+	)
+}
+
+// v1Routes lists every route of API v1, described in openapi.json.
+func (h *Handler) v1Routes() []route {
+	return []route{
+		{"POST /chats", h.CreateChat()},
+		{"GET /chats", h.ListChats()},
+		{"POST /chats/{id}/messages", h.SendMessage()},
+		{"GET /chats/{id}/messages", h.ListMessages()},
+		{"GET /chats/{id}", h.GetChatMessages()},
+		{"DELETE /chats/{id}", h.DeleteChat()},
+		{"POST /chats/{id}/restore", h.RestoreChat()},
+		{"POST /chats/{id}/read", h.MarkChatRead()},
+		{"GET /chats/{id}/pins", h.ListPins()},
+		{"PUT /chats/{id}/pins/{messageID}", h.PinMessage()},
+		{"DELETE /chats/{id}/pins/{messageID}", h.UnpinMessage()},
+		{"GET /chats/{id}/attachments/{attachmentID}", h.GetAttachment()},
+		{"POST /webhooks", h.CreateWebhook()},
+		{"GET /webhooks", h.ListWebhooks()},
+		{"GET /webhooks/{id}", h.GetWebhook()},
+		{"DELETE /webhooks/{id}", h.DeleteWebhook()},
+		{"POST /webhooks/{id}/enable", h.EnableWebhook()},
+		{"GET /webhooks/{id}/deliveries", h.ListWebhookDeliveries()},
+		{"POST /bots", h.CreateBot()},
+		{"GET /bots", h.ListBots()},
+		{"DELETE /bots/{id}", h.DeleteBot()},
+		{"GET " + openAPIPath, h.OpenAPI()},
+	}
 }
 
 // CreateChat handles chat creation.
@@ -41,22 +159,24 @@ func (h *Handler) CreateChat() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		body, err := request.DecodeAndValidate[domain.CreateChatInput](r)
 		if err != nil {
-			h.respondError(w, http.StatusBadRequest, err.Error()) // 400
+			h.respondRequestError(w, r, err) // 400 / 413
 			return
 		}
 		body.Sanitize()
 
 		chat, err := h.business.CreateChat(r.Context(), body.Title)
 		if err != nil {
-			h.handleBusinessError(w, err)
+			h.handleBusinessError(w, r, err)
 			return
 		}
 
-		h.respond(w, http.StatusCreated, chat)
+		h.respond(w, r, http.StatusCreated, chat)
 	}
 }
 
-// SendMessage handles message creation.
+// SendMessage handles message creation. Accepts either a JSON body or
+// a multipart form with a "text" field and attached "files". A bot API token
+// in the Authorization header posts the message on behalf of the bot.
 func (h *Handler) SendMessage() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		chatID, ok := h.parseChatID(w, r)
@@ -64,24 +184,48 @@ func (h *Handler) SendMessage() http.HandlerFunc {
 			return
 		}
 
-		body, err := request.DecodeAndValidate[domain.CreateMessageInput](r)
+		bot, ok := h.authenticateBot(w, r)
+		if !ok {
+			return
+		}
+
+		var (
+			body    domain.CreateMessageInput
+			uploads []domain.AttachmentUpload
+			err     error
+		)
+		if isMultipart(r) {
+			var cleanup func()
+			body, uploads, cleanup, err = parseMultipartMessage(r)
+			defer cleanup()
+		} else {
+			body, err = request.DecodeAndValidate[domain.CreateMessageInput](r)
+		}
 		if err != nil {
-			h.respondError(w, http.StatusBadRequest, err.Error()) // 400
+			h.respondRequestError(w, r, err) // 400 / 413
 			return
 		}
 		body.Sanitize()
 
-		message, err := h.business.CreateMessage(r.Context(), chatID, body.Text)
+		var message *domain.Message
+		if bot != nil {
+			message, err = h.business.CreateBotMessage(r.Context(), bot, chatID, body.Text, uploads)
+		} else {
+			message, err = h.business.CreateMessage(r.Context(), chatID, body.Text, uploads)
+		}
 		if err != nil {
-			h.handleBusinessError(w, err)
+			h.handleBusinessError(w, r, err)
 			return
 		}
 
-		h.respond(w, http.StatusCreated, message)
+		h.respond(w, r, http.StatusCreated, message)
 	}
 }
 
-// GetChatMessages handles getting chat with messages.
+// GetChatMessages handles getting chat with messages. The ETag covers the chat
+// version and the member's read position; a matching If-None-Match gets 304
+// without loading the messages. Every encoding has its own tag, and a 304 carries
+// the tag and Vary headers of the response it stands for.
 func (h *Handler) GetChatMessages() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		chatID, ok := h.parseChatID(w, r)
@@ -89,19 +233,189 @@ func (h *Handler) GetChatMessages() http.HandlerFunc {
 			return
 		}
 
+		memberID, ok := h.parseMemberID(w, r, false)
+		if !ok {
+			return
+		}
+
+		limit := h.parseLimit(r)
+
+		// The version is read first, so a message added meanwhile makes
+		// the tag stale rather than the body
+		version, err := h.business.ChatVersion(r.Context(), chatID, memberID)
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		etag := version.ETag()
+		ifNoneMatch := r.Header.Get("If-None-Match")
+		if ifNoneMatch != "" && domain.MatchETagWeak(ifNoneMatch, representationTag(r, etag)) {
+			setValidatorHeaders(w, etag, version.LastModified())
+			setEncodingHeaders(w, negotiateCodec(r))
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		ChatMessage, err := h.business.ReadChatMessages(r.Context(), chatID, memberID, limit, version)
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		setValidatorHeaders(w, etag, version.LastModified())
+		h.respond(w, r, http.StatusCreated, ChatMessage)
+	}
+}
+
+// ListMessages handles paging through chat messages, newest first.
+// The before query parameter takes the ID of the oldest message already seen.
+func (h *Handler) ListMessages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chatID, ok := h.parseChatID(w, r)
+		if !ok {
+			return
+		}
+
+		var beforeID int64
+		if before := r.URL.Query().Get("before"); before != "" {
+			id, err := strconv.ParseInt(before, 10, 64)
+			if err != nil || id <= 0 {
+				h.respondError(w, r, http.StatusBadRequest, ErrInvalidCursor)
+				return
+			}
+			beforeID = id
+		}
+
+		messages, err := h.business.ListChatMessages(r.Context(), chatID, h.parseLimit(r), beforeID)
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, messages)
+	}
+}
+
+// ListChats handles listing of the newest chats.
+func (h *Handler) ListChats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		memberID, ok := h.parseMemberID(w, r, false)
+		if !ok {
+			return
+		}
+
 		limit := h.parseLimit(r)
 
-		ChatMessage, err := h.business.ReadChatMessages(r.Context(), chatID, limit)
+		chats, err := h.business.ListChats(r.Context(), memberID, limit)
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, chats)
+	}
+}
+
+// MarkChatRead handles advancing the member's read position in a chat.
+func (h *Handler) MarkChatRead() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chatID, ok := h.parseChatID(w, r)
+		if !ok {
+			return
+		}
+
+		memberID, ok := h.parseMemberID(w, r, true)
+		if !ok {
+			return
+		}
+
+		body, err := request.DecodeAndValidate[domain.MarkReadInput](r)
+		if err != nil {
+			h.respondRequestError(w, r, err) // 400 / 413
+			return
+		}
+
+		state, err := h.business.MarkChatRead(r.Context(), chatID, memberID, body.MessageID)
 		if err != nil {
-			h.handleBusinessError(w, err)
+			h.handleBusinessError(w, r, err)
 			return
 		}
 
-		h.respond(w, http.StatusCreated, ChatMessage)
+		h.respond(w, r, http.StatusOK, state)
 	}
 }
 
-// DeleteChat handles chat deletion.
+// ListPins handles getting chat pins.
+func (h *Handler) ListPins() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chatID, ok := h.parseChatID(w, r)
+		if !ok {
+			return
+		}
+
+		pins, err := h.business.ListPins(r.Context(), chatID)
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, pins)
+	}
+}
+
+// PinMessage handles pinning a message. Honours If-Match with the chat ETag.
+func (h *Handler) PinMessage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chatID, ok := h.parseChatID(w, r)
+		if !ok {
+			return
+		}
+
+		messageID, ok := h.parsePathID(w, r, "messageID", ErrInvalidMessageID)
+		if !ok {
+			return
+		}
+
+		memberID, ok := h.parseMemberID(w, r, false)
+		if !ok {
+			return
+		}
+
+		pin, err := h.business.PinMessage(r.Context(), chatID, messageID, memberID, r.Header.Get("If-Match"))
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, pin)
+	}
+}
+
+// UnpinMessage handles unpinning a message. Honours If-Match with the chat ETag.
+func (h *Handler) UnpinMessage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chatID, ok := h.parseChatID(w, r)
+		if !ok {
+			return
+		}
+
+		messageID, ok := h.parsePathID(w, r, "messageID", ErrInvalidMessageID)
+		if !ok {
+			return
+		}
+
+		err := h.business.UnpinMessage(r.Context(), chatID, messageID, r.Header.Get("If-Match"))
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// DeleteChat handles chat deletion. Honours If-Match with the chat ETag
+// and reports the number of deleted messages in the X-Deleted-Messages header.
 func (h *Handler) DeleteChat() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		chatID, ok := h.parseChatID(w, r)
@@ -109,11 +423,91 @@ func (h *Handler) DeleteChat() http.HandlerFunc {
 			return
 		}
 
-		err := h.business.DeleteChat(r.Context(), chatID)
+		messages, err := h.business.DeleteChat(r.Context(), chatID, r.Header.Get("If-Match"))
 		if err != nil {
-			h.handleBusinessError(w, err)
+			h.handleBusinessError(w, r, err)
 			return
 		}
+		w.Header().Set(deletedMessagesHeader, strconv.FormatInt(messages, 10))
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
+
+// GetAttachment streams attachment content, honouring single byte-range requests.
+func (h *Handler) GetAttachment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chatID, ok := h.parseChatID(w, r)
+		if !ok {
+			return
+		}
+		attachmentID, ok := h.parsePathID(w, r, "attachmentID", ErrInvalidAttachmentID)
+		if !ok {
+			return
+		}
+
+		attachment, err := h.business.GetAttachment(r.Context(), chatID, attachmentID)
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		etag := `"` + attachment.SHA256 + `"`
+		rangeHeader := r.Header.Get("Range")
+		if ifRange := r.Header.Get("If-Range"); ifRange != "" && ifRange != etag {
+			rangeHeader = ""
+		}
+
+		rng, err := parseRange(rangeHeader, attachment.Size)
+		if err != nil {
+			w.Header().Set("Content-Range", "bytes */"+strconv.FormatInt(attachment.Size, 10))
+			h.respondError(w, r, http.StatusRequestedRangeNotSatisfiable, ErrRangeNotSatisfiable) // 416
+			return
+		}
+
+		// A full download reads to the end of the object instead of asking for a range
+		status, offset, length, readLength := http.StatusOK, int64(0), attachment.Size, int64(-1)
+		if rng != nil {
+			status, offset, length = http.StatusPartialContent, rng.start, rng.length
+			readLength = length
+		}
+
+		body, err := h.business.OpenAttachment(r.Context(), attachment, offset, readLength)
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+		defer body.Close()
+
+		setAttachmentHeaders(w, attachment, etag)
+		w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+		if rng != nil {
+			w.Header().Set("Content-Range", rng.contentRange(attachment.Size))
+		}
+		w.WriteHeader(status)
+
+		if r.Method == http.MethodHead {
+			return
+		}
+		if _, err := io.Copy(w, body); err != nil {
+			h.log.Error("failed to stream attachment", "attachment_id", attachment.ID, "error", err)
+		}
+	}
+}
+
+// RestoreChat handles restoring a deleted chat.
+func (h *Handler) RestoreChat() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chatID, ok := h.parseChatID(w, r)
+		if !ok {
+			return
+		}
+
+		chat, err := h.business.RestoreChat(r.Context(), chatID)
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, chat)
+	}
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Krokozabra213/test_api/internal/business"
+	"github.com/Krokozabra213/test_api/internal/delivery/http/middleware"
+	"github.com/Krokozabra213/test_api/internal/domain"
+)
+
+type fakeBusiness struct {
+	Business
+	version domain.ChatVersion
+	reads   int
+}
+
+func (f *fakeBusiness) ListChats(ctx context.Context, memberID string, limit int) ([]domain.ChatSummary, error) {
+	return []domain.ChatSummary{{ID: 1, Title: "general"}}, nil
+}
+
+func (f *fakeBusiness) ChatVersion(ctx context.Context, chatID int64, memberID string) (*domain.ChatVersion, error) {
+	if chatID != f.version.ChatID {
+		return nil, business.ErrChatNotFound
+	}
+	version := f.version
+	version.MemberID = memberID
+	return &version, nil
+}
+
+func (f *fakeBusiness) ReadChatMessages(ctx context.Context, chatID int64, memberID string, limit int,
+	version *domain.ChatVersion,
+) (*domain.ChatMessageOutput, error) {
+	f.reads++
+	return &domain.ChatMessageOutput{ID: chatID, Title: "general"}, nil
+}
+
+func (f *fakeBusiness) UnpinMessage(ctx context.Context, chatID, messageID int64, ifMatch string) error {
+	current, _ := f.ChatVersion(ctx, chatID, "")
+	if ifMatch != "" && !domain.MatchETag(ifMatch, current.ETag()) {
+		return business.ErrPreconditionFailed
+	}
+	return nil
+}
+
+func newTestRouter(biz Business) *http.ServeMux {
+	router := http.NewServeMux()
+	New(router, slog.New(slog.DiscardHandler), biz, Options{})
+	return router
+}
+
+func serve(router http.Handler, method, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGetChatMessages_ConditionalGet(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	biz := &fakeBusiness{version: domain.ChatVersion{
+		ChatID:        1,
+		CreatedAt:     createdAt,
+		LastMessageID: 5,
+		LastMessageAt: createdAt.Add(time.Hour),
+	}}
+	router := newTestRouter(biz)
+
+	rec := serve(router, http.MethodGet, "/v1/chats/1", nil)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "Thu, 01 Jan 2026 01:00:00 GMT", rec.Header().Get("Last-Modified"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "X-Member-ID", rec.Header().Get("Vary"))
+	assert.Equal(t, 1, biz.reads)
+
+	rec = serve(router, http.MethodGet, "/v1/chats/1", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, etag, rec.Header().Get("ETag"))
+	assert.Equal(t, 1, biz.reads, "304 does not load messages")
+
+	rec = serve(router, http.MethodGet, "/v1/chats/1", http.Header{
+		"If-None-Match": {etag},
+		"X-Member-ID":   {"alice"},
+	})
+	assert.Equal(t, http.StatusCreated, rec.Code, "member representation has its own tag")
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+
+	biz.version.LastMessageID = 6
+	rec = serve(router, http.MethodGet, "/v1/chats/1", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+}
+
+func TestGetChatMessages_RepresentationTags(t *testing.T) {
+	biz := &fakeBusiness{version: domain.ChatVersion{ChatID: 1, LastMessageID: 5}}
+	handler := middleware.Compress(0)(newTestRouter(biz))
+
+	rec := serve(handler, http.MethodGet, "/v1/chats/1", nil)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	etag := rec.Header().Get("ETag")
+
+	compressed := http.Header{"Accept": {mediaTypeMsgPack}, "Accept-Encoding": {"gzip"}}
+	rec = serve(handler, http.MethodGet, "/v1/chats/1", compressed)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	compressedTag := rec.Header().Get("ETag")
+	assert.Equal(t, strings.TrimSuffix(etag, `"`)+`-msgpack-gzip"`, compressedTag,
+		"every representation has its own strong tag")
+
+	compressed.Set("If-None-Match", compressedTag)
+	rec = serve(handler, http.MethodGet, "/v1/chats/1", compressed)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, compressedTag, rec.Header().Get("ETag"), "a 304 carries the tag of the 200")
+	assert.ElementsMatch(t, []string{"Accept-Encoding", "X-Member-ID", "Accept"}, rec.Header().Values("Vary"))
+
+	rec = serve(handler, http.MethodGet, "/v1/chats/1", http.Header{"If-None-Match": {compressedTag}})
+	assert.Equal(t, http.StatusCreated, rec.Code, "a msgpack tag does not validate JSON")
+	assert.Equal(t, etag, rec.Header().Get("ETag"))
+
+	rec = serve(handler, http.MethodGet, "/v1/chats/1", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = serve(handler, http.MethodDelete, "/v1/chats/1/pins/5", http.Header{"If-Match": {compressedTag}})
+	assert.Equal(t, http.StatusNoContent, rec.Code, "tags of any representation match the chat version")
+}
+
+func TestUnpinMessage_IfMatch(t *testing.T) {
+	biz := &fakeBusiness{version: domain.ChatVersion{ChatID: 1, LastMessageID: 5}}
+	router := newTestRouter(biz)
+
+	rec := serve(router, http.MethodGet, "/v1/chats/1", http.Header{"X-Member-ID": {"alice"}})
+	etag := rec.Header().Get("ETag")
+
+	biz.version.PinCount = 1
+	rec = serve(router, http.MethodDelete, "/v1/chats/1/pins/5", http.Header{"If-Match": {etag}})
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	rec = serve(router, http.MethodGet, "/v1/chats/1", http.Header{"X-Member-ID": {"alice"}})
+	rec = serve(router, http.MethodDelete, "/v1/chats/1/pins/5", http.Header{"If-Match": {rec.Header().Get("ETag")}})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestRoutes_RecoverPanics(t *testing.T) {
+	// fakeBusiness leaves ListPins unimplemented, so calling it panics
+	router := newTestRouter(&fakeBusiness{})
+
+	rec := serve(router, http.MethodGet, "/v1/chats/1/pins", nil)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"error":"`+ErrInternal+`"}`, rec.Body.String())
+}
+
+func TestRoutes_Limits(t *testing.T) {
+	router := http.NewServeMux()
+	New(router, slog.New(slog.DiscardHandler), &fakeBusiness{}, Options{
+		Limits: RouteLimits{MaxBodyBytes: 16},
+		RouteLimits: map[string]RouteLimits{
+			"POST /chats/{id}/read": {MaxBodyBytes: 1 << 10},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chats", strings.NewReader(`{"title":"a title longer than the limit"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.JSONEq(t, `{"error":"`+ErrRequestTooLarge+`"}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/chats", strings.NewReader(`{"title":"a title longer than the limit"}`))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code, "legacy aliases share the limits")
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/chats/1/read", strings.NewReader(`{"message_id":123456789012345}`))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.NotEqual(t, http.StatusRequestEntityTooLarge, rec.Code, "route overrides replace the default limits")
+}
+
+func TestHealth(t *testing.T) {
+	var dbErr error
+	router := http.NewServeMux()
+	New(router, slog.New(slog.DiscardHandler), &fakeBusiness{}, Options{
+		Readiness: map[string]ReadinessCheck{
+			"postgres": func(ctx context.Context) error { return dbErr },
+		},
+	})
+
+	rec := serve(router, http.MethodGet, "/healthz", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+
+	rec = serve(router, http.MethodGet, "/readyz", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok","checks":{"postgres":"ok"}}`, rec.Body.String())
+
+	dbErr = errors.New("database not ready: primary down")
+	rec = serve(router, http.MethodGet, "/readyz", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"status":"unavailable","checks":{"postgres":"database not ready: primary down"}}`, rec.Body.String())
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const (
+	// livenessPath answers as long as the server handles requests.
+	livenessPath = "/healthz"
+	// readinessPath answers 200 while every readiness check passes.
+	readinessPath = "/readyz"
+
+	readinessTimeout = 2 * time.Second
+)
+
+// ReadinessCheck reports whether a dependency can serve requests.
+type ReadinessCheck func(ctx context.Context) error
+
+// Health is the body of the health endpoints.
+type Health struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Liveness reports that the server is running.
+func (h *Handler) Liveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.respond(w, r, http.StatusOK, Health{Status: "ok"})
+	}
+}
+
+// Readiness runs the checks and answers 503 listing the failed ones, so that
+// load balancers stop routing requests while a dependency is down.
+func (h *Handler) Readiness(checks map[string]ReadinessCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		health := Health{Status: "ok", Checks: make(map[string]string, len(checks))}
+		statusCode := http.StatusOK
+		for name, check := range checks {
+			if err := check(ctx); err != nil {
+				health.Status = "unavailable"
+				health.Checks[name] = err.Error()
+				statusCode = http.StatusServiceUnavailable
+				continue
+			}
+			health.Checks[name] = "ok"
+		}
+		h.respond(w, r, statusCode, health)
+	}
+}
@@ -3,10 +3,26 @@ package handler
 
 import (
 	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Krokozabra213/test_api/internal/business"
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/pkg/request"
+)
+
+const (
+	// memberIDHeader identifies the chat member on whose behalf the request is made.
+	memberIDHeader = "X-Member-ID"
+	// deletedMessagesHeader reports how many messages were deleted with a chat.
+	deletedMessagesHeader = "X-Deleted-Messages"
+	// bearerPrefix starts the Authorization header carrying a bot API token.
+	bearerPrefix = "Bearer "
 )
 
 // Limit constraints
@@ -16,29 +32,61 @@ const (
 	maxLimit     = 100
 )
 
-// respond sends JSON response with logging on error.
-func (h *Handler) respond(w http.ResponseWriter, statusCode int, data any) {
-	if err := JSONResp(w, statusCode, data); err != nil {
+// Multipart message constraints. Per-file size, count and type limits
+// are enforced by the business layer, the whole request body is capped by
+// the body limit of the route.
+const (
+	multipartMemory = 8 << 20
+	textFormField   = "text"
+	filesFormField  = "files"
+)
+
+// respond sends the response in the encoding negotiated via Accept, JSON by default,
+// with logging on error.
+func (h *Handler) respond(w http.ResponseWriter, r *http.Request, statusCode int, data any) {
+	if err := writeResponse(w, r, statusCode, data); err != nil {
 		h.log.Error("failed to send response", "error", err)
 	}
 }
 
-// respondError sends JSON error response with logging on error.
-func (h *Handler) respondError(w http.ResponseWriter, statusCode int, message string) {
-	if err := JSONError(w, statusCode, message); err != nil {
+// respondError sends the {"error": message} response in the negotiated encoding
+// with logging on error.
+func (h *Handler) respondError(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	if err := writeResponse(w, r, statusCode, map[string]string{"error": message}); err != nil {
 		h.log.Error("failed to send error response", "error", err)
 	}
 }
 
 // handleBusinessError maps business errors to HTTP responses.
-func (h *Handler) handleBusinessError(w http.ResponseWriter, err error) {
+func (h *Handler) handleBusinessError(w http.ResponseWriter, r *http.Request, err error) {
 	switch {
-	case errors.Is(err, business.ErrChatNotFound):
-		h.respondError(w, http.StatusNotFound, ErrNotFound)
+	case errors.Is(err, business.ErrChatNotFound),
+		errors.Is(err, business.ErrMessageNotFound),
+		errors.Is(err, business.ErrPinNotFound),
+		errors.Is(err, business.ErrAttachmentNotFound),
+		errors.Is(err, business.ErrWebhookNotFound),
+		errors.Is(err, business.ErrBotNotFound):
+		h.respondError(w, r, http.StatusNotFound, ErrNotFound)
+	case errors.Is(err, business.ErrPreconditionFailed):
+		h.respondError(w, r, http.StatusPreconditionFailed, ErrPreconditionFailed)
+	case errors.Is(err, business.ErrInvalidBotToken):
+		h.respondError(w, r, http.StatusUnauthorized, ErrInvalidBotToken)
+	case errors.Is(err, business.ErrBotNameTaken):
+		h.respondError(w, r, http.StatusConflict, ErrBotNameTaken)
+	case errors.Is(err, business.ErrCommandConflict):
+		h.respondError(w, r, http.StatusConflict, ErrCommandConflict)
+	case errors.Is(err, business.ErrPinLimitReached):
+		h.respondError(w, r, http.StatusConflict, ErrPinLimitReached)
+	case errors.Is(err, business.ErrTooManyAttachments):
+		h.respondError(w, r, http.StatusBadRequest, ErrTooManyAttachments)
+	case errors.Is(err, business.ErrAttachmentTooLarge):
+		h.respondError(w, r, http.StatusRequestEntityTooLarge, ErrAttachmentTooLarge)
+	case errors.Is(err, business.ErrAttachmentType):
+		h.respondError(w, r, http.StatusUnsupportedMediaType, ErrAttachmentType)
 	case errors.Is(err, business.ErrTimeout):
-		h.respondError(w, http.StatusGatewayTimeout, ErrRequestTimeout)
+		h.respondError(w, r, http.StatusGatewayTimeout, ErrRequestTimeout)
 	default:
-		h.respondError(w, http.StatusInternalServerError, ErrInternal)
+		h.respondError(w, r, http.StatusInternalServerError, ErrInternal)
 	}
 }
 
@@ -58,19 +106,159 @@ func (h *Handler) parseLimit(r *http.Request) int {
 	return clamp(limit, minLimit, maxLimit)
 }
 
+// authenticateBot resolves the bot from a bearer token in the Authorization header.
+// Returns nil bot if the header is absent.
+func (h *Handler) authenticateBot(w http.ResponseWriter, r *http.Request) (*domain.Bot, bool) {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		return nil, true
+	}
+
+	token, ok := strings.CutPrefix(authorization, bearerPrefix)
+	token = strings.TrimSpace(token)
+	if !ok || token == "" {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.respondError(w, r, http.StatusUnauthorized, ErrInvalidBotToken)
+		return nil, false
+	}
+
+	bot, err := h.business.AuthenticateBot(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, business.ErrInvalidBotToken) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+		}
+		h.handleBusinessError(w, r, err)
+		return nil, false
+	}
+
+	return bot, true
+}
+
+// respondRequestError maps request decoding errors to HTTP responses.
+func (h *Handler) respondRequestError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		h.respondError(w, r, http.StatusRequestEntityTooLarge, ErrRequestTooLarge)
+		return
+	}
+	h.respondError(w, r, http.StatusBadRequest, err.Error())
+}
+
 // parseChatID extracts and validates chat ID from path.
 func (h *Handler) parseChatID(w http.ResponseWriter, r *http.Request) (int64, bool) {
-	chatIDString := r.PathValue("id")
-	chatID, err := strconv.ParseInt(chatIDString, 10, 64)
+	return h.parsePathID(w, r, "id", ErrInvalidChatID)
+}
+
+// parsePathID extracts and validates a positive ID from the named path segment.
+func (h *Handler) parsePathID(w http.ResponseWriter, r *http.Request, name, errMessage string) (int64, bool) {
+	idString := r.PathValue(name)
+	id, err := strconv.ParseInt(idString, 10, 64)
 	if err != nil {
-		h.respondError(w, http.StatusBadRequest, ErrInvalidChatID)
+		h.respondError(w, r, http.StatusBadRequest, errMessage)
 		return 0, false
 	}
 
-	if chatID <= 0 {
-		h.respondError(w, http.StatusBadRequest, ErrInvalidChatID)
+	if id <= 0 {
+		h.respondError(w, r, http.StatusBadRequest, errMessage)
 		return 0, false
 	}
 
-	return chatID, true
+	return id, true
+}
+
+// parseMemberID extracts and validates member ID from the X-Member-ID header.
+// An absent header yields an empty ID unless required is set.
+func (h *Handler) parseMemberID(w http.ResponseWriter, r *http.Request, required bool) (string, bool) {
+	memberID := strings.TrimSpace(r.Header.Get(memberIDHeader))
+	if memberID == "" && !required {
+		return "", true
+	}
+
+	if err := domain.ValidateMemberID(memberID); err != nil {
+		h.respondError(w, r, http.StatusBadRequest, err.Error())
+		return "", false
+	}
+
+	return memberID, true
+}
+
+// isMultipart reports whether the request carries a multipart form body.
+func isMultipart(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// parseMultipartMessage reads message text and attached files from a multipart form.
+// Large files are spooled to temporary files; the returned cleanup closes and removes them.
+func parseMultipartMessage(r *http.Request) (domain.CreateMessageInput, []domain.AttachmentUpload, func(), error) {
+	var (
+		input   domain.CreateMessageInput
+		files   []multipart.File
+		cleanup = func() {}
+	)
+
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		return input, nil, cleanup, fmt.Errorf("%w: %w", request.ErrDecode, err)
+	}
+	form := r.MultipartForm
+	cleanup = func() {
+		for _, file := range files {
+			file.Close()
+		}
+		form.RemoveAll()
+	}
+
+	if values := form.Value[textFormField]; len(values) > 0 {
+		input.Text = values[0]
+	}
+	if err := input.Validate(); err != nil {
+		return input, nil, cleanup, err
+	}
+
+	headers := form.File[filesFormField]
+	uploads := make([]domain.AttachmentUpload, 0, len(headers))
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			return input, nil, cleanup, fmt.Errorf("%w: %w", request.ErrDecode, err)
+		}
+		files = append(files, file)
+
+		upload := domain.AttachmentUpload{
+			Filename: header.Filename,
+			Size:     header.Size,
+			Body:     file,
+		}
+		upload.Sanitize()
+		uploads = append(uploads, upload)
+	}
+
+	return input, uploads, cleanup, nil
+}
+
+// setAttachmentHeaders sets headers describing downloaded attachment content.
+// Content is always served as a download and never sniffed by the browser.
+func setAttachmentHeaders(w http.ResponseWriter, attachment *domain.Attachment, etag string) {
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", attachment.ContentType)
+	header.Set("Content-Disposition", disposition)
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("ETag", etag)
+	header.Set("Last-Modified", attachment.CreatedAt.UTC().Format(http.TimeFormat))
+}
+
+// setValidatorHeaders sets validators of a chat representation. The response
+// varies by member, and caches must revalidate it on every use.
+func setValidatorHeaders(w http.ResponseWriter, etag string, lastModified time.Time) {
+	header := w.Header()
+	header.Set("ETag", etag)
+	header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	header.Set("Cache-Control", "no-cache")
+	header.Add("Vary", memberIDHeader)
 }
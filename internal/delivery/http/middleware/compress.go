@@ -0,0 +1,330 @@
+// Package middleware provides HTTP middleware shared by the API transports.
+package middleware
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// encoder is a pooled compressing writer.
+type encoder interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+// encoding is a content coding with a pool of its encoders.
+type encoding struct {
+	name string
+	pool *sync.Pool
+}
+
+// get returns an encoder writing to w.
+func (e encoding) get(w io.Writer) encoder {
+	enc := e.pool.Get().(encoder)
+	enc.Reset(w)
+	return enc
+}
+
+// encodings lists supported content codings in server preference order,
+// used to break ties between equal q-values. zstd compresses JSON about
+// as well as brotli at a fraction of the CPU cost.
+var encodings = []encoding{
+	{name: "zstd", pool: &sync.Pool{New: func() any {
+		enc, err := zstd.NewWriter(nil,
+			zstd.WithEncoderLevel(zstd.SpeedDefault),
+			zstd.WithEncoderConcurrency(1),
+			zstd.WithWindowSize(1<<20),
+		)
+		if err != nil {
+			panic(err)
+		}
+		return enc
+	}}},
+	{name: "br", pool: &sync.Pool{New: func() any {
+		return brotli.NewWriterLevel(nil, 5)
+	}}},
+	{name: "gzip", pool: &sync.Pool{New: func() any {
+		return gzip.NewWriter(nil)
+	}}},
+}
+
+// Compress encodes response bodies with the best content coding the client
+// accepts via Accept-Encoding. Bodies are buffered up to minSize bytes and
+// smaller ones are sent as is, since compression would not pay off.
+//
+// Responses that already have a content coding, partial content, range-capable
+// downloads and non-text media types pass through unchanged, as do HEAD and
+// upgrade requests. Compressed responses get the coding added to their entity
+// tag, since a strong tag identifies the exact bytes of a representation.
+// Responses with an entity tag and a media type are compressed regardless of
+// their size, and a 304 gets the coding added to its tag as well, so that it
+// carries the tag of the representation it validates; Coding tells handlers
+// that tag before they respond.
+func Compress(minSize int) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			enc, ok := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, encoding: enc, minSize: minSize}
+			defer cw.close()
+			next.ServeHTTP(cw, r.WithContext(context.WithValue(r.Context(), codingKey{}, enc.name)))
+		})
+	}
+}
+
+type codingKey struct{}
+
+// Coding returns the content coding Compress negotiated for the request, empty
+// without one. Responses with an entity tag are encoded with it when their
+// headers allow a coding.
+func Coding(ctx context.Context) string {
+	coding, _ := ctx.Value(codingKey{}).(string)
+	return coding
+}
+
+// TagRepresentation appends the name of a media type or content coding to an
+// entity tag, so that every representation of a resource gets a distinct tag:
+// "abc" becomes "abc-gzip". Malformed tags are returned unchanged.
+func TagRepresentation(etag, name string) string {
+	if len(etag) < 2 || !strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+	return etag[:len(etag)-1] + "-" + name + `"`
+}
+
+// negotiateEncoding picks the supported coding with the highest q-value.
+// A "*" applies to codings not listed explicitly.
+func negotiateEncoding(header string) (encoding, bool) {
+	if header == "" {
+		return encoding{}, false
+	}
+
+	listed := make(map[string]float64)
+	wildcard := 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if name == "*" {
+			wildcard = q
+			continue
+		}
+		listed[name] = q
+	}
+
+	var best encoding
+	bestQ := 0.0
+	for _, enc := range encodings {
+		q, ok := listed[enc.name]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// compressWriter buffers the head of the body until it reaches minSize,
+// then decides whether to compress the response.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding encoding
+	minSize  int
+
+	status    int
+	buf       []byte
+	committed bool
+	enc       encoder
+}
+
+func (cw *compressWriter) WriteHeader(statusCode int) {
+	if cw.committed || cw.status != 0 {
+		return
+	}
+	if statusCode < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	cw.status = statusCode
+	header := cw.Header()
+	switch {
+	case statusCode == http.StatusNotModified:
+		if codingAllowed(header) {
+			cw.tagETag()
+		}
+		_ = cw.commit(false)
+	case !cw.compressible():
+		_ = cw.commit(false)
+	case header.Get("ETag") != "" && header.Get("Content-Type") != "":
+		// The tag must not depend on the size of the body, which a 304 lacks
+		_ = cw.commit(true)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.committed {
+		if cw.enc != nil {
+			return cw.enc.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.commit(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush commits the response with compression regardless of its size,
+// since the final size of a streamed body is unknown.
+func (cw *compressWriter) Flush() {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.committed {
+		if err := cw.commit(true); err != nil {
+			return
+		}
+	}
+	if cw.enc != nil {
+		if err := cw.enc.Flush(); err != nil {
+			return
+		}
+	}
+	_ = http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// compressible reports whether the response status and headers allow a content coding.
+func (cw *compressWriter) compressible() bool {
+	switch cw.status {
+	case http.StatusNoContent, http.StatusPartialContent, http.StatusNotModified:
+		return false
+	}
+	return codingAllowed(cw.Header())
+}
+
+// codingAllowed reports whether the response headers allow a content coding.
+func codingAllowed(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" ||
+		header.Get("Content-Range") != "" ||
+		header.Get("Accept-Ranges") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	return contentType == "" || compressibleType(contentType)
+}
+
+// tagETag adds the content coding to the entity tag of the response, if any.
+func (cw *compressWriter) tagETag() {
+	header := cw.Header()
+	if etag := header.Get("ETag"); etag != "" {
+		header.Set("ETag", TagRepresentation(etag, cw.encoding.name))
+	}
+}
+
+// commit writes the status line, headers and buffered body, starting the
+// encoder when compress is set and the body's media type allows it.
+func (cw *compressWriter) commit(compress bool) error {
+	cw.committed = true
+
+	header := cw.Header()
+	if header.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		// Sniff before encoding, net/http would sniff the compressed bytes
+		header.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	if compress && cw.compressible() {
+		header.Set("Content-Encoding", cw.encoding.name)
+		header.Del("Content-Length")
+		cw.tagETag()
+		cw.enc = cw.encoding.get(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if cw.enc != nil {
+		_, err := cw.enc.Write(buf)
+		return err
+	}
+	_, err := cw.ResponseWriter.Write(buf)
+	return err
+}
+
+// close sends a body left below minSize and finishes the encoded stream.
+func (cw *compressWriter) close() {
+	if !cw.committed {
+		if cw.status == 0 {
+			return
+		}
+		_ = cw.commit(false)
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Close()
+		cw.enc.Reset(nil)
+		cw.encoding.pool.Put(cw.enc)
+		cw.enc = nil
+	}
+}
+
+// compressibleType reports whether the media type benefits from compression.
+// Images, audio, video and archives are already compressed.
+func compressibleType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/javascript", "application/xml",
+		"application/msgpack", "application/cbor", "application/x-ndjson":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"gzip, deflate, br", "br"},
+		{"gzip, deflate, br, zstd", "zstd"},
+		{"br;q=0.5, gzip", "gzip"},
+		{"*", "zstd"},
+		{"gzip;q=0.1, *;q=0.5, zstd;q=0", "br"},
+		{"GZIP", "gzip"},
+		{"gzip;q=0", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			enc, ok := negotiateEncoding(tt.header)
+			assert.Equal(t, tt.want != "", ok)
+			assert.Equal(t, tt.want, enc.name)
+		})
+	}
+}
+
+func decode(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+
+	var r io.Reader
+	switch encoding {
+	case "gzip":
+		gr, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		r = gr
+	case "br":
+		r = brotli.NewReader(bytes.NewReader(body))
+	case "zstd":
+		zr, err := zstd.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		defer zr.Close()
+		r = zr
+	default:
+		return string(body)
+	}
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestCompress(t *testing.T) {
+	large := `{"text":"` + strings.Repeat("hello ", 200) + `"}`
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		header         http.Header
+		body           string
+		wantEncoding   string
+	}{
+		{name: "gzip", acceptEncoding: "gzip", contentType: "application/json", body: large, wantEncoding: "gzip"},
+		{name: "brotli", acceptEncoding: "br", contentType: "application/json", body: large, wantEncoding: "br"},
+		{name: "zstd", acceptEncoding: "zstd", contentType: "application/msgpack", body: large, wantEncoding: "zstd"},
+		{name: "below threshold", acceptEncoding: "gzip", contentType: "application/json", body: `{"id":1}`},
+		{name: "not accepted", contentType: "application/json", body: large},
+		{name: "image", acceptEncoding: "gzip", contentType: "image/png", body: large},
+		{name: "ranged download", acceptEncoding: "gzip", contentType: "text/plain",
+			header: http.Header{"Accept-Ranges": {"bytes"}}, body: large},
+		{name: "sniffed type", acceptEncoding: "gzip", body: "<html>" + large, wantEncoding: "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Compress(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				for key, values := range tt.header {
+					w.Header()[key] = values
+				}
+				w.Header().Set("Content-Length", strconv.Itoa(len(tt.body)))
+				w.WriteHeader(http.StatusCreated)
+				// Several writes cross the threshold midway
+				for chunk := range slicesOf(tt.body, 100) {
+					_, _ = io.WriteString(w, chunk)
+				}
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Equal(t, tt.wantEncoding, rec.Header().Get("Content-Encoding"))
+			assert.Contains(t, rec.Header().Values("Vary"), "Accept-Encoding")
+			if tt.wantEncoding != "" {
+				assert.Empty(t, rec.Header().Get("Content-Length"))
+				assert.Less(t, rec.Body.Len(), len(tt.body))
+			}
+			assert.Equal(t, tt.body, decode(t, tt.wantEncoding, rec.Body.Bytes()))
+		})
+	}
+}
+
+func TestCompress_NotModified(t *testing.T) {
+	handler := Compress(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusNotModified)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Zero(t, rec.Body.Len())
+	assert.Equal(t, `"v1-gzip"`, rec.Header().Get("ETag"), "the tag of the compressed representation")
+}
+
+func TestCompress_TaggedResponses(t *testing.T) {
+	tests := []struct {
+		name         string
+		contentType  string
+		header       http.Header
+		wantEncoding string
+		wantETag     string
+	}{
+		{name: "below threshold", contentType: "application/json", wantEncoding: "gzip", wantETag: `"v1-gzip"`},
+		{name: "image", contentType: "image/png", wantETag: `"v1"`},
+		{name: "ranged download", contentType: "text/plain", header: http.Header{"Accept-Ranges": {"bytes"}},
+			wantETag: `"v1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var coding string
+			handler := Compress(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				coding = Coding(r.Context())
+				for key, values := range tt.header {
+					w.Header()[key] = values
+				}
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Header().Set("ETag", `"v1"`)
+				_, _ = io.WriteString(w, `{"id":1}`)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, "gzip", coding)
+			assert.Equal(t, tt.wantEncoding, rec.Header().Get("Content-Encoding"))
+			assert.Equal(t, tt.wantETag, rec.Header().Get("ETag"))
+			assert.Equal(t, `{"id":1}`, decode(t, tt.wantEncoding, rec.Body.Bytes()))
+		})
+	}
+}
+
+func TestCompress_FlushStreams(t *testing.T) {
+	handler := Compress(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: 1\n\n")
+		require.NoError(t, http.NewResponseController(w).Flush())
+		_, _ = io.WriteString(w, "data: 2\n\n")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "data: 1\n\ndata: 2\n\n", decode(t, "gzip", rec.Body.Bytes()))
+}
+
+// slicesOf yields s in chunks of at most n bytes.
+func slicesOf(s string, n int) func(yield func(string) bool) {
+	return func(yield func(string) bool) {
+		for len(s) > 0 {
+			chunk := s[:min(n, len(s))]
+			s = s[len(chunk):]
+			if !yield(chunk) {
+				return
+			}
+		}
+	}
+}
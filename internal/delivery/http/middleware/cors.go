@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// CORSOptions is the cross-origin policy of the API.
+type CORSOptions struct {
+	// AllowedOrigins are exact origins such as "https://app.example.com" or
+	// patterns with one wildcard such as "https://*.example.com". "*" allows any origin.
+	AllowedOrigins []string
+	AllowedMethods []string
+	// AllowedHeaders are request headers a preflight may ask for; "*" allows any.
+	AllowedHeaders []string
+	// ExposedHeaders are response headers readable by scripts.
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+}
+
+// Router finds the handler registered for a request, as http.ServeMux does.
+type Router interface {
+	Handler(r *http.Request) (h http.Handler, pattern string)
+}
+
+// CORS applies a cross-origin policy that can be replaced at runtime.
+type CORS struct {
+	router Router
+	policy atomic.Pointer[corsPolicy]
+}
+
+// corsPolicy is CORSOptions with header values prepared once.
+type corsPolicy struct {
+	CORSOptions
+	allowedMethods string
+	exposedHeaders string
+	anyHeader      bool
+	maxAge         string
+}
+
+// NewCORS creates the policy. The router is consulted for preflight requests.
+func NewCORS(opts CORSOptions, router Router) *CORS {
+	c := &CORS{router: router}
+	c.SetOptions(opts)
+	return c
+}
+
+// SetOptions replaces the policy for subsequent requests.
+func (c *CORS) SetOptions(opts CORSOptions) {
+	c.policy.Store(&corsPolicy{
+		CORSOptions:    opts,
+		allowedMethods: strings.Join(opts.AllowedMethods, ", "),
+		exposedHeaders: strings.Join(opts.ExposedHeaders, ", "),
+		anyHeader:      slices.Contains(opts.AllowedHeaders, "*"),
+		maxAge:         strconv.Itoa(int(opts.MaxAge.Seconds())),
+	})
+}
+
+// Middleware applies the policy. Without allowed origins requests pass through
+// untouched. Preflight requests are answered here when the router has a route
+// for the requested method, since method patterns of http.ServeMux reply 405
+// to OPTIONS. Preflights for unknown routes reach the router, and disallowed
+// ones get no CORS headers, which the browser rejects.
+func (c *CORS) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			opts := c.policy.Load()
+			if len(opts.AllowedOrigins) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			origin := r.Header.Get("Origin")
+			requestMethod := r.Header.Get("Access-Control-Request-Method")
+			header := w.Header()
+
+			if r.Method != http.MethodOptions || requestMethod == "" {
+				header.Add("Vary", "Origin")
+				if origin != "" && matchOrigin(opts.AllowedOrigins, origin) {
+					header.Set("Access-Control-Allow-Origin", origin)
+					if opts.AllowCredentials {
+						header.Set("Access-Control-Allow-Credentials", "true")
+					}
+					if opts.exposedHeaders != "" {
+						header.Set("Access-Control-Expose-Headers", opts.exposedHeaders)
+					}
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			probe := r.Clone(r.Context())
+			probe.Method = requestMethod
+			if _, pattern := c.router.Handler(probe); pattern == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header.Add("Vary", "Origin")
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			requestHeaders := r.Header.Get("Access-Control-Request-Headers")
+			if matchOrigin(opts.AllowedOrigins, origin) &&
+				slices.Contains(opts.AllowedMethods, requestMethod) &&
+				(opts.anyHeader || allowedHeaders(opts.AllowedHeaders, requestHeaders)) {
+				header.Set("Access-Control-Allow-Origin", origin)
+				header.Set("Access-Control-Allow-Methods", opts.allowedMethods)
+				if requestHeaders != "" {
+					header.Set("Access-Control-Allow-Headers", requestHeaders)
+				}
+				if opts.AllowCredentials {
+					header.Set("Access-Control-Allow-Credentials", "true")
+				}
+				if opts.MaxAge > 0 {
+					header.Set("Access-Control-Max-Age", opts.maxAge)
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// matchOrigin reports whether the origin matches any of the patterns.
+// A wildcard matches a non-empty run of host characters, so
+// "https://*.example.com" allows subdomains but not "https://example.com".
+func matchOrigin(patterns []string, origin string) bool {
+	if origin == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if pattern == "*" || strings.EqualFold(pattern, origin) {
+			return true
+		}
+		prefix, suffix, ok := strings.Cut(strings.ToLower(pattern), "*")
+		if !ok {
+			continue
+		}
+		lower := strings.ToLower(origin)
+		if len(lower) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(lower, prefix) && strings.HasSuffix(lower, suffix) {
+			wildcard := lower[len(prefix) : len(lower)-len(suffix)]
+			if !strings.ContainsAny(wildcard, "/:") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allowedHeaders reports whether every header of the comma separated list is allowed.
+func allowedHeaders(allowed []string, requested string) bool {
+	for name := range strings.SplitSeq(requested, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.ContainsFunc(allowed, func(h string) bool { return strings.EqualFold(h, name) }) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCORSHandler(opts CORSOptions) http.Handler {
+	handler, _ := newReloadableCORSHandler(opts)
+	return handler
+}
+
+func newReloadableCORSHandler(opts CORSOptions) (http.Handler, *CORS) {
+	router := http.NewServeMux()
+	router.HandleFunc("GET /v1/chats/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.HandleFunc("DELETE /v1/chats/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	cors := NewCORS(opts, router)
+	return cors.Middleware()(router), cors
+}
+
+var testCORSOptions = CORSOptions{
+	AllowedOrigins:   []string{"https://app.example.com", "https://*.preview.example.com"},
+	AllowedMethods:   []string{http.MethodGet, http.MethodDelete},
+	AllowedHeaders:   []string{"Content-Type", "X-Member-ID"},
+	ExposedHeaders:   []string{"ETag"},
+	AllowCredentials: true,
+	MaxAge:           10 * time.Minute,
+}
+
+func preflight(handler http.Handler, origin, method, headers string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, "/v1/chats/1", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	if headers != "" {
+		req.Header.Set("Access-Control-Request-Headers", headers)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	handler := newCORSHandler(testCORSOptions)
+
+	rec := preflight(handler, "https://app.example.com", http.MethodDelete, "x-member-id")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, DELETE", rec.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "x-member-id", rec.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+	assert.Contains(t, rec.Header().Values("Vary"), "Origin")
+
+	rec = preflight(handler, "https://pr-42.preview.example.com", http.MethodGet, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://pr-42.preview.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_PreflightRejected(t *testing.T) {
+	handler := newCORSHandler(testCORSOptions)
+
+	tests := []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+	}{
+		{"origin", "https://evil.example.com", http.MethodGet, ""},
+		{"wildcard spans path", "https://x/.preview.example.com", http.MethodGet, ""},
+		{"method not allowed", "https://app.example.com", http.MethodPut, ""},
+		{"header", "https://app.example.com", http.MethodGet, "X-Other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := preflight(handler, tt.origin, tt.method, tt.headers)
+			assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+		})
+	}
+
+	rec := preflight(handler, "https://app.example.com", http.MethodPost, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code, "preflights for unrouted methods reach the router")
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_ActualRequest(t *testing.T) {
+	handler := newCORSHandler(testCORSOptions)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/chats/1", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "ETag", rec.Header().Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/chats/1", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestMatchOrigin(t *testing.T) {
+	patterns := []string{"https://*.example.com", "http://localhost:3000"}
+
+	assert.True(t, matchOrigin(patterns, "https://app.example.com"))
+	assert.True(t, matchOrigin(patterns, "https://a.b.example.com"))
+	assert.True(t, matchOrigin(patterns, "HTTP://LOCALHOST:3000"))
+	assert.False(t, matchOrigin(patterns, "https://example.com"))
+	assert.False(t, matchOrigin(patterns, "https://app.example.com:8443"))
+	assert.False(t, matchOrigin(patterns, "http://app.example.com"))
+	assert.False(t, matchOrigin(patterns, "http://localhost:3001"))
+	assert.True(t, matchOrigin([]string{"*"}, "https://anything.test"))
+}
+
+func TestCORS_SetOptions(t *testing.T) {
+	handler, cors := newReloadableCORSHandler(CORSOptions{})
+
+	rec := preflight(handler, "https://app.example.com", http.MethodGet, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code, "without origins requests pass through")
+
+	cors.SetOptions(testCORSOptions)
+	rec = preflight(handler, "https://app.example.com", http.MethodGet, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+}
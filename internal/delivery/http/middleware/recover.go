@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover turns a handler panic into a logged error with its stack and, unless
+// the response has already started, the 500 response written by respond.
+// http.ErrAbortHandler is re-raised, as it deliberately aborts the response.
+func Recover(log *slog.Logger, respond http.HandlerFunc) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &startedWriter{ResponseWriter: w}
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(recovered)
+				}
+
+				log.Error("handler panic recovered",
+					slog.Any("panic", recovered),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("stack", string(debug.Stack())),
+				)
+				if !rw.started {
+					respond(w, r)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// startedWriter records whether the response has been started.
+type startedWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *startedWriter) WriteHeader(statusCode int) {
+	if statusCode >= http.StatusOK {
+		w.started = true
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *startedWriter) Write(p []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *startedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,36 @@
+// Package handler provides HTTP handlers for API.
+package handler
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/swaggest/swgui/v5emb"
+)
+
+const (
+	// openAPIPath serves the OpenAPI document of an API version.
+	openAPIPath = "/openapi.json"
+	// docsPath serves Swagger UI and its assets.
+	docsPath = "/docs/"
+)
+
+// openAPISpec describes every route of the API. openapi_test.go checks it against routes.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPI serves the OpenAPI document.
+func (h *Handler) OpenAPI() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if _, err := w.Write(openAPISpec); err != nil {
+			h.log.Error("failed to send openapi document", "error", err)
+		}
+	}
+}
+
+// Docs serves Swagger UI rendering the OpenAPI document of the current version.
+func (h *Handler) Docs() http.Handler {
+	return v5emb.New("Chat API", currentVersion+openAPIPath, docsPath)
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type openAPIDocument struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas    map[string]openAPISchema    `json:"schemas"`
+		Parameters map[string]openAPIParameter `json:"parameters"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	Parameters []openAPIParameter `json:"parameters"`
+}
+
+type openAPIParameter struct {
+	Ref    string          `json:"$ref"`
+	Name   string          `json:"name"`
+	In     string          `json:"in"`
+	Schema openAPIProperty `json:"schema"`
+}
+
+type openAPISchema struct {
+	Properties map[string]openAPIProperty `json:"properties"`
+}
+
+type openAPIProperty struct {
+	MinLength *int   `json:"minLength"`
+	MaxLength *int   `json:"maxLength"`
+	Maximum   *int64 `json:"maximum"`
+}
+
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+func loadOpenAPI(t *testing.T) openAPIDocument {
+	t.Helper()
+
+	var doc openAPIDocument
+	require.NoError(t, json.Unmarshal(openAPISpec, &doc))
+	return doc
+}
+
+func TestOpenAPI_MatchesRoutes(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	var routes []string
+	for _, route := range (&Handler{}).v1Routes() {
+		routes = append(routes, route.pattern)
+	}
+
+	var documented []string
+	for path, operations := range doc.Paths {
+		for method, operation := range operations {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+
+			// Every path segment parameter is declared
+			var declared []string
+			for _, param := range operation.Parameters {
+				if param.Ref != "" {
+					param = doc.Components.Parameters[strings.TrimPrefix(param.Ref, "#/components/parameters/")]
+				}
+				if param.In == "path" {
+					declared = append(declared, param.Name)
+				}
+			}
+			for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+				assert.Contains(t, declared, match[1], "%s %s", method, path)
+			}
+		}
+	}
+
+	slices.Sort(routes)
+	slices.Sort(documented)
+	assert.Equal(t, routes, documented, "routes registered in New and openapi.json drifted apart")
+}
+
+func TestOpenAPI_MatchesValidationLimits(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	tests := []struct {
+		name     string
+		property openAPIProperty
+		validate func(value string) error
+	}{
+		{"CreateChatInput.title", doc.Components.Schemas["CreateChatInput"].Properties["title"],
+			func(v string) error { return domain.CreateChatInput{Title: v}.Validate() }},
+		{"CreateMessageInput.text", doc.Components.Schemas["CreateMessageInput"].Properties["text"],
+			func(v string) error { return domain.CreateMessageInput{Text: v}.Validate() }},
+		{"CreateMessageForm.text", doc.Components.Schemas["CreateMessageForm"].Properties["text"],
+			func(v string) error { return domain.CreateMessageInput{Text: v}.Validate() }},
+		{"X-Member-ID", doc.Components.Parameters["MemberID"].Schema, domain.ValidateMemberID},
+		{"CreateWebhookInput.secret", doc.Components.Schemas["CreateWebhookInput"].Properties["secret"], func(v string) error {
+			return domain.CreateWebhookInput{
+				URL:        "https://example.com/hook",
+				Secret:     v,
+				EventTypes: []domain.EventType{domain.EventMessageCreated},
+			}.Validate()
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			property := tt.property
+			require.NotNil(t, property.MinLength)
+			require.NotNil(t, property.MaxLength)
+
+			assert.NoError(t, tt.validate(strings.Repeat("a", *property.MinLength)))
+			assert.NoError(t, tt.validate(strings.Repeat("a", *property.MaxLength)))
+			assert.Error(t, tt.validate(strings.Repeat("a", *property.MinLength-1)))
+			assert.Error(t, tt.validate(strings.Repeat("a", *property.MaxLength+1)))
+		})
+	}
+}
+
+func TestOpenAPI_DocumentsUnreadCountCap(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	for _, schema := range []string{"ChatSummary", "ChatMessageOutput", "ReadState"} {
+		property, ok := doc.Components.Schemas[schema].Properties["unread_count"]
+		require.True(t, ok, schema)
+		require.NotNil(t, property.Maximum, schema)
+		assert.Equal(t, int64(domain.UnreadCountCap), *property.Maximum, schema)
+	}
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	router := http.NewServeMux()
+	New(router, slog.New(slog.DiscardHandler), nil, Options{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, currentVersion+openAPIPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, string(openAPISpec), rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, docsPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), currentVersion+openAPIPath)
+}
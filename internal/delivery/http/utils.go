@@ -3,7 +3,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 // JSONResp sends JSON response with the given status code.
@@ -30,3 +34,60 @@ func clamp(val, minVal, maxVal int) int {
 	}
 	return val
 }
+
+// byteRange is a satisfiable range within a resource.
+type byteRange struct {
+	start  int64
+	length int64
+}
+
+// contentRange formats the Content-Range header value for the range.
+func (b byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", b.start, b.start+b.length-1, size)
+}
+
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// parseRange parses a single "bytes=" range against the resource size.
+// Returns nil range when the header is absent, malformed or asks for several ranges,
+// in which case the full resource should be served.
+func parseRange(header string, size int64) (*byteRange, error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil, nil
+	}
+	from, to, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return nil, nil
+	}
+
+	if from == "" {
+		suffix, err := strconv.ParseInt(to, 10, 64)
+		if err != nil || suffix < 0 {
+			return nil, nil
+		}
+		if suffix == 0 || size == 0 {
+			return nil, errRangeNotSatisfiable
+		}
+		suffix = min(suffix, size)
+		return &byteRange{start: size - suffix, length: suffix}, nil
+	}
+
+	start, err := strconv.ParseInt(from, 10, 64)
+	if err != nil || start < 0 {
+		return nil, nil
+	}
+	end := size - 1
+	if to != "" {
+		end, err = strconv.ParseInt(to, 10, 64)
+		if err != nil || end < start {
+			return nil, nil
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return nil, errRangeNotSatisfiable
+	}
+
+	return &byteRange{start: start, length: end - start + 1}, nil
+}
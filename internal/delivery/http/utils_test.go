@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		size    int64
+		want    *byteRange
+		wantErr bool
+	}{
+		{name: "absent", header: "", size: 10},
+		{name: "unknown unit", header: "items=0-1", size: 10},
+		{name: "multiple ranges", header: "bytes=0-1,3-4", size: 10},
+		{name: "malformed", header: "bytes=abc", size: 10},
+		{name: "reversed", header: "bytes=5-2", size: 10},
+		{name: "closed", header: "bytes=2-5", size: 10, want: &byteRange{start: 2, length: 4}},
+		{name: "open", header: "bytes=7-", size: 10, want: &byteRange{start: 7, length: 3}},
+		{name: "end clamped", header: "bytes=7-100", size: 10, want: &byteRange{start: 7, length: 3}},
+		{name: "suffix", header: "bytes=-4", size: 10, want: &byteRange{start: 6, length: 4}},
+		{name: "suffix clamped", header: "bytes=-40", size: 10, want: &byteRange{start: 0, length: 10}},
+		{name: "start past end", header: "bytes=10-", size: 10, wantErr: true},
+		{name: "zero suffix", header: "bytes=-0", size: 10, wantErr: true},
+		{name: "empty resource", header: "bytes=-1", size: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRange(tt.header, tt.size)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errRangeNotSatisfiable)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
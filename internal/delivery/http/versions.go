@@ -0,0 +1,67 @@
+// Package handler provides HTTP handlers for API.
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// currentVersion prefixes the routes that unversioned legacy aliases point to.
+const currentVersion = "/v1"
+
+// apiVersion is a set of routes mounted under a path prefix. Versions share
+// the business layer: a breaking change to a request or response gets a new
+// version with its own handlers, while older versions keep their shape.
+type apiVersion struct {
+	prefix string
+	routes []route
+}
+
+// Deprecation announces the retirement of the unversioned legacy routes.
+// Zero times are not reported.
+type Deprecation struct {
+	// DeprecatedAt is sent in the Deprecation header (RFC 9745).
+	DeprecatedAt time.Time
+	// SunsetAt is sent in the Sunset header (RFC 8594), the date after which
+	// the legacy routes may stop responding.
+	SunsetAt time.Time
+}
+
+// versions lists every mounted API version.
+func (h *Handler) versions() []apiVersion {
+	return []apiVersion{
+		{prefix: currentVersion, routes: h.v1Routes()},
+	}
+}
+
+// mount registers the route under the prefix, keeping its method.
+func mount(router *http.ServeMux, prefix string, route route) {
+	method, path, ok := strings.Cut(route.pattern, " ")
+	if !ok {
+		method, path = "", route.pattern
+	}
+	pattern := prefix + path
+	if method != "" {
+		pattern = method + " " + pattern
+	}
+	router.Handle(pattern, route.handler)
+}
+
+// deprecated marks responses of a legacy alias with deprecation headers
+// and links the same resource under the versioned prefix.
+func deprecated(next http.Handler, successor string, deprecation Deprecation) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		if !deprecation.DeprecatedAt.IsZero() {
+			header.Set("Deprecation", "@"+strconv.FormatInt(deprecation.DeprecatedAt.Unix(), 10))
+		}
+		if !deprecation.SunsetAt.IsZero() {
+			header.Set("Sunset", deprecation.SunsetAt.UTC().Format(http.TimeFormat))
+		}
+		header.Add("Link", "<"+successor+r.URL.EscapedPath()+`>; rel="successor-version"`)
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_LegacyRoutesAreDeprecated(t *testing.T) {
+	deprecation := Deprecation{
+		DeprecatedAt: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		SunsetAt:     time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC),
+	}
+	router := http.NewServeMux()
+	New(router, slog.New(slog.DiscardHandler), &fakeBusiness{}, Options{Deprecation: deprecation})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/chats", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Deprecation"))
+	assert.Empty(t, rec.Header().Get("Sunset"))
+
+	versioned := rec.Body.String()
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/chats?limit=5", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, versioned, rec.Body.String())
+	assert.Equal(t, "@1792368000", rec.Header().Get("Deprecation"))
+	assert.Equal(t, "Mon, 19 Apr 2027 00:00:00 GMT", rec.Header().Get("Sunset"))
+	assert.Equal(t, `</v1/chats>; rel="successor-version"`, rec.Header().Get("Link"))
+}
+
+func TestNew_EveryRouteHasLegacyAlias(t *testing.T) {
+	router := http.NewServeMux()
+	New(router, slog.New(slog.DiscardHandler), &fakeBusiness{}, Options{})
+
+	for _, route := range (&Handler{}).v1Routes() {
+		method, path, _ := strings.Cut(route.pattern, " ")
+
+		_, versioned := router.Handler(httptest.NewRequest(method, currentVersion+path, nil))
+		_, legacy := router.Handler(httptest.NewRequest(method, path, nil))
+		assert.Equal(t, method+" "+currentVersion+path, versioned)
+		assert.Equal(t, route.pattern, legacy)
+	}
+}
@@ -0,0 +1,119 @@
+// Package handler provides HTTP handlers for API.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/pkg/request"
+)
+
+// CreateWebhook handles webhook subscription.
+func (h *Handler) CreateWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := request.DecodeAndValidate[domain.CreateWebhookInput](r)
+		if err != nil {
+			h.respondRequestError(w, r, err) // 400 / 413
+			return
+		}
+		body.Sanitize()
+
+		webhook, err := h.business.CreateWebhook(r.Context(), body)
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusCreated, webhook)
+	}
+}
+
+// ListWebhooks handles listing of webhooks.
+func (h *Handler) ListWebhooks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhooks, err := h.business.ListWebhooks(r.Context())
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, webhooks)
+	}
+}
+
+// GetWebhook handles getting a webhook.
+func (h *Handler) GetWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhookID, ok := h.parseWebhookID(w, r)
+		if !ok {
+			return
+		}
+
+		webhook, err := h.business.GetWebhook(r.Context(), webhookID)
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, webhook)
+	}
+}
+
+// DeleteWebhook handles webhook removal.
+func (h *Handler) DeleteWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhookID, ok := h.parseWebhookID(w, r)
+		if !ok {
+			return
+		}
+
+		if err := h.business.DeleteWebhook(r.Context(), webhookID); err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// EnableWebhook handles re-enabling a disabled webhook.
+func (h *Handler) EnableWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhookID, ok := h.parseWebhookID(w, r)
+		if !ok {
+			return
+		}
+
+		webhook, err := h.business.EnableWebhook(r.Context(), webhookID)
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, webhook)
+	}
+}
+
+// ListWebhookDeliveries handles inspection of the latest webhook deliveries.
+func (h *Handler) ListWebhookDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhookID, ok := h.parseWebhookID(w, r)
+		if !ok {
+			return
+		}
+
+		limit := h.parseLimit(r)
+
+		deliveries, err := h.business.ListWebhookDeliveries(r.Context(), webhookID, limit)
+		if err != nil {
+			h.handleBusinessError(w, r, err)
+			return
+		}
+
+		h.respond(w, r, http.StatusOK, deliveries)
+	}
+}
+
+// parseWebhookID extracts and validates webhook ID from path.
+func (h *Handler) parseWebhookID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	return h.parsePathID(w, r, "id", ErrInvalidWebhookID)
+}
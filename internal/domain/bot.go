@@ -0,0 +1,150 @@
+// Package domain contains business entities and DTOs.
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SystemBotName is the built-in bot authoring replies of built-in commands.
+const SystemBotName = "system"
+
+// commandPrefix starts a slash command message.
+const commandPrefix = "/"
+
+var (
+	botNamePattern     = regexp.MustCompile(`^[a-z][a-z0-9_]{2,31}$`)
+	commandNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+)
+
+// CommandNames is a list of slash command names stored as a JSON array.
+type CommandNames []string
+
+// Value implements driver.Valuer.
+func (c CommandNames) Value() (driver.Value, error) {
+	return jsonValue(c)
+}
+
+// Scan implements sql.Scanner.
+func (c *CommandNames) Scan(src any) error {
+	return jsonScan(src, c)
+}
+
+// Bot is an automated chat participant. Bots post messages with an API token;
+// bots with a callback URL also handle the slash commands they registered.
+type Bot struct {
+	ID             int64        `json:"id"`
+	Name           string       `json:"name"`
+	TokenHash      *string      `json:"-"`
+	CallbackURL    *string      `json:"callback_url,omitempty"`
+	CallbackSecret string       `json:"-"`
+	Commands       CommandNames `json:"commands" gorm:"type:jsonb"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+func NewBot(name, tokenHash string, callbackURL *string, callbackSecret string, commands []string) Bot {
+	return Bot{
+		Name:           name,
+		TokenHash:      &tokenHash,
+		CallbackURL:    callbackURL,
+		CallbackSecret: callbackSecret,
+		Commands:       commands,
+	}
+}
+
+// CreatedBot is a newly created bot together with its credentials,
+// which are shown only once.
+type CreatedBot struct {
+	Bot
+	Token          string `json:"token"`
+	CallbackSecret string `json:"callback_secret,omitempty"`
+}
+
+// Command is a slash command found in a chat message.
+type Command struct {
+	Name      string `json:"name"`
+	Args      string `json:"args"`
+	ChatID    int64  `json:"chat_id"`
+	MessageID int64  `json:"message_id"`
+}
+
+// ParseCommand extracts a slash command such as "/stats" or "/weather Moscow"
+// from the message text. Reports false if the text is not a command.
+func ParseCommand(text string) (Command, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(text), commandPrefix)
+	if !ok {
+		return Command{}, false
+	}
+
+	name, args, _ := strings.Cut(rest, " ")
+	name = strings.ToLower(name)
+	if !commandNamePattern.MatchString(name) {
+		return Command{}, false
+	}
+
+	return Command{
+		Name: name,
+		Args: strings.TrimSpace(args),
+	}, true
+}
+
+// ValidateCommandName checks if slash command name is valid.
+func ValidateCommandName(name string) error {
+	if !commandNamePattern.MatchString(name) {
+		return fmt.Errorf("command %q should be 1 to 32 lowercase letters, digits or underscores", name)
+	}
+	return nil
+}
+
+// ChatStats holds chat counters reported by the /stats command.
+type ChatStats struct {
+	Messages    int64
+	Attachments int64
+	Pins        int64
+	Members     int64
+}
+
+// CreateBotInput represents bot creation request.
+type CreateBotInput struct {
+	Name        string   `json:"name"`
+	CallbackURL *string  `json:"callback_url"`
+	Commands    []string `json:"commands"`
+}
+
+// Validate checks if bot creation input is valid.
+func (i CreateBotInput) Validate() error {
+	if !botNamePattern.MatchString(i.Name) {
+		return errors.New("name should be 3 to 32 lowercase letters, digits or underscores starting with a letter")
+	}
+
+	if i.CallbackURL != nil {
+		if err := validateHTTPURL("callback_url", *i.CallbackURL); err != nil {
+			return err
+		}
+	}
+
+	if len(i.Commands) > 0 && i.CallbackURL == nil {
+		return errors.New("commands require callback_url")
+	}
+	for _, command := range i.Commands {
+		if err := ValidateCommandName(command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sanitize normalizes input data.
+func (i *CreateBotInput) Sanitize() {
+	if i.CallbackURL != nil {
+		callbackURL := strings.TrimSpace(*i.CallbackURL)
+		i.CallbackURL = &callbackURL
+	}
+	slices.Sort(i.Commands)
+	i.Commands = slices.Compact(i.Commands)
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text string
+		want Command
+		ok   bool
+	}{
+		{text: "/help", want: Command{Name: "help"}, ok: true},
+		{text: "  /Weather  Moscow  ", want: Command{Name: "weather", Args: "Moscow"}, ok: true},
+		{text: "/stats@now", ok: false},
+		{text: "/", ok: false},
+		{text: "hello /help", ok: false},
+		{text: "//help", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseCommand(tt.text)
+		assert.Equal(t, tt.ok, ok, tt.text)
+		assert.Equal(t, tt.want, got, tt.text)
+	}
+}
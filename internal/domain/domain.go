@@ -1,14 +1,20 @@
 // Package domain contains business entities and DTOs.
 package domain
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Chat represents a chat entity used both as a DTO for output response
-// and as a business logic model.
+// and as a business logic model. Deleted chats are kept with DeletedAt set
+// until they are purged, and are invisible to regular queries.
 type Chat struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int64          `json:"id"`
+	Title     string         `json:"title"`
+	CreatedAt time.Time      `json:"created_at"`
+	DeletedAt gorm.DeletedAt `json:"-"`
 }
 
 func NewChat(title string) Chat {
@@ -20,15 +26,61 @@ func NewChat(title string) Chat {
 // Message represents a message entity used both as a DTO for output response
 // and as a business logic model.
 type Message struct {
-	ID        int64     `json:"id"`
-	ChatID    int64     `json:"chat_id"`
-	Text      string    `json:"text"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          int64        `json:"id"`
+	ChatID      int64        `json:"chat_id"`
+	BotID       *int64       `json:"bot_id,omitempty"`
+	Text        string       `json:"text"`
+	CreatedAt   time.Time    `json:"created_at"`
+	Attachments []Attachment `json:"attachments,omitempty" gorm:"foreignKey:MessageID"`
 }
 
-func NewMessage(chatID int64, text string) Message {
+func NewMessage(chatID int64, botID *int64, text string, attachments []Attachment) Message {
 	return Message{
-		ChatID: chatID,
-		Text:   text,
+		ChatID:      chatID,
+		BotID:       botID,
+		Text:        text,
+		Attachments: attachments,
+	}
+}
+
+// Attachment represents file metadata attached to a message. The file content
+// itself lives in blob storage under StorageKey.
+type Attachment struct {
+	ID          int64     `json:"id"`
+	MessageID   int64     `json:"message_id"`
+	ChatID      int64     `json:"chat_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256" gorm:"column:sha256"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func NewAttachment(chatID int64, filename, contentType string, size int64, sha256, storageKey string) Attachment {
+	return Attachment{
+		ChatID:      chatID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      sha256,
+		StorageKey:  storageKey,
+	}
+}
+
+// PinnedMessage represents a message pinned to the top of a chat.
+type PinnedMessage struct {
+	ChatID    int64     `json:"chat_id"`
+	MessageID int64     `json:"message_id"`
+	PinnedBy  string    `json:"pinned_by,omitempty"`
+	PinnedAt  time.Time `json:"pinned_at"`
+	Message   Message   `json:"message" gorm:"foreignKey:MessageID"`
+}
+
+func NewPinnedMessage(chatID, messageID int64, pinnedBy string) PinnedMessage {
+	return PinnedMessage{
+		ChatID:    chatID,
+		MessageID: messageID,
+		PinnedBy:  pinnedBy,
 	}
 }
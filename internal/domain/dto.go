@@ -4,6 +4,8 @@ package domain
 import (
 	"errors"
 	"fmt"
+	"io"
+	"path"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -13,8 +15,13 @@ import (
 const (
 	maxTitleLen       = 200
 	maxMessageTextLen = 5000
+	maxFilenameLen    = 255
+	maxMemberIDLen    = 64
 )
 
+// defaultFilename is used when an upload carries no usable file name.
+const defaultFilename = "attachment"
+
 // CreateChatInput represents chat creation request.
 type CreateChatInput struct {
 	Title string `json:"title"`
@@ -68,20 +75,100 @@ func (i *CreateMessageInput) Sanitize() {
 	i.Text = strings.TrimSpace(i.Text)
 }
 
-// ChatMessageOutput represents chat with messages response.
+// AttachmentUpload represents a single file received with a message.
+type AttachmentUpload struct {
+	Filename string
+	Size     int64
+	Body     io.Reader
+}
+
+// Sanitize strips directories from the client-supplied file name and bounds its length.
+func (u *AttachmentUpload) Sanitize() {
+	name := strings.TrimSpace(path.Base(strings.ReplaceAll(u.Filename, "\\", "/")))
+	if name == "." || name == "/" || name == "" {
+		name = defaultFilename
+	}
+	if utf8.RuneCountInString(name) > maxFilenameLen {
+		name = string([]rune(name)[:maxFilenameLen])
+	}
+	u.Filename = name
+}
+
+// ValidateMemberID checks if member identifier is valid.
+func ValidateMemberID(memberID string) error {
+	idLen := utf8.RuneCountInString(memberID)
+	if idLen == 0 || idLen > maxMemberIDLen {
+		return fmt.Errorf("member id should be between 1 and %d characters", maxMemberIDLen)
+	}
+	return nil
+}
+
+// MarkReadInput represents request to advance member's read position.
+// Zero MessageID marks the latest message in the chat as read.
+type MarkReadInput struct {
+	MessageID int64 `json:"message_id"`
+}
+
+// Validate checks if mark read input is valid.
+func (i MarkReadInput) Validate() error {
+	if i.MessageID < 0 {
+		return errors.New("message_id should not be negative")
+	}
+	return nil
+}
+
+// UnreadCountCap bounds unread counters: a member with more unread messages
+// gets exactly this value, so clients should show it as "1000+".
+const UnreadCountCap = 1000
+
+// ReadState represents member's read position within a chat.
+type ReadState struct {
+	ChatID            int64  `json:"chat_id"`
+	MemberID          string `json:"member_id"`
+	LastReadMessageID *int64 `json:"last_read_message_id"`
+	// UnreadCount is at most UnreadCountCap.
+	UnreadCount int64     `json:"unread_count"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ChatMember represents a member who has read the chat at least once.
+type ChatMember struct {
+	ChatID            int64     `json:"chat_id"`
+	MemberID          string    `json:"member_id"`
+	LastReadMessageID *int64    `json:"last_read_message_id"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// ChatSummary represents a chat in listings. UnreadCount is set
+// only when the request identifies a member and is at most UnreadCountCap.
+type ChatSummary struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	CreatedAt   time.Time `json:"created_at"`
+	UnreadCount *int64    `json:"unread_count,omitempty"`
+}
+
+// ChatMessageOutput represents chat with messages response. UnreadCount is set
+// only when the request identifies a member and is at most UnreadCountCap.
 type ChatMessageOutput struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	CreatedAt time.Time `json:"created_at"`
-	Messages  []Message `json:"messages"`
+	ID          int64           `json:"id"`
+	Title       string          `json:"title"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UnreadCount *int64          `json:"unread_count,omitempty"`
+	Pinned      []PinnedMessage `json:"pinned"`
+	Messages    []Message       `json:"messages"`
 }
 
 // NewChatMessageOutput creates a new ChatMessageOutput instance.
-func NewChatMessageOutput(chatID int64, title string, createdAt time.Time, messages []Message) *ChatMessageOutput {
+func NewChatMessageOutput(chatID int64, title string, createdAt time.Time, unreadCount *int64,
+	pinned []PinnedMessage, messages []Message,
+) *ChatMessageOutput {
 	return &ChatMessageOutput{
-		ID:        chatID,
-		Title:     title,
-		CreatedAt: createdAt,
-		Messages:  messages,
+		ID:          chatID,
+		Title:       title,
+		CreatedAt:   createdAt,
+		UnreadCount: unreadCount,
+		Pinned:      pinned,
+		Messages:    messages,
 	}
 }
@@ -0,0 +1,63 @@
+// Package domain contains business entities and DTOs.
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType names a domain event.
+type EventType string
+
+// Domain event types.
+const (
+	EventChatCreated     EventType = "chat.created"
+	EventChatDeleted     EventType = "chat.deleted"
+	EventChatRestored    EventType = "chat.restored"
+	EventMessageCreated  EventType = "message.created"
+	EventMessagePinned   EventType = "message.pinned"
+	EventMessageUnpinned EventType = "message.unpinned"
+)
+
+// Event represents a change that happened to a chat. Payload holds
+// the JSON-encoded entity the event is about. ID is assigned by the outbox
+// and lets consumers drop duplicates of at-least-once delivery.
+type Event struct {
+	ID         int64           `json:"id"`
+	Type       EventType       `json:"type"`
+	ChatID     int64           `json:"chat_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// NewEvent creates an event with the payload encoded as JSON.
+func NewEvent(eventType EventType, chatID int64, payload any) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		Type:       eventType,
+		ChatID:     chatID,
+		OccurredAt: time.Now().UTC(),
+		Payload:    data,
+	}, nil
+}
+
+// OutboxEntry is an event waiting in the transactional outbox.
+type OutboxEntry struct {
+	Event
+	Attempts      int
+	NextAttemptAt time.Time
+}
+
+// OutboxUpdate records the outcome of an attempt to publish an outbox entry.
+type OutboxUpdate struct {
+	ID            int64
+	Published     bool
+	DeadLettered  bool
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+}
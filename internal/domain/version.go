@@ -0,0 +1,106 @@
+// Package domain contains business entities and DTOs.
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ChatVersion captures the state a chat representation depends on,
+// so it can be compared without loading the messages.
+type ChatVersion struct {
+	ChatID        int64
+	CreatedAt     time.Time
+	LastMessageID int64
+	LastMessageAt time.Time
+	PinCount      int64
+	LastPinnedAt  time.Time
+	// MemberID is set when the version is read for a member, whose read
+	// position determines the unread counter of the representation.
+	MemberID          string
+	LastReadMessageID int64
+	ReadAt            time.Time
+}
+
+// memberTagSeparator separates the member part of an entity tag from the chat part.
+const memberTagSeparator = "."
+
+// representationTagSeparator precedes the media type and content coding that
+// transports append to the tag of a representation, e.g. "<chat>-msgpack-gzip".
+const representationTagSeparator = "-"
+
+// ETag returns a strong entity tag of the chat version. Versions read for
+// a member get a tag of the form "<chat>.<member>".
+func (v ChatVersion) ETag() string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d:%d:%d:%d:%d:%d",
+		v.ChatID,
+		v.CreatedAt.UnixMicro(),
+		v.LastMessageID,
+		v.LastMessageAt.UnixMicro(),
+		v.PinCount,
+		v.LastPinnedAt.UnixMicro(),
+	))
+	tag := hex.EncodeToString(sum[:16])
+
+	if v.MemberID != "" {
+		memberSum := sha256.Sum256(fmt.Appendf(nil, "%s:%d:%d",
+			v.MemberID,
+			v.LastReadMessageID,
+			v.ReadAt.UnixMicro(),
+		))
+		tag += memberTagSeparator + hex.EncodeToString(memberSum[:8])
+	}
+	return `"` + tag + `"`
+}
+
+// LastModified returns the time of the latest change captured by the version.
+// Removing a pin is not timestamped, so validators should prefer the ETag.
+func (v ChatVersion) LastModified() time.Time {
+	modified := v.CreatedAt
+	for _, t := range []time.Time{v.LastMessageAt, v.LastPinnedAt, v.ReadAt} {
+		if t.After(modified) {
+			modified = t
+		}
+	}
+	return modified
+}
+
+// MatchETag reports whether an If-Match header value matches the strong entity tag
+// of the chat. Tags read for any member and tags of any representation match the
+// chat version they were read at. Weak tags never match, "*" matches any existing entity.
+func MatchETag(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if i := strings.IndexAny(candidate, memberTagSeparator+representationTagSeparator); i >= 0 {
+			candidate = candidate[:i] + `"`
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchETagWeak reports whether an If-None-Match header value matches the entity tag
+// using weak comparison, so W/ prefixes added by intermediaries are ignored. The tag
+// must be the one of the representation being sent. "*" matches any existing entity.
+func MatchETagWeak(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
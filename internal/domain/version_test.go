@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatVersion_ETag(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	version := ChatVersion{ChatID: 1, CreatedAt: createdAt, LastMessageID: 10, LastMessageAt: createdAt.Add(time.Hour)}
+
+	etag := version.ETag()
+	assert.Equal(t, etag, version.ETag())
+
+	newer := version
+	newer.LastMessageID = 11
+	assert.NotEqual(t, etag, newer.ETag())
+
+	member := version
+	member.MemberID = "alice"
+	memberTag := member.ETag()
+	assert.NotEqual(t, etag, memberTag)
+
+	member.LastReadMessageID = 10
+	assert.NotEqual(t, memberTag, member.ETag(), "read position changes the member tag")
+}
+
+func TestMatchETag(t *testing.T) {
+	version := ChatVersion{ChatID: 1, LastMessageID: 10}
+	etag := version.ETag()
+
+	member := version
+	member.MemberID = "alice"
+
+	stale := version
+	stale.LastMessageID = 9
+	staleMember := stale
+	staleMember.MemberID = "alice"
+
+	assert.True(t, MatchETag(etag, etag))
+	assert.True(t, MatchETag("*", etag))
+	assert.True(t, MatchETag(stale.ETag()+", "+etag, etag))
+	assert.True(t, MatchETag(member.ETag(), etag), "member tags match the chat version")
+	assert.False(t, MatchETag(stale.ETag(), etag))
+	assert.False(t, MatchETag(staleMember.ETag(), etag))
+	assert.False(t, MatchETag("W/"+etag, etag), "weak tags never match")
+	assert.True(t, MatchETag(strings.TrimSuffix(etag, `"`)+`-gzip"`, etag), "tags of any representation match")
+	assert.True(t, MatchETag(strings.TrimSuffix(member.ETag(), `"`)+`-msgpack-br"`, etag))
+	assert.False(t, MatchETag(strings.TrimSuffix(stale.ETag(), `"`)+`-gzip"`, etag))
+}
+
+func TestMatchETagWeak(t *testing.T) {
+	etag := ChatVersion{ChatID: 1, LastMessageID: 10}.ETag()
+
+	assert.True(t, MatchETagWeak(etag, etag))
+	assert.True(t, MatchETagWeak("W/"+etag, etag))
+	assert.True(t, MatchETagWeak(`"other", `+etag, etag))
+	assert.True(t, MatchETagWeak("*", etag))
+	assert.False(t, MatchETagWeak(`"other"`, etag))
+	assert.False(t, MatchETagWeak(strings.TrimSuffix(etag, `"`)+`-msgpack"`, etag), "tags of other representations do not match")
+}
+
+func TestChatVersion_LastModified(t *testing.T) {
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	version := ChatVersion{
+		CreatedAt:     createdAt,
+		LastMessageAt: createdAt.Add(2 * time.Hour),
+		LastPinnedAt:  createdAt.Add(time.Hour),
+		ReadAt:        createdAt,
+	}
+	assert.Equal(t, createdAt.Add(2*time.Hour), version.LastModified())
+
+	version.ReadAt = createdAt.Add(3 * time.Hour)
+	assert.Equal(t, createdAt.Add(3*time.Hour), version.LastModified())
+}
@@ -0,0 +1,188 @@
+// Package domain contains business entities and DTOs.
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Webhook validation limits.
+const (
+	minWebhookSecretLen = 16
+	maxWebhookSecretLen = 256
+	maxWebhookURLLen    = 2048
+)
+
+// WebhookEventTypes lists events that can be delivered to webhooks.
+var WebhookEventTypes = []EventType{
+	EventChatCreated,
+	EventChatDeleted,
+	EventChatRestored,
+	EventMessageCreated,
+	EventMessagePinned,
+	EventMessageUnpinned,
+}
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// EventTypes is a set of event types stored as a JSON array.
+type EventTypes []EventType
+
+// Value implements driver.Valuer.
+func (t EventTypes) Value() (driver.Value, error) {
+	return jsonValue(t)
+}
+
+// Scan implements sql.Scanner.
+func (t *EventTypes) Scan(src any) error {
+	return jsonScan(src, t)
+}
+
+// jsonValue encodes v for a JSONB column.
+func jsonValue(v any) (driver.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// jsonScan decodes a JSONB column into dst.
+func jsonScan(src, dst any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, dst)
+	case string:
+		return json.Unmarshal([]byte(v), dst)
+	default:
+		return fmt.Errorf("unsupported json value %T", src)
+	}
+}
+
+// Webhook is a subscription delivering chat events to an HTTP endpoint.
+// It is disabled automatically after too many consecutive failed attempts.
+type Webhook struct {
+	ID                  int64      `json:"id"`
+	URL                 string     `json:"url"`
+	Secret              string     `json:"-"`
+	EventTypes          EventTypes `json:"event_types" gorm:"type:jsonb"`
+	ChatID              *int64     `json:"chat_id,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+func NewWebhook(url, secret string, eventTypes []EventType, chatID *int64) Webhook {
+	return Webhook{
+		URL:        url,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		ChatID:     chatID,
+	}
+}
+
+// WebhookDelivery is a single event queued for delivery to a webhook.
+type WebhookDelivery struct {
+	ID            int64             `json:"id"`
+	WebhookID     int64             `json:"webhook_id"`
+	EventID       int64             `json:"event_id"`
+	EventType     EventType         `json:"event_type"`
+	Payload       json.RawMessage   `json:"payload"`
+	Status        string            `json:"status"`
+	Attempts      int               `json:"attempts"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	CreatedAt     time.Time         `json:"created_at"`
+	CompletedAt   *time.Time        `json:"completed_at,omitempty"`
+	Webhook       *Webhook          `json:"-"`
+	AttemptLog    []DeliveryAttempt `json:"attempt_log,omitempty" gorm:"foreignKey:DeliveryID"`
+}
+
+// DeliveryAttempt records the outcome of one attempt to deliver a webhook.
+// StatusCode is zero when no response was received.
+type DeliveryAttempt struct {
+	ID          int64     `json:"id"`
+	DeliveryID  int64     `json:"-"`
+	StatusCode  int       `json:"status_code"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms" gorm:"column:duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+func (DeliveryAttempt) TableName() string {
+	return "webhook_delivery_attempts"
+}
+
+// DeliveryResult is what the dispatcher reports after attempting a delivery.
+type DeliveryResult struct {
+	Attempt DeliveryAttempt
+	// Status is the new delivery status; a pending delivery is retried at NextAttemptAt.
+	Status        string
+	NextAttemptAt time.Time
+	// DisableAfter is the number of consecutive failures after which the webhook is disabled.
+	DisableAfter int
+}
+
+// CreateWebhookInput represents webhook subscription request.
+type CreateWebhookInput struct {
+	URL        string      `json:"url"`
+	Secret     string      `json:"secret"`
+	EventTypes []EventType `json:"event_types"`
+	ChatID     *int64      `json:"chat_id"`
+}
+
+// Validate checks if webhook creation input is valid.
+func (i CreateWebhookInput) Validate() error {
+	if err := validateHTTPURL("url", i.URL); err != nil {
+		return err
+	}
+
+	secretLen := utf8.RuneCountInString(i.Secret)
+	if secretLen < minWebhookSecretLen || secretLen > maxWebhookSecretLen {
+		return fmt.Errorf("secret should be between %d and %d characters", minWebhookSecretLen, maxWebhookSecretLen)
+	}
+
+	if len(i.EventTypes) == 0 {
+		return errors.New("event_types should not be empty")
+	}
+	for _, eventType := range i.EventTypes {
+		if !slices.Contains(WebhookEventTypes, eventType) {
+			return fmt.Errorf("unsupported event type %q", eventType)
+		}
+	}
+
+	if i.ChatID != nil && *i.ChatID <= 0 {
+		return errors.New("chat_id should be positive")
+	}
+	return nil
+}
+
+// validateHTTPURL checks that the named field holds an absolute http or https URL.
+func validateHTTPURL(field, raw string) error {
+	if len(raw) > maxWebhookURLLen {
+		return fmt.Errorf("%s should be at most %d characters", field, maxWebhookURLLen)
+	}
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s should be an absolute http or https URL", field)
+	}
+	return nil
+}
+
+// Sanitize normalizes input data.
+func (i *CreateWebhookInput) Sanitize() {
+	i.URL = strings.TrimSpace(i.URL)
+	slices.Sort(i.EventTypes)
+	i.EventTypes = slices.Compact(i.EventTypes)
+}
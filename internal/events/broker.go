@@ -0,0 +1,86 @@
+// Package events provides in-process fan-out of domain events to live subscribers.
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+)
+
+// AllChats subscribes to events of every chat.
+const AllChats int64 = 0
+
+// Broker delivers published events to subscribers without blocking the publisher.
+// A subscriber that does not keep up loses events rather than slowing everyone down.
+type Broker struct {
+	log        *slog.Logger
+	bufferSize int
+
+	mu     sync.RWMutex
+	nextID uint64
+	subs   map[uint64]*subscription
+}
+
+type subscription struct {
+	chatID int64
+	ch     chan domain.Event
+}
+
+// NewBroker creates a broker whose subscriber channels hold up to bufferSize pending events.
+func NewBroker(log *slog.Logger, bufferSize int) *Broker {
+	return &Broker{
+		log:        log,
+		bufferSize: bufferSize,
+		subs:       make(map[uint64]*subscription),
+	}
+}
+
+// Publish fans the event out to subscribers of its chat.
+func (b *Broker) Publish(_ context.Context, event domain.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for id, sub := range b.subs {
+		if sub.chatID != AllChats && sub.chatID != event.ChatID {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			b.log.Warn("dropping event for slow subscriber",
+				slog.Uint64("subscription_id", id),
+				slog.String("event_type", string(event.Type)),
+				slog.Int64("chat_id", event.ChatID),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe returns a channel receiving events of the given chat, or of all chats for AllChats.
+// The returned function cancels the subscription and closes the channel.
+func (b *Broker) Subscribe(chatID int64) (<-chan domain.Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	sub := &subscription{
+		chatID: chatID,
+		ch:     make(chan domain.Event, b.bufferSize),
+	}
+	b.subs[id] = sub
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+}
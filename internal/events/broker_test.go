@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroker_FiltersByChat(t *testing.T) {
+	broker := NewBroker(slog.New(slog.NewTextHandler(io.Discard, nil)), 4)
+
+	chatEvents, cancelChat := broker.Subscribe(1)
+	defer cancelChat()
+	allEvents, cancelAll := broker.Subscribe(AllChats)
+	defer cancelAll()
+
+	require.NoError(t, broker.Publish(context.Background(), domain.Event{Type: domain.EventMessagePinned, ChatID: 2}))
+	require.NoError(t, broker.Publish(context.Background(), domain.Event{Type: domain.EventMessagePinned, ChatID: 1}))
+
+	assert.Equal(t, int64(1), (<-chatEvents).ChatID)
+	assert.Empty(t, chatEvents)
+	assert.Equal(t, int64(2), (<-allEvents).ChatID)
+	assert.Equal(t, int64(1), (<-allEvents).ChatID)
+}
+
+func TestBroker_DropsForSlowSubscriber(t *testing.T) {
+	broker := NewBroker(slog.New(slog.NewTextHandler(io.Discard, nil)), 1)
+
+	events, cancel := broker.Subscribe(AllChats)
+	for range 3 {
+		require.NoError(t, broker.Publish(context.Background(), domain.Event{ChatID: 1}))
+	}
+	assert.Len(t, events, 1)
+
+	cancel()
+	cancel()
+	_, open := <-events
+	assert.True(t, open)
+	_, open = <-events
+	assert.False(t, open)
+}
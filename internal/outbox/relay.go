@@ -0,0 +1,222 @@
+// Package outbox relays domain events recorded in the transactional outbox to publishers.
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+)
+
+// Store defines methods for reading and updating the outbox.
+type Store interface {
+	// RelayPending passes pending entries, in the order they were recorded, to relay and
+	// stores the updates it returns. Returns false if another relay is currently draining the outbox.
+	RelayPending(ctx context.Context, limit int,
+		relay func(ctx context.Context, pending []domain.OutboxEntry) []domain.OutboxUpdate) (bool, error)
+	// PurgeOutbox deletes up to limit entries published or dead-lettered before the given time.
+	PurgeOutbox(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// Publisher delivers events to consumers.
+type Publisher interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
+
+// Publishers publishes every event to each publisher in turn and fails on the first error,
+// so the relay retries the event and the ones that succeeded see it again.
+type Publishers []Publisher
+
+// Publish implements Publisher.
+func (p Publishers) Publish(ctx context.Context, event domain.Event) error {
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Config holds relay tuning parameters.
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// MaxAttempts is the number of failed publishes after which an event is dead-lettered.
+	MaxAttempts int
+	// RetryBackoff is the delay after the first failure, doubled on every next one up to MaxRetryBackoff.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// Retention is how long published and dead-lettered events are kept,
+	// purged every PurgeInterval.
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// Relay publishes outbox events in the order they were recorded, at least once.
+// A failing event holds back the later events of its chat until it is published
+// or dead-lettered; events of other chats go on.
+type Relay struct {
+	log       *slog.Logger
+	cfg       Config
+	store     Store
+	publisher Publisher
+	now       func() time.Time
+}
+
+// NewRelay creates a new relay.
+func NewRelay(log *slog.Logger, store Store, publisher Publisher, cfg Config) *Relay {
+	return &Relay{
+		log:       log,
+		cfg:       cfg,
+		store:     store,
+		publisher: publisher,
+		now:       time.Now,
+	}
+}
+
+// Run relays events every poll interval until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	const op = "outbox.Run"
+	log := r.log.With(slog.String("op", op))
+	log.Info("starting outbox relay", slog.Duration("interval", r.cfg.PollInterval))
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	purgeTicker := time.NewTicker(r.cfg.PurgeInterval)
+	defer purgeTicker.Stop()
+
+	r.purge(ctx, log)
+	for {
+		for {
+			published, err := r.RelayOnce(ctx)
+			if err != nil {
+				log.Error("failed to relay outbox", slog.String("error", err.Error()))
+			}
+			// A full batch means more events may be waiting
+			if err != nil || published < r.cfg.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("outbox relay stopped")
+			return
+		case <-ticker.C:
+		case <-purgeTicker.C:
+			r.purge(ctx, log)
+		}
+	}
+}
+
+func (r *Relay) purge(ctx context.Context, log *slog.Logger) {
+	purged, err := r.PurgeOnce(ctx)
+	if err != nil {
+		log.Error("failed to purge outbox", slog.Int64("purged", purged), slog.String("error", err.Error()))
+		return
+	}
+	if purged > 0 {
+		log.Info("purged outbox", slog.Int64("purged", purged))
+	}
+}
+
+// PurgeOnce deletes the events published or dead-lettered longer than the retention ago,
+// batch by batch, and returns their number.
+func (r *Relay) PurgeOnce(ctx context.Context) (int64, error) {
+	before := r.now().Add(-r.cfg.Retention)
+	var total int64
+	for {
+		purged, err := r.store.PurgeOutbox(ctx, before, r.cfg.BatchSize)
+		total += purged
+		if err != nil {
+			return total, err
+		}
+		if purged < int64(r.cfg.BatchSize) {
+			return total, nil
+		}
+	}
+}
+
+// RelayOnce publishes a single batch of due events and returns the number of published ones.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	var published int
+	_, err := r.store.RelayPending(ctx, r.cfg.BatchSize,
+		func(ctx context.Context, pending []domain.OutboxEntry) []domain.OutboxUpdate {
+			updates := r.relay(ctx, pending)
+			for _, update := range updates {
+				if update.Published {
+					published++
+				}
+			}
+			return updates
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	return published, nil
+}
+
+// relay publishes pending entries in order. An entry that is not due yet or fails
+// holds back the later entries of its chat, so they never overtake it.
+func (r *Relay) relay(ctx context.Context, pending []domain.OutboxEntry) []domain.OutboxUpdate {
+	now := r.now()
+	updates := make([]domain.OutboxUpdate, 0, len(pending))
+	held := map[int64]bool{}
+
+	for _, entry := range pending {
+		if held[entry.ChatID] {
+			continue
+		}
+		if entry.NextAttemptAt.After(now) {
+			held[entry.ChatID] = true
+			continue
+		}
+
+		err := r.publisher.Publish(ctx, entry.Event)
+		if err == nil {
+			updates = append(updates, domain.OutboxUpdate{
+				ID:        entry.ID,
+				Published: true,
+				Attempts:  entry.Attempts + 1,
+			})
+			continue
+		}
+
+		update := domain.OutboxUpdate{
+			ID:        entry.ID,
+			Attempts:  entry.Attempts + 1,
+			LastError: err.Error(),
+		}
+		log := r.log.With(
+			slog.Int64("event_id", entry.ID),
+			slog.String("event_type", string(entry.Type)),
+			slog.Int("attempts", update.Attempts),
+			slog.String("error", err.Error()),
+		)
+
+		if update.Attempts >= r.cfg.MaxAttempts {
+			update.DeadLettered = true
+			updates = append(updates, update)
+			log.Error("dead-lettering outbox event")
+			continue
+		}
+
+		update.NextAttemptAt = now.Add(r.backoff(update.Attempts))
+		updates = append(updates, update)
+		held[entry.ChatID] = true
+		log.Warn("failed to publish outbox event, will retry", slog.Time("next_attempt_at", update.NextAttemptAt))
+	}
+
+	return updates
+}
+
+// backoff returns the delay before the next attempt after the given number of failures.
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.cfg.RetryBackoff
+	for i := 1; i < attempts && delay < r.cfg.MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, r.cfg.MaxRetryBackoff)
+}
@@ -0,0 +1,226 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelay_PublishesInOrder(t *testing.T) {
+	store := newMemoryStore(3)
+	broker := events.NewBroker(discardLogger(), 10)
+	received, cancel := broker.Subscribe(events.AllChats)
+	defer cancel()
+
+	relay := NewRelay(discardLogger(), store, broker, testConfig())
+	published, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, published)
+
+	for _, id := range []int64{1, 2, 3} {
+		assert.Equal(t, id, (<-received).ID)
+	}
+	assert.Empty(t, store.pending())
+}
+
+func TestRelay_RetriesFailedEventBeforeLaterOnes(t *testing.T) {
+	store := newMemoryStore(2)
+	publisher := &flakyPublisher{failures: map[int64]int{1: 1}}
+	relay := NewRelay(discardLogger(), store, publisher, testConfig())
+	now := time.Now()
+	relay.now = func() time.Time { return now }
+
+	published, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, published)
+	require.Len(t, store.pending(), 2)
+	assert.Equal(t, 1, store.entries[0].Attempts)
+	assert.Equal(t, now.Add(time.Second), store.entries[0].NextAttemptAt)
+
+	// Not due yet: nothing is published, the second event must wait as well
+	published, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, published)
+
+	now = now.Add(time.Second)
+	published, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []int64{1, 1, 2}, publisher.attempted)
+}
+
+func TestRelay_DeadLettersAfterMaxAttempts(t *testing.T) {
+	store := newMemoryStore(2)
+	store.entries[0].Attempts = testConfig().MaxAttempts - 1
+	publisher := &flakyPublisher{failures: map[int64]int{1: 1}}
+
+	relay := NewRelay(discardLogger(), store, publisher, testConfig())
+	published, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.True(t, store.deadLettered[1])
+	assert.Empty(t, store.pending())
+}
+
+func TestRelay_FailedEventHoldsBackOnlyItsChat(t *testing.T) {
+	store := newMemoryStore(4)
+	store.entries[1].ChatID = 2
+	store.entries[3].ChatID = 2
+	publisher := &flakyPublisher{failures: map[int64]int{1: 1}}
+
+	relay := NewRelay(discardLogger(), store, publisher, testConfig())
+	published, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []int64{1, 2, 4}, publisher.attempted, "event 3 waits for event 1 of the same chat")
+	assert.Equal(t, []int64{1, 3}, ids(store.pending()))
+}
+
+func TestRelay_PurgesInBatches(t *testing.T) {
+	store := newMemoryStore(25)
+	relay := NewRelay(discardLogger(), store, &flakyPublisher{}, testConfig())
+	now := time.Now()
+	relay.now = func() time.Time { return now }
+
+	for range 3 {
+		_, err := relay.RelayOnce(context.Background())
+		require.NoError(t, err)
+	}
+
+	purged, err := relay.PurgeOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(25), purged)
+	assert.Empty(t, store.entries)
+	assert.Equal(t, []time.Time{now.Add(-time.Hour), now.Add(-time.Hour), now.Add(-time.Hour)}, store.purgedBefore)
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	relay := NewRelay(discardLogger(), nil, nil, testConfig())
+
+	assert.Equal(t, time.Second, relay.backoff(1))
+	assert.Equal(t, 4*time.Second, relay.backoff(3))
+	assert.Equal(t, 10*time.Second, relay.backoff(8))
+}
+
+func testConfig() Config {
+	return Config{
+		PollInterval:    time.Second,
+		BatchSize:       10,
+		MaxAttempts:     5,
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: 10 * time.Second,
+		Retention:       time.Hour,
+		PurgeInterval:   time.Hour,
+	}
+}
+
+func ids(entries []domain.OutboxEntry) []int64 {
+	ids := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// memoryStore keeps the outbox in memory, applying updates the way the database does.
+type memoryStore struct {
+	mu           sync.Mutex
+	entries      []domain.OutboxEntry
+	published    map[int64]bool
+	deadLettered map[int64]bool
+	purgedBefore []time.Time
+}
+
+func newMemoryStore(n int) *memoryStore {
+	store := &memoryStore{
+		published:    map[int64]bool{},
+		deadLettered: map[int64]bool{},
+	}
+	for i := 1; i <= n; i++ {
+		store.entries = append(store.entries, domain.OutboxEntry{
+			Event: domain.Event{ID: int64(i), Type: domain.EventMessageCreated, ChatID: 1},
+		})
+	}
+	return store
+}
+
+func (s *memoryStore) pending() []domain.OutboxEntry {
+	var pending []domain.OutboxEntry
+	for _, entry := range s.entries {
+		if !s.published[entry.ID] && !s.deadLettered[entry.ID] {
+			pending = append(pending, entry)
+		}
+	}
+	return pending
+}
+
+func (s *memoryStore) RelayPending(ctx context.Context, limit int,
+	relay func(ctx context.Context, pending []domain.OutboxEntry) []domain.OutboxUpdate,
+) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := s.pending()
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+	for _, update := range relay(ctx, pending) {
+		for i := range s.entries {
+			if s.entries[i].ID != update.ID {
+				continue
+			}
+			s.entries[i].Attempts = update.Attempts
+			s.entries[i].NextAttemptAt = update.NextAttemptAt
+			s.published[update.ID] = update.Published
+			s.deadLettered[update.ID] = update.DeadLettered
+		}
+	}
+	return true, nil
+}
+
+// PurgeOutbox deletes up to limit published or dead-lettered entries, regardless of before.
+func (s *memoryStore) PurgeOutbox(_ context.Context, before time.Time, limit int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgedBefore = append(s.purgedBefore, before)
+	var purged int64
+	kept := s.entries[:0]
+	for _, entry := range s.entries {
+		if purged < int64(limit) && (s.published[entry.ID] || s.deadLettered[entry.ID]) {
+			purged++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	s.entries = kept
+	return purged, nil
+}
+
+// flakyPublisher fails the given number of attempts of each event.
+type flakyPublisher struct {
+	failures  map[int64]int
+	attempted []int64
+}
+
+func (p *flakyPublisher) Publish(_ context.Context, event domain.Event) error {
+	p.attempted = append(p.attempted, event.ID)
+	if p.failures[event.ID] > 0 {
+		p.failures[event.ID]--
+		return errors.New("consumer unavailable")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Package postgres provides data access layer for chat application.
+package postgres
+
+import (
+	"context"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// Batch lookups below serve several chats in one query, so callers resolving
+// a list of chats do not issue a query per chat.
+
+// GetChatsByIDs retrieves the existing chats among the given IDs in no particular order.
+func (r *PostgresRepository) GetChatsByIDs(ctx context.Context, chatIDs []int64) ([]domain.Chat, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var chats []domain.Chat
+	err := r.client.WithContext(repoCtx).Where("id IN ?", chatIDs).Find(&chats).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return chats, nil
+}
+
+// GetChatMembers retrieves members of the given chats ordered by chat and member ID.
+func (r *PostgresRepository) GetChatMembers(ctx context.Context, chatIDs []int64) ([]domain.ChatMember, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var members []domain.ChatMember
+	err := r.client.WithContext(repoCtx).
+		Table("chat_members").
+		Select("chat_id, member_id, last_read_message_id, updated_at").
+		Where("chat_id IN ?", chatIDs).
+		Order("chat_id, member_id").
+		Scan(&members).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return members, nil
+}
+
+// GetMessagesPage retrieves up to limit newest messages of every given chat, ordered
+// by chat and then by ID (newest first). A non-zero beforeID returns only older messages.
+func (r *PostgresRepository) GetMessagesPage(ctx context.Context, chatIDs []int64, limit int, beforeID int64) ([]domain.Message, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	db := r.client.WithContext(repoCtx)
+	ranked := db.Table("messages").
+		Select("id, ROW_NUMBER() OVER (PARTITION BY chat_id ORDER BY id DESC) AS row_rank").
+		Where("chat_id IN ?", chatIDs)
+	if beforeID > 0 {
+		ranked = ranked.Where("id < ?", beforeID)
+	}
+
+	var messages []domain.Message
+	err := db.
+		Preload("Attachments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Where("id IN (?)", db.Table("(?) AS ranked", ranked).Select("id").Where("row_rank <= ?", limit)).
+		Order("chat_id, id DESC").
+		Find(&messages).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,111 @@
+// Package postgres provides data access layer for chat application.
+package postgres
+
+import (
+	"context"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// chatStatsQuery counts what the /stats command reports about a single chat.
+const chatStatsQuery = `
+SELECT
+	(SELECT COUNT(*) FROM messages WHERE chat_id = @chat_id) AS messages,
+	(SELECT COUNT(*) FROM attachments WHERE chat_id = @chat_id) AS attachments,
+	(SELECT COUNT(*) FROM pinned_messages WHERE chat_id = @chat_id) AS pins,
+	(SELECT COUNT(*) FROM chat_members WHERE chat_id = @chat_id) AS members`
+
+// SaveBot persists new bot and returns it with generated ID & CreatedAt fields.
+// Returns error if the name is taken.
+func (r *PostgresRepository) SaveBot(ctx context.Context, bot domain.Bot) (*domain.Bot, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	err := r.client.WithContext(repoCtx).Create(&bot).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return &bot, nil
+}
+
+// GetBots retrieves bots with API tokens ordered by ID, leaving out the system bot.
+func (r *PostgresRepository) GetBots(ctx context.Context) ([]domain.Bot, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var bots []domain.Bot
+	err := r.client.WithContext(repoCtx).
+		Where("token_hash IS NOT NULL").
+		Order("id").
+		Find(&bots).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return bots, nil
+}
+
+// GetBotByName retrieves bot by its unique name. Returns error if not found.
+func (r *PostgresRepository) GetBotByName(ctx context.Context, name string) (*domain.Bot, error) {
+	return r.findBot(ctx, "name = ?", name)
+}
+
+// GetBotByTokenHash retrieves bot by the SHA-256 of its API token. Returns error if not found.
+func (r *PostgresRepository) GetBotByTokenHash(ctx context.Context, tokenHash string) (*domain.Bot, error) {
+	return r.findBot(ctx, "token_hash = ?", tokenHash)
+}
+
+// GetBotByCommand retrieves the oldest bot handling the slash command. Returns error if not found.
+func (r *PostgresRepository) GetBotByCommand(ctx context.Context, command string) (*domain.Bot, error) {
+	return r.findBot(ctx, "commands @> jsonb_build_array(?::text)", command)
+}
+
+// DeleteBot removes bot by ID. Its messages stay in chats without an author.
+// Returns error if not found; the system bot cannot be deleted.
+func (r *PostgresRepository) DeleteBot(ctx context.Context, botID int64) error {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	result := r.client.WithContext(repoCtx).
+		Where("token_hash IS NOT NULL").
+		Delete(&domain.Bot{}, botID)
+	if result.Error != nil {
+		return r.handleError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return r.handleError(gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// GetChatStats retrieves chat counters.
+func (r *PostgresRepository) GetChatStats(ctx context.Context, chatID int64) (*domain.ChatStats, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var stats domain.ChatStats
+	err := r.client.WithContext(repoCtx).
+		Raw(chatStatsQuery, map[string]any{"chat_id": chatID}).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return &stats, nil
+}
+
+func (r *PostgresRepository) findBot(ctx context.Context, query string, args ...any) (*domain.Bot, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var bot domain.Bot
+	err := r.client.WithContext(repoCtx).Where(query, args...).Order("id").First(&bot).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return &bot, nil
+}
@@ -19,6 +19,14 @@ var (
 	ErrNotFound   = errors.New("not found error")
 	ErrInternal   = errors.New("internal error")
 	ErrUnknown    = errors.New("unknown error")
+
+	// ErrTransaction is a conflict with a concurrent transaction, such as
+	// a serialization failure or deadlock, that persisted after retries.
+	ErrTransaction = errors.New("transaction conflict error")
+
+	// Constraint errors
+	ErrLimitExceeded   = errors.New("limit exceeded error")
+	ErrVersionMismatch = errors.New("version mismatch error")
 )
 
 // ErrorFactory maps postgres client errors to repository-level errors.
@@ -36,6 +44,8 @@ func ErrorFactory(err error) error {
 			return ErrDuplicate
 		case errors.Is(err, postgresclient.ErrNotFound):
 			return ErrNotFound
+		case errors.Is(err, postgresclient.ErrTransaction):
+			return ErrTransaction
 		case errors.Is(err, postgresclient.ErrInternal):
 			return ErrInternal
 		}
@@ -0,0 +1,195 @@
+// Package postgres provides data access layer for chat application.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// outboxLockKey identifies the advisory lock held by the relay instance
+// currently draining the outbox. A single holder keeps events in order.
+const outboxLockKey int64 = 0x6f7574626f78 // "outbox"
+
+// outboxRecord is the persistent form of domain.Event in the outbox table.
+type outboxRecord struct {
+	ID             int64
+	EventType      domain.EventType
+	ChatID         int64
+	Payload        json.RawMessage
+	OccurredAt     time.Time
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	PublishedAt    *time.Time
+	DeadLetteredAt *time.Time
+}
+
+func (outboxRecord) TableName() string {
+	return "outbox"
+}
+
+// appendEvent records a domain event in the outbox as part of the transaction
+// making the change it describes, so the event exists if and only if the change does.
+func appendEvent(tx *gorm.DB, eventType domain.EventType, chatID int64, payload any) error {
+	event, err := domain.NewEvent(eventType, chatID, payload)
+	if err != nil {
+		return err
+	}
+
+	record := outboxRecord{
+		EventType:     event.Type,
+		ChatID:        event.ChatID,
+		Payload:       event.Payload,
+		OccurredAt:    event.OccurredAt,
+		NextAttemptAt: event.OccurredAt,
+	}
+	return tx.Omit("PublishedAt", "DeadLetteredAt").Create(&record).Error
+}
+
+// pendingOutboxQuery selects pending entries in the order of the transactions that
+// recorded them. Only entries of transactions older than every running one are
+// selected, so a transaction committing late never adds an entry before the ones
+// already relayed, as ordering by ID alone would. Chats with an entry waiting for
+// a retry are left out, as none of their entries may overtake it.
+const pendingOutboxQuery = `
+SELECT o.* FROM outbox o
+WHERE o.published_at IS NULL AND o.dead_lettered_at IS NULL
+  AND o.txid < pg_snapshot_xmin(pg_current_snapshot())
+  AND NOT EXISTS (
+	SELECT 1 FROM outbox w
+	WHERE w.chat_id = o.chat_id
+	  AND w.published_at IS NULL AND w.dead_lettered_at IS NULL
+	  AND w.next_attempt_at > CURRENT_TIMESTAMP
+  )
+ORDER BY o.txid, o.id
+LIMIT @limit`
+
+// purgeOutboxQuery deletes a batch of entries published or dead-lettered before @before.
+const purgeOutboxQuery = `
+DELETE FROM outbox WHERE id IN (
+	SELECT id FROM outbox
+	WHERE COALESCE(published_at, dead_lettered_at) < @before
+	LIMIT @limit
+)`
+
+// RelayPending passes up to limit pending outbox entries, in the order they were
+// recorded, to relay and stores the updates it returns. A session advisory lock on
+// a dedicated connection lets only one relay work at a time. The entries are read
+// and the updates stored in short statements: relay runs outside of a transaction
+// and with ctx as is, so slow publishing neither holds a transaction open nor runs
+// into the repository timeout. Returns false without calling relay if another relay
+// holds the lock.
+func (r *PostgresRepository) RelayPending(ctx context.Context, limit int,
+	relay func(ctx context.Context, pending []domain.OutboxEntry) []domain.OutboxUpdate,
+) (bool, error) {
+	var acquired bool
+	err := r.client.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		lockCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+		defer cancel()
+
+		err := conn.WithContext(lockCtx).Raw("SELECT pg_try_advisory_lock(?)", outboxLockKey).Scan(&acquired).Error
+		if err != nil || !acquired {
+			return err
+		}
+		defer releaseOutboxLock(ctx, conn)
+
+		var records []outboxRecord
+		err = conn.WithContext(lockCtx).Raw(pendingOutboxQuery, map[string]any{"limit": limit}).Scan(&records).Error
+		if err != nil || len(records) == 0 {
+			return err
+		}
+
+		pending := make([]domain.OutboxEntry, 0, len(records))
+		for _, record := range records {
+			pending = append(pending, record.entry())
+		}
+		updates := relay(ctx, pending)
+
+		// Published events are recorded even when ctx is cancelled meanwhile,
+		// so that they are not published again
+		updateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ctxTimeout)
+		defer cancel()
+		return conn.WithContext(updateCtx).Transaction(func(tx *gorm.DB) error {
+			for _, update := range updates {
+				if err := applyOutboxUpdate(tx, update); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return false, r.handleError(err)
+	}
+
+	return acquired, nil
+}
+
+// releaseOutboxLock releases the advisory lock held by the session of conn. Should that
+// fail, the connection is closed instead of returning to the pool, which releases it as well.
+func releaseOutboxLock(ctx context.Context, conn *gorm.DB) {
+	unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ctxTimeout)
+	defer cancel()
+
+	err := conn.WithContext(unlockCtx).Exec("SELECT pg_advisory_unlock(?)", outboxLockKey).Error
+	if err == nil {
+		return
+	}
+	if sqlConn, ok := conn.Statement.ConnPool.(*sql.Conn); ok {
+		_ = sqlConn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+}
+
+// PurgeOutbox deletes up to limit entries published or dead-lettered before the given time
+// and returns the number of deleted ones.
+func (r *PostgresRepository) PurgeOutbox(ctx context.Context, before time.Time, limit int) (int64, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	result := r.client.WithContext(repoCtx).Exec(purgeOutboxQuery, map[string]any{
+		"before": before,
+		"limit":  limit,
+	})
+	if result.Error != nil {
+		return 0, r.handleError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (record outboxRecord) entry() domain.OutboxEntry {
+	return domain.OutboxEntry{
+		Event: domain.Event{
+			ID:         record.ID,
+			Type:       record.EventType,
+			ChatID:     record.ChatID,
+			OccurredAt: record.OccurredAt,
+			Payload:    record.Payload,
+		},
+		Attempts:      record.Attempts,
+		NextAttemptAt: record.NextAttemptAt,
+	}
+}
+
+func applyOutboxUpdate(tx *gorm.DB, update domain.OutboxUpdate) error {
+	columns := map[string]any{
+		"attempts":   update.Attempts,
+		"last_error": update.LastError,
+	}
+	switch {
+	case update.Published:
+		columns["published_at"] = gorm.Expr("CURRENT_TIMESTAMP")
+	case update.DeadLettered:
+		columns["dead_lettered_at"] = gorm.Expr("CURRENT_TIMESTAMP")
+	default:
+		columns["next_attempt_at"] = update.NextAttemptAt
+	}
+
+	return tx.Model(&outboxRecord{}).Where("id = ?", update.ID).Updates(columns).Error
+}
@@ -0,0 +1,156 @@
+// Package postgres provides data access layer for chat application.
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PinMessage pins a chat message unless the chat already has maxPins pins.
+// Pinning an already pinned message returns the existing pin with created set to false.
+// The chat row is locked so concurrent pins cannot overshoot the limit. When ifMatch
+// is set, the message is pinned only if the chat version matches.
+func (r *PostgresRepository) PinMessage(ctx context.Context, chatID, messageID int64, pinnedBy string, maxPins int, ifMatch string) (*domain.PinnedMessage, bool, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var (
+		pin     domain.PinnedMessage
+		created bool
+	)
+	err := r.client.WithContext(repoCtx).Transaction(func(tx *gorm.DB) error {
+		_, err := lockActiveChat(tx, chatID, lockUpdate)
+		if err != nil {
+			return err
+		}
+
+		if err := checkChatVersion(tx, chatID, ifMatch); err != nil {
+			return err
+		}
+
+		var existing []domain.PinnedMessage
+		err = preloadPinnedMessage(tx).
+			Where("chat_id = ? AND message_id = ?", chatID, messageID).
+			Limit(1).
+			Find(&existing).Error
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			pin = existing[0]
+			return nil
+		}
+
+		var messages int64
+		err = tx.Model(&domain.Message{}).
+			Where("id = ? AND chat_id = ?", messageID, chatID).
+			Count(&messages).Error
+		if err != nil {
+			return err
+		}
+		if messages == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var pins int64
+		err = tx.Model(&domain.PinnedMessage{}).Where("chat_id = ?", chatID).Count(&pins).Error
+		if err != nil {
+			return err
+		}
+		if pins >= int64(maxPins) {
+			return ErrLimitExceeded
+		}
+
+		pin = domain.NewPinnedMessage(chatID, messageID, pinnedBy)
+		if err := tx.Omit(clause.Associations).Create(&pin).Error; err != nil {
+			return err
+		}
+		created = true
+
+		err = preloadPinnedMessage(tx).
+			Where("chat_id = ? AND message_id = ?", chatID, messageID).
+			First(&pin).Error
+		if err != nil {
+			return err
+		}
+		return appendEvent(tx, domain.EventMessagePinned, chatID, pin)
+	})
+	if errors.Is(err, ErrLimitExceeded) || errors.Is(err, ErrVersionMismatch) {
+		return nil, false, err
+	}
+	if err != nil {
+		return nil, false, r.handleError(err)
+	}
+
+	return &pin, created, nil
+}
+
+// UnpinMessage removes a pin and returns it. When ifMatch is set, the pin is removed
+// only if the chat version matches. Returns error if the message is not pinned.
+func (r *PostgresRepository) UnpinMessage(ctx context.Context, chatID, messageID int64, ifMatch string) (*domain.PinnedMessage, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var pin domain.PinnedMessage
+	err := r.client.WithContext(repoCtx).Transaction(func(tx *gorm.DB) error {
+		if ifMatch != "" {
+			// The chat lock keeps the version stable until the pin is removed
+			if _, err := lockActiveChat(tx, chatID, lockUpdate); err != nil {
+				return err
+			}
+			if err := checkChatVersion(tx, chatID, ifMatch); err != nil {
+				return err
+			}
+		}
+
+		err := preloadPinnedMessage(tx).
+			Clauses(clause.Locking{Strength: lockUpdate}).
+			Where("chat_id = ? AND message_id = ?", chatID, messageID).
+			First(&pin).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("chat_id = ? AND message_id = ?", chatID, messageID).
+			Delete(&domain.PinnedMessage{}).Error
+		if err != nil {
+			return err
+		}
+		return appendEvent(tx, domain.EventMessageUnpinned, chatID, pin)
+	})
+	if errors.Is(err, ErrVersionMismatch) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return &pin, nil
+}
+
+// GetPins retrieves chat pins with their messages, most recently pinned first.
+func (r *PostgresRepository) GetPins(ctx context.Context, chatID int64) ([]domain.PinnedMessage, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var pins []domain.PinnedMessage
+	err := preloadPinnedMessage(r.client.WithContext(repoCtx)).
+		Where("chat_id = ?", chatID).
+		Order("pinned_at DESC, message_id DESC").
+		Find(&pins).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return pins, nil
+}
+
+func preloadPinnedMessage(db *gorm.DB) *gorm.DB {
+	return db.Preload("Message").Preload("Message.Attachments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	})
+}
@@ -0,0 +1,163 @@
+// Package postgres provides data access layer for chat application.
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// unreadCountCap bounds unread counters so a long-abandoned chat never
+// turns into a full scan of its history.
+const unreadCountCap = domain.UnreadCountCap
+
+// unreadSubquery counts messages after member's last read one in (created_at, id)
+// order, so messages sharing its timestamp are told apart by ID. The aggregate
+// yields the start of the history when nothing is read yet. It walks
+// idx_message_chat_created from the read position and stops after @cap rows.
+// The %[1]s verb is replaced with the expression yielding the chat ID.
+const unreadSubquery = `
+SELECT COUNT(*) AS unread_count FROM (
+	SELECT 1 FROM messages m
+	CROSS JOIN (
+		SELECT COALESCE(MAX(lm.created_at), '-infinity') AS created_at, COALESCE(MAX(lm.id), 0) AS id
+		FROM chat_members cm
+		JOIN messages lm ON lm.id = cm.last_read_message_id
+		WHERE cm.chat_id = %[1]s AND cm.member_id = @member_id
+	) lr
+	WHERE m.chat_id = %[1]s
+	  AND (m.created_at, m.id) > (lr.created_at, lr.id)
+	LIMIT @cap
+) capped`
+
+const upsertReadStateQuery = `
+INSERT INTO chat_members (chat_id, member_id, last_read_message_id)
+VALUES (@chat_id, @member_id, @message_id)
+ON CONFLICT (chat_id, member_id) DO UPDATE
+SET last_read_message_id = EXCLUDED.last_read_message_id,
+    updated_at = CURRENT_TIMESTAMP
+WHERE EXCLUDED.last_read_message_id IS NOT NULL
+  AND (chat_members.last_read_message_id IS NULL
+       OR chat_members.last_read_message_id < EXCLUDED.last_read_message_id)`
+
+var (
+	countUnreadQuery = fmt.Sprintf(unreadSubquery, "@chat_id")
+
+	listChatsQuery = `
+SELECT c.id, c.title, c.created_at, u.unread_count
+FROM chats c
+LEFT JOIN LATERAL (` + fmt.Sprintf(unreadSubquery, "c.id") + `) u ON @member_id <> ''
+WHERE c.deleted_at IS NULL
+ORDER BY c.created_at DESC, c.id DESC
+LIMIT @limit`
+)
+
+// MarkRead advances member's read position to the given message and returns the resulting state.
+// Zero messageID marks the latest message as read. The position never moves backwards.
+func (r *PostgresRepository) MarkRead(ctx context.Context, chatID int64, memberID string, messageID int64) (*domain.ReadState, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var state domain.ReadState
+	err := r.client.WithContext(repoCtx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockActiveChat(tx, chatID, lockShare); err != nil {
+			return err
+		}
+
+		target, err := resolveReadTarget(tx, chatID, messageID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(upsertReadStateQuery, map[string]any{
+			"chat_id":    chatID,
+			"member_id":  memberID,
+			"message_id": target,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Raw(`SELECT chat_id, member_id, last_read_message_id, updated_at
+			FROM chat_members WHERE chat_id = ? AND member_id = ?`, chatID, memberID).
+			Scan(&state).Error
+		if err != nil {
+			return err
+		}
+
+		state.UnreadCount, err = countUnread(tx, chatID, memberID)
+		return err
+	})
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return &state, nil
+}
+
+// CountUnread returns the number of messages the member has not read yet, capped at unreadCountCap.
+func (r *PostgresRepository) CountUnread(ctx context.Context, chatID int64, memberID string) (int64, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	count, err := countUnread(r.client.WithContext(repoCtx), chatID, memberID)
+	if err != nil {
+		return 0, r.handleError(err)
+	}
+
+	return count, nil
+}
+
+// ListChats retrieves chats ordered by creation time (newest first).
+// Unread counters are computed only when memberID is set.
+func (r *PostgresRepository) ListChats(ctx context.Context, memberID string, limit int) ([]domain.ChatSummary, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var chats []domain.ChatSummary
+	err := r.client.WithContext(repoCtx).Raw(listChatsQuery, map[string]any{
+		"member_id": memberID,
+		"cap":       unreadCountCap,
+		"limit":     limit,
+	}).Scan(&chats).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return chats, nil
+}
+
+// resolveReadTarget checks that the message belongs to the chat, or picks the latest one
+// when messageID is zero. Returns nil for an empty chat.
+func resolveReadTarget(tx *gorm.DB, chatID, messageID int64) (*int64, error) {
+	var ids []int64
+	query := tx.Table("messages").Where("chat_id = ?", chatID)
+	if messageID == 0 {
+		query = query.Order("created_at DESC, id DESC").Limit(1)
+	} else {
+		query = query.Where("id = ?", messageID)
+	}
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		if messageID != 0 {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, nil
+	}
+	return &ids[0], nil
+}
+
+func countUnread(db *gorm.DB, chatID int64, memberID string) (int64, error) {
+	var count int64
+	err := db.Raw(countUnreadQuery, map[string]any{
+		"chat_id":   chatID,
+		"member_id": memberID,
+		"cap":       unreadCountCap,
+	}).Scan(&count).Error
+	return count, err
+}
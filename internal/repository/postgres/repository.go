@@ -3,11 +3,14 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/Krokozabra213/test_api/internal/domain"
 	postgresclient "github.com/Krokozabra213/test_api/pkg/database/postgres-client"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -16,7 +19,12 @@ const (
 
 // PostgresClient defines database operations interface.
 type PostgresClient interface {
+	// WithContext returns a session of the transaction of ctx, if any, or of the primary.
 	WithContext(ctx context.Context) *gorm.DB
+	// ReadReplica runs the read-only query on a replica, falling back to the primary.
+	ReadReplica(ctx context.Context, query func(db *gorm.DB) error) error
+	// WithTx runs fn in a transaction, retried on conflicts with other transactions.
+	WithTx(ctx context.Context, opts postgresclient.TxOptions, fn func(ctx context.Context) error) error
 }
 
 // PostgresRepository implements chat data storage using PostgreSQL.
@@ -32,12 +40,18 @@ func NewPostgresRepository(client PostgresClient) *PostgresRepository {
 }
 
 // SaveChat persists new chat and returns it with generated ID & CreatedAt field.
+// Every mutation below records its domain event in the outbox within the same transaction.
 func (r *PostgresRepository) SaveChat(ctx context.Context, title string) (*domain.Chat, error) {
 	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
 	defer cancel()
 
 	chat := domain.NewChat(title)
-	err := r.client.WithContext(repoCtx).Create(&chat).Error
+	err := r.client.WithContext(repoCtx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&chat).Error; err != nil {
+			return err
+		}
+		return appendEvent(tx, domain.EventChatCreated, chat.ID, chat)
+	})
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -45,13 +59,16 @@ func (r *PostgresRepository) SaveChat(ctx context.Context, title string) (*domai
 	return &chat, nil
 }
 
-// GetChat retrieves chat by ID. Returns error if not found.
+// GetChat retrieves chat by ID from a read replica.
+// Returns error if not found.
 func (r *PostgresRepository) GetChat(ctx context.Context, chatID int64) (*domain.Chat, error) {
 	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
 	defer cancel()
 
 	var chat domain.Chat
-	err := r.client.WithContext(repoCtx).First(&chat, chatID).Error
+	err := r.client.ReadReplica(repoCtx, func(db *gorm.DB) error {
+		return db.First(&chat, chatID).Error
+	})
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -59,28 +76,80 @@ func (r *PostgresRepository) GetChat(ctx context.Context, chatID int64) (*domain
 	return &chat, nil
 }
 
-// DeleteChat removes a chat by its ID.
-func (r *PostgresRepository) DeleteChat(ctx context.Context, chatID int64) error {
+// DeleteChat soft-deletes a chat by its ID and returns the number of messages deleted with it.
+// The messages stay in place until the chat is purged. When ifMatch is set, the chat is deleted
+// only if its current version matches. Returns error if the chat does not exist.
+func (r *PostgresRepository) DeleteChat(ctx context.Context, chatID int64, ifMatch string) (int64, error) {
 	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
 	defer cancel()
 
-	err := r.client.WithContext(repoCtx).
-		Delete(&domain.Chat{}, chatID).Error
+	var messages int64
+	err := r.client.WithContext(repoCtx).Transaction(func(tx *gorm.DB) error {
+		chat, err := lockActiveChat(tx, chatID, lockUpdate)
+		if err != nil {
+			return err
+		}
+
+		if err := checkChatVersion(tx, chatID, ifMatch); err != nil {
+			return err
+		}
+
+		err = tx.Model(&domain.Message{}).Where("chat_id = ?", chatID).Count(&messages).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Delete(&domain.Chat{}, chatID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return appendEvent(tx, domain.EventChatDeleted, chatID, chat)
+	})
+	if errors.Is(err, ErrVersionMismatch) {
+		return 0, err
+	}
 	if err != nil {
-		return r.handleError(err)
+		return 0, r.handleError(err)
 	}
 
-	return nil
+	return messages, nil
+}
+
+// GetChatVersion retrieves the chat version without loading its messages.
+// A non-empty memberID adds the member's read position to the version.
+// Returns error if the chat does not exist.
+func (r *PostgresRepository) GetChatVersion(ctx context.Context, chatID int64, memberID string) (*domain.ChatVersion, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	version, err := getChatVersion(r.client.WithContext(repoCtx), chatID, memberID)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return version, nil
 }
 
-// SaveMessage persists new message and returns it with generated ID & CreatedAt field.
-// func (r *PostgresRepository) SaveMessage(ctx context.Context, message *domain.Message) (*domain.Message, error) {
-func (r *PostgresRepository) SaveMessage(ctx context.Context, chatID int64, text string) (*domain.Message, error) {
+// SaveMessage persists new message together with its attachments in a single transaction
+// and returns it with generated ID & CreatedAt fields. A non-nil botID marks the message
+// as posted by the bot. Returns error if the chat is deleted.
+func (r *PostgresRepository) SaveMessage(ctx context.Context, chatID int64, botID *int64, text string, attachments []domain.Attachment) (*domain.Message, error) {
 	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
 	defer cancel()
 
-	message := domain.NewMessage(chatID, text)
-	err := r.client.WithContext(repoCtx).Create(&message).Error
+	message := domain.NewMessage(chatID, botID, text, attachments)
+	err := r.client.WithContext(repoCtx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockActiveChat(tx, chatID, lockShare); err != nil {
+			return err
+		}
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+		return appendEvent(tx, domain.EventMessageCreated, chatID, message)
+	})
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -88,17 +157,35 @@ func (r *PostgresRepository) SaveMessage(ctx context.Context, chatID int64, text
 	return &message, nil
 }
 
-// GetMessages retrieves messages for chat, ordered by creation time (newest first).
-func (r *PostgresRepository) GetMessages(ctx context.Context, chatID int64, limit int) ([]domain.Message, error) {
+// GetMessages retrieves messages for chat from a read replica, ordered by creation time
+// (newest first). A non-zero lastMessageID is the newest message known to exist: a replica
+// returning messages without it has not replicated it yet, and the primary is read instead.
+func (r *PostgresRepository) GetMessages(ctx context.Context, chatID int64, limit int, lastMessageID int64) ([]domain.Message, error) {
 	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
 	defer cancel()
 
 	var messages []domain.Message
-	err := r.client.WithContext(repoCtx).
-		Where("chat_id = ?", chatID).
-		Order("created_at DESC").
-		Limit(limit).
-		Find(&messages).Error
+	err := r.client.ReadReplica(repoCtx, func(db *gorm.DB) error {
+		messages = nil
+		err := db.
+			Preload("Attachments", func(db *gorm.DB) *gorm.DB {
+				return db.Order("id")
+			}).
+			Where("chat_id = ?", chatID).
+			Order("created_at DESC").
+			Limit(limit).
+			Find(&messages).Error
+		if err != nil {
+			return err
+		}
+		// Messages newer than the last one may have pushed it out of the limit
+		if lastMessageID != 0 && limit > 0 && !slices.ContainsFunc(messages, func(m domain.Message) bool {
+			return m.ID == lastMessageID
+		}) {
+			return postgresclient.ErrNotReplicated
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, r.handleError(err)
 	}
@@ -106,6 +193,163 @@ func (r *PostgresRepository) GetMessages(ctx context.Context, chatID int64, limi
 	return messages, nil
 }
 
+// GetAttachment retrieves attachment metadata by ID within the given chat.
+func (r *PostgresRepository) GetAttachment(ctx context.Context, chatID, attachmentID int64) (*domain.Attachment, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var attachment domain.Attachment
+	err := r.client.WithContext(repoCtx).
+		Joins("JOIN chats ON chats.id = attachments.chat_id AND chats.deleted_at IS NULL").
+		Where("attachments.chat_id = ?", chatID).
+		First(&attachment, "attachments.id = ?", attachmentID).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return &attachment, nil
+}
+
+// RestoreChat clears the deletion mark of a chat deleted after deletedAfter.
+// Returns error if there is no such deleted chat.
+func (r *PostgresRepository) RestoreChat(ctx context.Context, chatID int64, deletedAfter time.Time) (*domain.Chat, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var chat domain.Chat
+	err := r.client.WithContext(repoCtx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().
+			Model(&chat).
+			Clauses(clause.Returning{}).
+			Where("id = ? AND deleted_at IS NOT NULL AND deleted_at > ?", chatID, deletedAfter).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return appendEvent(tx, domain.EventChatRestored, chatID, chat)
+	})
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return &chat, nil
+}
+
+// PurgeDeletedChats permanently removes up to batchSize chats deleted before deletedBefore,
+// cascading to their messages. Returns the number of purged chats and the storage keys
+// of their attachments, which are left for the caller to remove from blob storage.
+func (r *PostgresRepository) PurgeDeletedChats(ctx context.Context, deletedBefore time.Time, batchSize int) (int64, []string, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var (
+		purged      int64
+		storageKeys []string
+	)
+	err := r.client.WithContext(repoCtx).Transaction(func(tx *gorm.DB) error {
+		var chatIDs []int64
+		err := tx.Unscoped().Model(&domain.Chat{}).
+			Clauses(clause.Locking{Strength: lockUpdate, Options: "SKIP LOCKED"}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Order("deleted_at").
+			Limit(batchSize).
+			Pluck("id", &chatIDs).Error
+		if err != nil || len(chatIDs) == 0 {
+			return err
+		}
+
+		err = tx.Model(&domain.Attachment{}).
+			Where("chat_id IN ?", chatIDs).
+			Pluck("storage_key", &storageKeys).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Delete(&domain.Chat{}, chatIDs)
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, nil, r.handleError(err)
+	}
+
+	return purged, storageKeys, nil
+}
+
+// chatVersionQuery reads the latest message through idx_message_chat_created,
+// aggregates pins of a single chat and reads the member's read position.
+const chatVersionQuery = `
+SELECT c.id AS chat_id, c.created_at,
+       COALESCE(m.id, 0) AS last_message_id,
+       COALESCE(m.created_at, c.created_at) AS last_message_at,
+       p.pin_count,
+       COALESCE(p.last_pinned_at, c.created_at) AS last_pinned_at,
+       COALESCE(cm.last_read_message_id, 0) AS last_read_message_id,
+       COALESCE(cm.updated_at, c.created_at) AS read_at
+FROM chats c
+LEFT JOIN LATERAL (
+	SELECT id, created_at FROM messages
+	WHERE chat_id = c.id
+	ORDER BY created_at DESC, id DESC
+	LIMIT 1
+) m ON true
+LEFT JOIN LATERAL (
+	SELECT COUNT(*) AS pin_count, MAX(pinned_at) AS last_pinned_at
+	FROM pinned_messages
+	WHERE chat_id = c.id
+) p ON true
+LEFT JOIN chat_members cm ON cm.chat_id = c.id AND cm.member_id = ?
+WHERE c.id = ? AND c.deleted_at IS NULL`
+
+func getChatVersion(db *gorm.DB, chatID int64, memberID string) (*domain.ChatVersion, error) {
+	var versions []domain.ChatVersion
+	if err := db.Raw(chatVersionQuery, memberID, chatID).Scan(&versions).Error; err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	version := &versions[0]
+	version.MemberID = memberID
+	return version, nil
+}
+
+// checkChatVersion returns ErrVersionMismatch unless ifMatch is empty or matches
+// the current chat version. The chat row should be locked by the transaction.
+func checkChatVersion(tx *gorm.DB, chatID int64, ifMatch string) error {
+	if ifMatch == "" {
+		return nil
+	}
+	version, err := getChatVersion(tx, chatID, "")
+	if err != nil {
+		return err
+	}
+	if !domain.MatchETag(ifMatch, version.ETag()) {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// Row lock strengths
+const (
+	lockShare  = "SHARE"
+	lockUpdate = "UPDATE"
+)
+
+// lockActiveChat locks a not deleted chat row until the end of the transaction and returns it.
+// Returns gorm.ErrRecordNotFound if the chat does not exist or is deleted.
+func lockActiveChat(tx *gorm.DB, chatID int64, strength string) (*domain.Chat, error) {
+	var chat domain.Chat
+	err := tx.Clauses(clause.Locking{Strength: strength}).First(&chat, chatID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &chat, nil
+}
+
 // handleError wraps database errors into domain-specific errors.
 func (r *PostgresRepository) handleError(err error) error {
 	if err == nil {
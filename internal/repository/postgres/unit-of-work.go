@@ -0,0 +1,44 @@
+// Package postgres provides data access layer for chat application.
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	postgresclient "github.com/Krokozabra213/test_api/pkg/database/postgres-client"
+)
+
+// TxOptions configures the transaction of a unit of work: its isolation level,
+// read-only mode and retries on conflicts with concurrent transactions.
+type TxOptions = postgresclient.TxOptions
+
+// Atomic runs fn as a unit of work: the repository calls made with the context
+// passed to fn share a single transaction, committed when fn returns nil.
+// Their own transactions become savepoints of it.
+//
+// When a call fails with ErrTransaction, the whole unit of work runs again
+// from the start, so fn must not have side effects outside the repository.
+// Errors of fn are returned unchanged; ErrTransaction is returned once the
+// retries run out.
+func (r *PostgresRepository) Atomic(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var fnErr error
+	err := r.client.WithTx(repoCtx, opts, func(txCtx context.Context) error {
+		fnErr = fn(txCtx)
+		if errors.Is(fnErr, ErrTransaction) {
+			// Mark the conflict for the client to retry
+			return postgresclient.NewError(postgresclient.ErrTransaction.Error(), fnErr)
+		}
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return r.handleError(err)
+	}
+
+	return nil
+}
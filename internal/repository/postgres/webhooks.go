@@ -0,0 +1,232 @@
+// Package postgres provides data access layer for chat application.
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// enqueueDeliveriesQuery queues the event for every enabled webhook subscribed to it.
+const enqueueDeliveriesQuery = `
+INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+SELECT w.id, @event_id, @event_type, @payload
+FROM webhooks w
+WHERE w.disabled_at IS NULL
+  AND w.event_types @> jsonb_build_array(@event_type::text)
+  AND (w.chat_id IS NULL OR w.chat_id = @chat_id)
+ON CONFLICT (webhook_id, event_id) DO NOTHING`
+
+// claimDeliveriesQuery leases due deliveries of enabled webhooks by moving
+// their next attempt forward, so concurrent dispatchers do not pick them up.
+const claimDeliveriesQuery = `
+UPDATE webhook_deliveries SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => @lease)
+WHERE id IN (
+	SELECT d.id FROM webhook_deliveries d
+	JOIN webhooks w ON w.id = d.webhook_id AND w.disabled_at IS NULL
+	WHERE d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP
+	ORDER BY d.next_attempt_at, d.id
+	LIMIT @limit
+	FOR UPDATE OF d SKIP LOCKED
+)
+RETURNING id`
+
+// SaveWebhook persists new webhook and returns it with generated ID & CreatedAt fields.
+func (r *PostgresRepository) SaveWebhook(ctx context.Context, webhook domain.Webhook) (*domain.Webhook, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	err := r.client.WithContext(repoCtx).Create(&webhook).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return &webhook, nil
+}
+
+// GetWebhooks retrieves all webhooks ordered by ID.
+func (r *PostgresRepository) GetWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var webhooks []domain.Webhook
+	err := r.client.WithContext(repoCtx).Order("id").Find(&webhooks).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook retrieves webhook by ID. Returns error if not found.
+func (r *PostgresRepository) GetWebhook(ctx context.Context, webhookID int64) (*domain.Webhook, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var webhook domain.Webhook
+	err := r.client.WithContext(repoCtx).First(&webhook, webhookID).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return &webhook, nil
+}
+
+// DeleteWebhook removes webhook together with its deliveries. Returns error if not found.
+func (r *PostgresRepository) DeleteWebhook(ctx context.Context, webhookID int64) error {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	result := r.client.WithContext(repoCtx).Delete(&domain.Webhook{}, webhookID)
+	if result.Error != nil {
+		return r.handleError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return r.handleError(gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// EnableWebhook re-enables webhook and resets its failure counter. Returns error if not found.
+func (r *PostgresRepository) EnableWebhook(ctx context.Context, webhookID int64) (*domain.Webhook, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var webhook domain.Webhook
+	result := r.client.WithContext(repoCtx).
+		Model(&webhook).
+		Clauses(clause.Returning{}).
+		Where("id = ?", webhookID).
+		Updates(map[string]any{
+			"disabled_at":          nil,
+			"consecutive_failures": 0,
+		})
+	if result.Error != nil {
+		return nil, r.handleError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, r.handleError(gorm.ErrRecordNotFound)
+	}
+
+	return &webhook, nil
+}
+
+// GetWebhookDeliveries retrieves the latest deliveries of a webhook with their attempts.
+func (r *PostgresRepository) GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int) ([]domain.WebhookDelivery, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var deliveries []domain.WebhookDelivery
+	err := r.client.WithContext(repoCtx).
+		Preload("AttemptLog", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Where("webhook_id = ?", webhookID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return deliveries, nil
+}
+
+// EnqueueDeliveries queues the event for delivery to matching webhooks.
+// Enqueuing the same event twice is a no-op.
+func (r *PostgresRepository) EnqueueDeliveries(ctx context.Context, event domain.Event) error {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	err = r.client.WithContext(repoCtx).Exec(enqueueDeliveriesQuery, map[string]any{
+		"event_id":   event.ID,
+		"event_type": string(event.Type),
+		"chat_id":    event.ChatID,
+		"payload":    string(payload),
+	}).Error
+	if err != nil {
+		return r.handleError(err)
+	}
+
+	return nil
+}
+
+// ClaimDueDeliveries leases up to limit due deliveries for the lease duration
+// and returns them with their webhooks.
+func (r *PostgresRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	var deliveries []domain.WebhookDelivery
+	err := r.client.WithContext(repoCtx).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		err := tx.Raw(claimDeliveriesQuery, map[string]any{
+			"lease": lease.Seconds(),
+			"limit": limit,
+		}).Scan(&ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		return tx.Preload("Webhook").Order("id").Find(&deliveries, ids).Error
+	})
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return deliveries, nil
+}
+
+// RecordDeliveryAttempt stores the attempt, moves the delivery to its new status
+// and updates the webhook failure counter, disabling the webhook once the counter
+// reaches result.DisableAfter.
+func (r *PostgresRepository) RecordDeliveryAttempt(ctx context.Context, delivery domain.WebhookDelivery, result domain.DeliveryResult) error {
+	repoCtx, cancel := EnsureCtxTimeout(ctx, ctxTimeout)
+	defer cancel()
+
+	err := r.client.WithContext(repoCtx).Transaction(func(tx *gorm.DB) error {
+		attempt := result.Attempt
+		attempt.DeliveryID = delivery.ID
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+
+		columns := map[string]any{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"status":          result.Status,
+			"next_attempt_at": result.NextAttemptAt,
+		}
+		if result.Status != domain.DeliveryPending {
+			columns["completed_at"] = gorm.Expr("CURRENT_TIMESTAMP")
+		}
+		err := tx.Model(&domain.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(columns).Error
+		if err != nil {
+			return err
+		}
+
+		webhook := tx.Model(&domain.Webhook{}).Where("id = ?", delivery.WebhookID)
+		if result.Status == domain.DeliveryDelivered {
+			return webhook.Update("consecutive_failures", 0).Error
+		}
+		return webhook.Updates(map[string]any{
+			"consecutive_failures": gorm.Expr("consecutive_failures + 1"),
+			"disabled_at": gorm.Expr("CASE WHEN consecutive_failures + 1 >= ? THEN CURRENT_TIMESTAMP ELSE disabled_at END",
+				result.DisableAfter),
+		}).Error
+	})
+	if err != nil {
+		return r.handleError(err)
+	}
+
+	return nil
+}
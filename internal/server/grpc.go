@@ -0,0 +1,55 @@
+// Package server provides HTTP server implementation.
+package server
+
+import (
+	"net"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/config"
+	"google.golang.org/grpc"
+)
+
+// GRPCServer wraps gRPC server with graceful shutdown support.
+type GRPCServer struct {
+	grpcServer *grpc.Server
+	addr       string
+}
+
+// NewGRPCServer creates a new gRPC server listening on the configured address.
+func NewGRPCServer(cfg *config.Config, grpcServer *grpc.Server) *GRPCServer {
+	return &GRPCServer{
+		grpcServer: grpcServer,
+		addr:       net.JoinHostPort(cfg.GRPC.Host, cfg.GRPC.Port),
+	}
+}
+
+// Run starts the gRPC server.
+func (s *GRPCServer) Run() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.grpcServer.Serve(listener)
+}
+
+// ShutDown gracefully stops the server, closing remaining streams after the timeout.
+func (s *GRPCServer) ShutDown(timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+	case <-timer.C:
+		s.grpcServer.Stop()
+	}
+}
+
+// Addr returns the server address.
+func (s *GRPCServer) Addr() string {
+	return s.addr
+}
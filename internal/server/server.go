@@ -3,6 +3,8 @@ package server
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"net"
 	"net/http"
 	"time"
@@ -16,21 +18,60 @@ type Server struct {
 }
 
 // NewServer creates a new HTTP server with the given config and handler.
-func NewServer(cfg *config.Config, handler http.Handler) *Server {
+// With TLS enabled it serves HTTPS with HTTP/2, otherwise plain HTTP/1.1
+// and, if configured, h2c.
+func NewServer(cfg *config.Config, handler http.Handler, log *slog.Logger) (*Server, error) {
+	httpServer := &http.Server{
+		Addr:           net.JoinHostPort(cfg.HTTP.Host, cfg.HTTP.Port),
+		Handler:        handler,
+		ReadTimeout:    cfg.HTTP.ReadTimeout,
+		WriteTimeout:   cfg.HTTP.WriteTimeout,
+		MaxHeaderBytes: cfg.HTTP.MaxHeaderMegabytes << 20,
+		Protocols:      new(http.Protocols),
+	}
+	httpServer.Protocols.SetHTTP1(true)
+
+	if cfg.HTTP.TLS.Enabled {
+		tlsConfig, err := newTLSConfig(cfg.HTTP.TLS, log)
+		if err != nil {
+			return nil, fmt.Errorf("server: tls: %w", err)
+		}
+		httpServer.TLSConfig = tlsConfig
+		httpServer.Protocols.SetHTTP2(true)
+	} else if cfg.HTTP.H2C {
+		httpServer.Protocols.SetUnencryptedHTTP2(true)
+	}
+
+	return &Server{httpServer: httpServer}, nil
+}
+
+// NewAdminServer creates the plain HTTP server of the admin listener. It has
+// no write timeout, as CPU profiles and traces stream for as long as requested.
+func NewAdminServer(cfg *config.Config, handler http.Handler) *Server {
 	return &Server{
 		httpServer: &http.Server{
-			Addr:           net.JoinHostPort(cfg.HTTP.Host, cfg.HTTP.Port),
-			Handler:        handler,
-			ReadTimeout:    cfg.HTTP.ReadTimeout,
-			WriteTimeout:   cfg.HTTP.WriteTimeout,
-			MaxHeaderBytes: cfg.HTTP.MaxHeaderMegabytes << 20,
+			Addr:              net.JoinHostPort(cfg.Admin.Host, cfg.Admin.Port),
+			Handler:           handler,
+			ReadHeaderTimeout: cfg.HTTP.ReadTimeout,
 		},
 	}
 }
 
 // Run starts the HTTP server.
 func (s *Server) Run() error {
-	return s.httpServer.ListenAndServe()
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+	return s.serve(listener)
+}
+
+// serve accepts connections on the listener, over TLS when configured.
+func (s *Server) serve(listener net.Listener) error {
+	if s.httpServer.TLSConfig != nil {
+		return s.httpServer.ServeTLS(listener, "", "")
+	}
+	return s.httpServer.Serve(listener)
 }
 
 // ShutDown gracefully stops the server with the given timeout.
@@ -0,0 +1,271 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Krokozabra213/test_api/internal/config"
+)
+
+// testCA issues certificates for the tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue returns PEM encoded certificate and key signed by the CA.
+func (ca *testCA) issue(t *testing.T, serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+// startServer serves a handler reporting the protocol and returns the base URL.
+func startServer(t *testing.T, cfg *config.Config) string {
+	t.Helper()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	})
+	srv, err := NewServer(cfg, handler, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		if err := srv.serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("serve: %v", err)
+		}
+	}()
+	t.Cleanup(func() { _ = srv.ShutDown(time.Second) })
+
+	scheme := "http://"
+	if cfg.HTTP.TLS.Enabled {
+		scheme = "https://"
+	}
+	return scheme + listener.Addr().String()
+}
+
+func get(t *testing.T, client *http.Client, url string) (string, *http.Response, error) {
+	t.Helper()
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body), resp, nil
+}
+
+func tlsTestConfig(t *testing.T, ca *testCA) *config.Config {
+	t.Helper()
+
+	dir := t.TempDir()
+	certPEM, keyPEM := ca.issue(t, 2, x509.ExtKeyUsageServerAuth)
+	writeFile(t, filepath.Join(dir, "cert.pem"), certPEM)
+	writeFile(t, filepath.Join(dir, "key.pem"), keyPEM)
+	writeFile(t, filepath.Join(dir, "ca.pem"), ca.pem)
+
+	return &config.Config{HTTP: config.HTTPConfig{
+		ReadTimeout:  time.Second,
+		WriteTimeout: time.Second,
+		TLS: config.TLSConfig{
+			Enabled:    true,
+			CertFile:   filepath.Join(dir, "cert.pem"),
+			KeyFile:    filepath.Join(dir, "key.pem"),
+			MinVersion: "1.2",
+			ClientAuth: "none",
+		},
+	}}
+}
+
+func tlsClient(ca *testCA, certs ...tls.Certificate) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: pool, Certificates: certs},
+		ForceAttemptHTTP2: true,
+	}}
+}
+
+func TestServer_TLS(t *testing.T) {
+	ca := newTestCA(t)
+	cfg := tlsTestConfig(t, ca)
+	cfg.HTTP.TLS.MinVersion = "1.3"
+	url := startServer(t, cfg)
+
+	proto, resp, err := get(t, tlsClient(ca), url)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", proto)
+	assert.Equal(t, uint16(tls.VersionTLS13), resp.TLS.Version)
+
+	client := tlsClient(ca)
+	client.Transport.(*http.Transport).TLSClientConfig.MaxVersion = tls.VersionTLS12
+	_, _, err = get(t, client, url)
+	assert.Error(t, err, "handshakes below minVersion fail")
+}
+
+func TestNewTLSConfig_DefaultMinVersion(t *testing.T) {
+	cfg := tlsTestConfig(t, newTestCA(t))
+	cfg.HTTP.TLS.MinVersion = ""
+
+	tlsConfig, err := newTLSConfig(cfg.HTTP.TLS, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+}
+
+func TestServer_MutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	cfg := tlsTestConfig(t, ca)
+	cfg.HTTP.TLS.ClientCAFile = filepath.Join(filepath.Dir(cfg.HTTP.TLS.CertFile), "ca.pem")
+	cfg.HTTP.TLS.ClientAuth = "require"
+	url := startServer(t, cfg)
+
+	_, _, err := get(t, tlsClient(ca), url)
+	assert.Error(t, err, "clients without a certificate are rejected")
+
+	certPEM, keyPEM := ca.issue(t, 3, x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	_, resp, err := get(t, tlsClient(ca, clientCert), url)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	other := newTestCA(t)
+	certPEM, keyPEM = other.issue(t, 4, x509.ExtKeyUsageClientAuth)
+	foreignCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	_, _, err = get(t, tlsClient(ca, foreignCert), url)
+	assert.Error(t, err, "certificates of other CAs are rejected")
+}
+
+func TestServer_ReloadsCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	cfg := tlsTestConfig(t, ca)
+	url := startServer(t, cfg)
+
+	serial := func() int64 {
+		// A fresh client per call forces a new handshake
+		_, resp, err := get(t, tlsClient(ca), url)
+		require.NoError(t, err)
+		return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}
+	require.Equal(t, int64(2), serial())
+
+	certPEM, keyPEM := ca.issue(t, 5, x509.ExtKeyUsageServerAuth)
+	writeFile(t, cfg.HTTP.TLS.CertFile, certPEM)
+	writeFile(t, cfg.HTTP.TLS.KeyFile, keyPEM)
+	assert.Equal(t, int64(5), serial())
+
+	// A broken rotation keeps the previous certificate
+	writeFile(t, cfg.HTTP.TLS.KeyFile, []byte("garbage"))
+	assert.Equal(t, int64(5), serial())
+}
+
+func TestServer_H2C(t *testing.T) {
+	cfg := &config.Config{HTTP: config.HTTPConfig{H2C: true}}
+	url := startServer(t, cfg)
+
+	transport := &http.Transport{Protocols: new(http.Protocols)}
+	transport.Protocols.SetUnencryptedHTTP2(true)
+	proto, _, err := get(t, &http.Client{Transport: transport}, url)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", proto)
+
+	proto, _, err = get(t, http.DefaultClient, url)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1", proto)
+}
+
+func TestNewServer_InvalidTLS(t *testing.T) {
+	ca := newTestCA(t)
+	tests := []struct {
+		name   string
+		modify func(*config.TLSConfig)
+	}{
+		{"missing cert", func(c *config.TLSConfig) { c.CertFile = "" }},
+		{"unreadable cert", func(c *config.TLSConfig) { c.CertFile += ".missing" }},
+		{"min version", func(c *config.TLSConfig) { c.MinVersion = "1.0" }},
+		{"insecure cipher", func(c *config.TLSConfig) { c.CipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"} }},
+		{"client auth without CA", func(c *config.TLSConfig) { c.ClientAuth = "require" }},
+		{"client auth mode", func(c *config.TLSConfig) { c.ClientAuth = "always" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tlsTestConfig(t, ca)
+			tt.modify(&cfg.HTTP.TLS)
+			_, err := NewServer(cfg, http.NotFoundHandler(), slog.New(slog.DiscardHandler))
+			assert.Error(t, err)
+		})
+	}
+}
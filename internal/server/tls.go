@@ -0,0 +1,191 @@
+// Package server provides HTTP server implementation.
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/config"
+)
+
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"":         tls.NoClientCert,
+	"none":     tls.NoClientCert,
+	"optional": tls.VerifyClientCertIfGiven,
+	"require":  tls.RequireAndVerifyClientCert,
+}
+
+// newTLSConfig builds the server TLS config. Certificates and client CAs are
+// read from files on handshakes, so rotated files are picked up without restart.
+func newTLSConfig(cfg config.TLSConfig, log *slog.Logger) (*tls.Config, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("certFile and keyFile are required")
+	}
+
+	minVersion, ok := tlsVersions[cfg.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported minVersion %q, want 1.2 or 1.3", cfg.MinVersion)
+	}
+
+	clientAuth, ok := clientAuthTypes[cfg.ClientAuth]
+	if !ok {
+		return nil, fmt.Errorf("unsupported clientAuth %q, want none, optional or require", cfg.ClientAuth)
+	}
+	if clientAuth != tls.NoClientCert && cfg.ClientCAFile == "" {
+		return nil, fmt.Errorf("clientAuth %q requires clientCAFile", cfg.ClientAuth)
+	}
+
+	cipherSuites, err := cipherSuiteIDs(cfg.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	reloader := &certReloader{
+		certFile:     cfg.CertFile,
+		keyFile:      cfg.KeyFile,
+		clientCAFile: cfg.ClientCAFile,
+		interval:     cfg.ReloadInterval,
+		log:          log,
+		base: &tls.Config{
+			MinVersion:   minVersion,
+			CipherSuites: cipherSuites,
+			ClientAuth:   clientAuth,
+			NextProtos:   []string{"h2", "http/1.1"},
+		},
+	}
+	if err := reloader.reload(); err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion:         minVersion,
+		NextProtos:         []string{"h2", "http/1.1"},
+		GetConfigForClient: reloader.configForClient,
+	}, nil
+}
+
+// cipherSuiteIDs resolves cipher suite names. Only suites Go considers
+// secure are accepted.
+func cipherSuiteIDs(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	suites := tls.CipherSuites()
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		i := slices.IndexFunc(suites, func(s *tls.CipherSuite) bool { return s.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unsupported cipher suite %q", name)
+		}
+		ids = append(ids, suites[i].ID)
+	}
+	return ids, nil
+}
+
+// certReloader serves the TLS config with the certificate and client CAs
+// loaded from files, reloading them when the files change.
+type certReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	interval     time.Duration
+	base         *tls.Config
+	log          *slog.Logger
+
+	mu        sync.Mutex
+	config    *tls.Config
+	stamp     string
+	checkedAt time.Time
+}
+
+// configForClient returns the current config, first checking the files
+// for changes when the reload interval has passed. A failed reload keeps
+// serving the previous certificate, since files may be caught mid-rotation.
+func (r *certReloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkedAt) >= r.interval {
+		r.checkedAt = time.Now()
+		if stamp, err := r.fileStamp(); err == nil && stamp != r.stamp {
+			if err := r.load(); err != nil {
+				r.log.Warn("failed to reload tls certificate", "error", err)
+			} else {
+				r.log.Info("tls certificate reloaded", "cert_file", r.certFile)
+			}
+		}
+	}
+	return r.config, nil
+}
+
+// reload loads the files unconditionally.
+func (r *certReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checkedAt = time.Now()
+	return r.load()
+}
+
+// load reads the files into a new config. The caller holds mu.
+func (r *certReloader) load() error {
+	stamp, err := r.fileStamp()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair: %w", err)
+	}
+
+	config := r.base.Clone()
+	config.Certificates = []tls.Certificate{cert}
+
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in client CA file %s", r.clientCAFile)
+		}
+		config.ClientCAs = pool
+	}
+
+	r.config = config
+	r.stamp = stamp
+	return nil
+}
+
+// fileStamp identifies the current contents of the files by size and
+// modification time.
+func (r *certReloader) fileStamp() (string, error) {
+	var stamp string
+	for _, name := range []string{r.certFile, r.keyFile, r.clientCAFile} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return "", err
+		}
+		stamp += fmt.Sprintf("%s:%d:%d;", name, info.Size(), info.ModTime().UnixNano())
+	}
+	return stamp, nil
+}
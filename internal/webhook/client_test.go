@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:4700::1111]:443", true},
+		{"127.0.0.1:8080", false},
+		{"[::1]:80", false},
+		{"10.1.2.3:80", false},
+		{"172.16.0.1:80", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"[fe80::1]:80", false},
+		{"[fd00::1]:80", false},
+		{"0.0.0.0:80", false},
+		{"100.64.0.1:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"[64:ff9b::a00:1]:80", false},
+		{"224.0.0.1:80", false},
+	}
+	for _, tt := range tests {
+		err := checkAddress(tt.address, nil)
+		if tt.allowed {
+			assert.NoError(t, err, tt.address)
+		} else {
+			assert.ErrorIs(t, err, ErrForbiddenAddress, tt.address)
+		}
+	}
+
+	allowed := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	assert.NoError(t, checkAddress("10.1.2.3:80", allowed))
+	assert.ErrorIs(t, checkAddress("192.168.1.1:80", allowed), ErrForbiddenAddress)
+}
+
+func TestHTTPClient_RefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	get := func(client *http.Client) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		return client.Do(req)
+	}
+
+	_, err := get(NewHTTPClient(nil))
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+
+	resp, err := get(loopbackClient())
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
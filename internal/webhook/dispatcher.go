@@ -0,0 +1,244 @@
+// Package webhook delivers chat events to subscribed HTTP endpoints.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+)
+
+// Request headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// signaturePrefix names the algorithm in the signature header value.
+const signaturePrefix = "sha256="
+
+// responseDrainLimit bounds how much of a response body is read before closing it.
+const responseDrainLimit = 64 << 10
+
+// Store defines methods for webhook delivery persistence.
+type Store interface {
+	EnqueueDeliveries(ctx context.Context, event domain.Event) error
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	RecordDeliveryAttempt(ctx context.Context, delivery domain.WebhookDelivery, result domain.DeliveryResult) error
+}
+
+// Config holds dispatcher tuning parameters.
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Timeout bounds a single delivery request.
+	Timeout     time.Duration
+	MaxAttempts int
+	// RetryBackoff is the delay after the first failure, doubled on every next one up to MaxRetryBackoff.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// DisableAfter is the number of consecutive failed attempts after which a webhook is disabled.
+	DisableAfter int
+}
+
+// Dispatcher queues events for matching webhooks and delivers them.
+// It implements the outbox publisher, so events are queued at least once.
+type Dispatcher struct {
+	log        *slog.Logger
+	cfg        Config
+	store      Store
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewDispatcher creates a new dispatcher. A nil httpClient falls back to NewHTTPClient
+// without allowed internal networks.
+func NewDispatcher(log *slog.Logger, store Store, httpClient *http.Client, cfg Config) *Dispatcher {
+	if httpClient == nil {
+		httpClient = NewHTTPClient(nil)
+	}
+
+	return &Dispatcher{
+		log:        log,
+		cfg:        cfg,
+		store:      store,
+		httpClient: httpClient,
+		now:        time.Now,
+	}
+}
+
+// Publish queues the event for delivery to subscribed webhooks.
+func (d *Dispatcher) Publish(ctx context.Context, event domain.Event) error {
+	return d.store.EnqueueDeliveries(ctx, event)
+}
+
+// Run delivers due events every poll interval until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	const op = "webhook.Run"
+	log := d.log.With(slog.String("op", op))
+	log.Info("starting webhook dispatcher", slog.Duration("interval", d.cfg.PollInterval))
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			claimed, err := d.DispatchOnce(ctx)
+			if err != nil {
+				log.Error("failed to dispatch webhooks", slog.String("error", err.Error()))
+			}
+			// A full batch means more deliveries may be due
+			if err != nil || claimed < d.cfg.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("webhook dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce attempts a single batch of due deliveries concurrently
+// and returns the number of attempted ones.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	// The lease outlives the slowest delivery, so nobody else retries it meanwhile
+	deliveries, err := d.store.ClaimDueDeliveries(ctx, d.cfg.BatchSize, 2*d.cfg.Timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := d.deliver(ctx, delivery)
+			// Recording must survive shutdown, or the attempt is lost
+			recordCtx := context.WithoutCancel(ctx)
+			if err := d.store.RecordDeliveryAttempt(recordCtx, delivery, result); err != nil {
+				d.log.Error("failed to record webhook delivery",
+					slog.Int64("delivery_id", delivery.ID),
+					slog.String("error", err.Error()),
+				)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// deliver sends a single delivery and decides what happens to it next.
+func (d *Dispatcher) deliver(ctx context.Context, delivery domain.WebhookDelivery) domain.DeliveryResult {
+	start := d.now()
+	statusCode, err := d.send(ctx, delivery)
+
+	result := domain.DeliveryResult{
+		Attempt: domain.DeliveryAttempt{
+			StatusCode: statusCode,
+			DurationMS: d.now().Sub(start).Milliseconds(),
+		},
+		Status:        domain.DeliveryDelivered,
+		NextAttemptAt: start,
+		DisableAfter:  d.cfg.DisableAfter,
+	}
+	if err == nil {
+		return result
+	}
+
+	attempts := delivery.Attempts + 1
+	result.Attempt.Error = err.Error()
+	log := d.log.With(
+		slog.Int64("webhook_id", delivery.WebhookID),
+		slog.Int64("delivery_id", delivery.ID),
+		slog.Int("attempts", attempts),
+		slog.String("error", err.Error()),
+	)
+
+	if attempts >= d.cfg.MaxAttempts {
+		result.Status = domain.DeliveryFailed
+		log.Error("webhook delivery failed permanently")
+		return result
+	}
+
+	result.Status = domain.DeliveryPending
+	result.NextAttemptAt = start.Add(d.backoff(attempts))
+	log.Warn("webhook delivery failed, will retry", slog.Time("next_attempt_at", result.NextAttemptAt))
+	return result
+}
+
+// send posts the delivery payload and returns the response status code.
+// Any status outside 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, delivery domain.WebhookDelivery) (int, error) {
+	if delivery.Webhook == nil {
+		return 0, fmt.Errorf("webhook %d not loaded", delivery.WebhookID)
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, responseDrainLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt after the given number of failures.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.RetryBackoff
+	for i := 1; i < attempts && delay < d.cfg.MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.MaxRetryBackoff)
+}
+
+// Sign returns the signature header value for a delivery body: HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the webhook secret. Receivers recompute it
+// and should reject stale timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is valid for the body and timestamp.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
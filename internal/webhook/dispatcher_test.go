@@ -0,0 +1,255 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef"
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan received, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{header: r.Header, body: body}
+	}))
+	t.Cleanup(receiver.Close)
+
+	store := newMemoryStore(domain.Webhook{ID: 1, URL: receiver.URL, Secret: testSecret,
+		EventTypes: domain.EventTypes{domain.EventMessageCreated}})
+	dispatcher := NewDispatcher(discardLogger(), store, loopbackClient(), testConfig())
+
+	event, err := domain.NewEvent(domain.EventMessageCreated, 7, map[string]string{"text": "hello"})
+	require.NoError(t, err)
+	event.ID = 42
+	require.NoError(t, dispatcher.Publish(context.Background(), event))
+	// Publishing the same outbox event again must not duplicate the delivery
+	require.NoError(t, dispatcher.Publish(context.Background(), event))
+
+	claimed, err := dispatcher.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+
+	req := <-requests
+	assert.Equal(t, string(domain.EventMessageCreated), req.header.Get(HeaderEvent))
+	timestamp, err := strconv.ParseInt(req.header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, Verify(testSecret, timestamp, req.body, req.header.Get(HeaderSignature)))
+
+	var delivered domain.Event
+	require.NoError(t, json.Unmarshal(req.body, &delivered))
+	assert.Equal(t, int64(42), delivered.ID)
+	assert.Equal(t, int64(7), delivered.ChatID)
+
+	delivery := store.deliveries[0]
+	assert.Equal(t, domain.DeliveryDelivered, delivery.Status)
+	require.Len(t, delivery.AttemptLog, 1)
+	assert.Equal(t, http.StatusOK, delivery.AttemptLog[0].StatusCode)
+}
+
+func TestDispatcher_SkipsUnsubscribedEvents(t *testing.T) {
+	store := newMemoryStore(domain.Webhook{ID: 1, URL: "http://127.0.0.1", Secret: testSecret,
+		EventTypes: domain.EventTypes{domain.EventChatCreated}})
+	dispatcher := NewDispatcher(discardLogger(), store, loopbackClient(), testConfig())
+
+	require.NoError(t, dispatcher.Publish(context.Background(), domain.Event{ID: 1, Type: domain.EventMessageCreated}))
+	assert.Empty(t, store.deliveries)
+}
+
+func TestDispatcher_RetriesAndDisablesFailingWebhook(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(receiver.Close)
+
+	store := newMemoryStore(domain.Webhook{ID: 1, URL: receiver.URL, Secret: testSecret,
+		EventTypes: domain.EventTypes{domain.EventChatCreated}})
+	cfg := testConfig()
+	cfg.DisableAfter = 2
+	dispatcher := NewDispatcher(discardLogger(), store, loopbackClient(), cfg)
+	now := time.Now()
+	dispatcher.now = func() time.Time { return now }
+	store.now = dispatcher.now
+
+	require.NoError(t, dispatcher.Publish(context.Background(), domain.Event{ID: 1, Type: domain.EventChatCreated}))
+
+	_, err := dispatcher.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	delivery := store.deliveries[0]
+	assert.Equal(t, domain.DeliveryPending, delivery.Status)
+	assert.Equal(t, now.Add(time.Second), delivery.NextAttemptAt)
+	assert.Equal(t, http.StatusInternalServerError, delivery.AttemptLog[0].StatusCode)
+
+	// Not due yet
+	claimed, err := dispatcher.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, claimed)
+
+	now = now.Add(time.Second)
+	_, err = dispatcher.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.NotNil(t, store.webhooks[0].DisabledAt)
+	assert.Len(t, store.deliveries[0].AttemptLog, 2)
+
+	// A disabled webhook gets no more attempts
+	now = now.Add(time.Hour)
+	claimed, err = dispatcher.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, claimed)
+}
+
+func TestDispatcher_FailsDeliveryAfterMaxAttempts(t *testing.T) {
+	store := newMemoryStore(domain.Webhook{ID: 1, URL: "http://127.0.0.1:1", Secret: testSecret,
+		EventTypes: domain.EventTypes{domain.EventChatCreated}})
+	dispatcher := NewDispatcher(discardLogger(), store, loopbackClient(), testConfig())
+
+	require.NoError(t, dispatcher.Publish(context.Background(), domain.Event{ID: 1, Type: domain.EventChatCreated}))
+	store.deliveries[0].Attempts = testConfig().MaxAttempts - 1
+
+	_, err := dispatcher.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeliveryFailed, store.deliveries[0].Status)
+	assert.Zero(t, store.deliveries[0].AttemptLog[0].StatusCode)
+	assert.NotEmpty(t, store.deliveries[0].AttemptLog[0].Error)
+}
+
+func TestSign(t *testing.T) {
+	signature := Sign("secret", 1700000000, []byte(`{"id":1}`))
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.True(t, Verify("secret", 1700000000, []byte(`{"id":1}`), signature))
+	assert.False(t, Verify("secret", 1700000001, []byte(`{"id":1}`), signature))
+	assert.False(t, Verify("other", 1700000000, []byte(`{"id":1}`), signature))
+}
+
+func testConfig() Config {
+	return Config{
+		PollInterval:    time.Second,
+		BatchSize:       10,
+		Timeout:         time.Second,
+		MaxAttempts:     5,
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: time.Minute,
+		DisableAfter:    10,
+	}
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// loopbackClient reaches the httptest receivers, which listen on loopback.
+func loopbackClient() *http.Client {
+	return NewHTTPClient([]netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")})
+}
+
+// memoryStore mirrors the repository semantics in memory.
+type memoryStore struct {
+	mu         sync.Mutex
+	now        func() time.Time
+	webhooks   []domain.Webhook
+	deliveries []domain.WebhookDelivery
+}
+
+func newMemoryStore(webhooks ...domain.Webhook) *memoryStore {
+	return &memoryStore{now: time.Now, webhooks: webhooks}
+}
+
+func (s *memoryStore) EnqueueDeliveries(_ context.Context, event domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	for _, webhook := range s.webhooks {
+		if webhook.DisabledAt != nil || !slices.Contains(webhook.EventTypes, event.Type) {
+			continue
+		}
+		duplicate := slices.ContainsFunc(s.deliveries, func(d domain.WebhookDelivery) bool {
+			return d.WebhookID == webhook.ID && d.EventID == event.ID
+		})
+		if duplicate {
+			continue
+		}
+		s.deliveries = append(s.deliveries, domain.WebhookDelivery{
+			ID:            int64(len(s.deliveries) + 1),
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       payload,
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: s.now(),
+		})
+	}
+	return nil
+}
+
+func (s *memoryStore) ClaimDueDeliveries(_ context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var claimed []domain.WebhookDelivery
+	for i := range s.deliveries {
+		delivery := &s.deliveries[i]
+		webhook := s.webhook(delivery.WebhookID)
+		if len(claimed) == limit || delivery.Status != domain.DeliveryPending ||
+			webhook.DisabledAt != nil || delivery.NextAttemptAt.After(s.now()) {
+			continue
+		}
+		delivery.NextAttemptAt = s.now().Add(lease)
+		claimed = append(claimed, *delivery)
+		claimed[len(claimed)-1].Webhook = webhook
+	}
+	return claimed, nil
+}
+
+func (s *memoryStore) RecordDeliveryAttempt(_ context.Context, delivery domain.WebhookDelivery, result domain.DeliveryResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := &s.deliveries[delivery.ID-1]
+	stored.Attempts++
+	stored.Status = result.Status
+	stored.NextAttemptAt = result.NextAttemptAt
+	stored.AttemptLog = append(stored.AttemptLog, result.Attempt)
+
+	webhook := s.webhook(delivery.WebhookID)
+	if result.Status == domain.DeliveryDelivered {
+		webhook.ConsecutiveFailures = 0
+		return nil
+	}
+	webhook.ConsecutiveFailures++
+	if webhook.ConsecutiveFailures >= result.DisableAfter {
+		now := s.now()
+		webhook.DisabledAt = &now
+	}
+	return nil
+}
+
+func (s *memoryStore) webhook(id int64) *domain.Webhook {
+	for i := range s.webhooks {
+		if s.webhooks[i].ID == id {
+			return &s.webhooks[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE attachments (
+    id           BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    message_id   BIGINT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    chat_id      BIGINT NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    filename     VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size         BIGINT NOT NULL CHECK (size >= 0),
+    sha256       CHAR(64) NOT NULL,
+    storage_key  VARCHAR(512) NOT NULL UNIQUE,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Index for preloading attachments of fetched messages
+CREATE INDEX idx_attachment_message ON attachments(message_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE chat_members (
+    chat_id              BIGINT NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    member_id            VARCHAR(64) NOT NULL,
+    last_read_message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+    updated_at           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chat_id, member_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS chat_members;
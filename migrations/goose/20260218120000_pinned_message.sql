@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE pinned_messages (
+    chat_id    BIGINT NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    message_id BIGINT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    pinned_by  VARCHAR(64) NOT NULL DEFAULT '',
+    pinned_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chat_id, message_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS pinned_messages;
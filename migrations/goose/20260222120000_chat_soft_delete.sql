@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE chats ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Partial index for the purger scanning chats past retention
+CREATE INDEX idx_chat_deleted ON chats(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_chat_deleted;
+ALTER TABLE chats DROP COLUMN IF EXISTS deleted_at;
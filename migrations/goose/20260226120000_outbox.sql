@@ -0,0 +1,21 @@
+-- +goose Up
+-- Domain events written in the same transaction as the change they describe.
+-- chat_id has no foreign key: events must outlive purged chats.
+CREATE TABLE outbox (
+    id               BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    event_type       VARCHAR(64) NOT NULL,
+    chat_id          BIGINT NOT NULL,
+    payload          JSONB NOT NULL,
+    occurred_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts         INT NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error       TEXT NOT NULL DEFAULT '',
+    published_at     TIMESTAMP WITH TIME ZONE,
+    dead_lettered_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Partial index for the relay reading pending events in order
+CREATE INDEX idx_outbox_pending ON outbox(id) WHERE published_at IS NULL AND dead_lettered_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,47 @@
+-- +goose Up
+-- Outgoing webhook subscriptions. event_types is a JSON array of event type names;
+-- chat_id restricts the subscription to a single chat.
+CREATE TABLE webhooks (
+    id                   BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    url                  TEXT NOT NULL,
+    secret               VARCHAR(256) NOT NULL,
+    event_types          JSONB NOT NULL,
+    chat_id              BIGINT,
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    disabled_at          TIMESTAMP WITH TIME ZONE,
+    created_at           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One delivery per webhook and outbox event, so a re-published event is not sent twice
+CREATE TABLE webhook_deliveries (
+    id              BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    webhook_id      BIGINT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id        BIGINT NOT NULL,
+    event_type      VARCHAR(64) NOT NULL,
+    payload         JSONB NOT NULL,
+    status          VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts        INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at    TIMESTAMP WITH TIME ZONE,
+    UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX idx_webhook_delivery_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_delivery_webhook ON webhook_deliveries(webhook_id, id DESC);
+
+CREATE TABLE webhook_delivery_attempts (
+    id           BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    delivery_id  BIGINT NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    status_code  INT NOT NULL DEFAULT 0,
+    error        TEXT NOT NULL DEFAULT '',
+    duration_ms  BIGINT NOT NULL DEFAULT 0,
+    attempted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_attempt_delivery ON webhook_delivery_attempts(delivery_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
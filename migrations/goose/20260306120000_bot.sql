@@ -0,0 +1,21 @@
+-- +goose Up
+-- Bot accounts. Only the SHA-256 of the API token is stored. Bots with a callback URL
+-- receive the slash commands listed in commands. The built-in system bot has no token
+-- and authors replies of built-in commands.
+CREATE TABLE bots (
+    id              BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name            VARCHAR(32) NOT NULL UNIQUE,
+    token_hash      CHAR(64) UNIQUE,
+    callback_url    TEXT,
+    callback_secret VARCHAR(64) NOT NULL DEFAULT '',
+    commands        JSONB NOT NULL DEFAULT '[]',
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO bots (name) VALUES ('system');
+
+ALTER TABLE messages ADD COLUMN bot_id BIGINT REFERENCES bots(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE messages DROP COLUMN IF EXISTS bot_id;
+DROP TABLE IF EXISTS bots;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Transaction that recorded the event. The relay reads only events of transactions
+-- older than every running one, in txid order, so that an event committed late never
+-- lands behind the ones already relayed. Existing events get the migration's txid.
+ALTER TABLE outbox ADD COLUMN txid xid8 NOT NULL DEFAULT pg_current_xact_id();
+
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX idx_outbox_pending ON outbox(txid, id) WHERE published_at IS NULL AND dead_lettered_at IS NULL;
+
+-- Index for purging published and dead-lettered events past the retention
+CREATE INDEX idx_outbox_done ON outbox((COALESCE(published_at, dead_lettered_at)));
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_outbox_done;
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX idx_outbox_pending ON outbox(id) WHERE published_at IS NULL AND dead_lettered_at IS NULL;
+ALTER TABLE outbox DROP COLUMN IF EXISTS txid;
@@ -0,0 +1,31 @@
+// Package blobstore provides pluggable binary object storage.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Storage drivers
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+var (
+	ErrNotFound      = errors.New("blob not found")
+	ErrInvalidKey    = errors.New("invalid blob key")
+	ErrUnknownDriver = errors.New("unknown storage driver")
+)
+
+// BlobStore defines operations on binary objects addressed by key.
+type BlobStore interface {
+	// Put stores size bytes read from r under the given key.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns a reader over the object starting at offset.
+	// A negative length reads until the end of the object.
+	Get(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	// Delete removes the object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
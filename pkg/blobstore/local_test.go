@@ -0,0 +1,39 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_PutGetDelete(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "chats/1/abc", strings.NewReader("0123456789"), 10, ""))
+
+	body, err := store.Get(ctx, "chats/1/abc", 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("234"), readAll(t, body))
+
+	require.NoError(t, store.Delete(ctx, "chats/1/abc"))
+	require.NoError(t, store.Delete(ctx, "chats/1/abc"))
+
+	_, err = store.Get(ctx, "chats/1/abc", 0, -1)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestLocalStore_RejectsEscapingKeys(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "/etc/passwd", "../outside", "chats/../../outside"} {
+		err := store.Put(context.Background(), key, strings.NewReader("x"), 1, "")
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
@@ -0,0 +1,50 @@
+package chatclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// CreateBot registers a bot. The returned token and callback secret
+// are shown only once; pass the token to WithBotToken to act as the bot.
+func (c *Client) CreateBot(ctx context.Context, input CreateBotInput) (*CreatedBot, error) {
+	body, err := jsonBody(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var bot CreatedBot
+	_, err = c.call(ctx, request{
+		method:      http.MethodPost,
+		path:        "/bots",
+		body:        body,
+		contentType: "application/json",
+	}, &bot)
+	if err != nil {
+		return nil, err
+	}
+	return &bot, nil
+}
+
+// ListBots returns all bots.
+func (c *Client) ListBots(ctx context.Context) ([]Bot, error) {
+	var bots []Bot
+	_, err := c.call(ctx, request{
+		method: http.MethodGet,
+		path:   "/bots",
+	}, &bots)
+	if err != nil {
+		return nil, err
+	}
+	return bots, nil
+}
+
+// DeleteBot removes the bot; its messages stay in chats.
+func (c *Client) DeleteBot(ctx context.Context, botID int64) error {
+	_, err := c.call(ctx, request{
+		method: http.MethodDelete,
+		path:   "/bots/" + strconv.FormatInt(botID, 10),
+	}, nil)
+	return err
+}
@@ -0,0 +1,326 @@
+package chatclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/Krokozabra213/test_api/internal/domain"
+)
+
+// Page sizes accepted by the server.
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// ListChatsOptions narrows ListChats. MemberID adds unread counters.
+type ListChatsOptions struct {
+	Limit    int
+	MemberID string
+}
+
+// GetChatOptions narrows GetChat. MemberID adds the unread counter.
+// IfNoneMatch takes the ETag of a previous response; GetChat fails with
+// ErrNotModified while it still matches.
+type GetChatOptions struct {
+	Limit       int
+	MemberID    string
+	IfNoneMatch string
+}
+
+// File is an attachment uploaded with a message.
+type File struct {
+	Name string
+	Body io.Reader
+}
+
+// CreateChat creates a chat with the given title.
+func (c *Client) CreateChat(ctx context.Context, title string) (*Chat, error) {
+	body, err := jsonBody(domain.CreateChatInput{Title: title})
+	if err != nil {
+		return nil, err
+	}
+
+	var chat Chat
+	_, err = c.call(ctx, request{
+		method:      http.MethodPost,
+		path:        "/chats",
+		body:        body,
+		contentType: "application/json",
+	}, &chat)
+	if err != nil {
+		return nil, err
+	}
+	return &chat, nil
+}
+
+// ListChats returns the newest chats.
+func (c *Client) ListChats(ctx context.Context, opts ListChatsOptions) ([]ChatSummary, error) {
+	var chats []ChatSummary
+	_, err := c.call(ctx, request{
+		method: http.MethodGet,
+		path:   "/chats",
+		query:  limitQuery(opts.Limit),
+		header: memberHeader(opts.MemberID),
+	}, &chats)
+	if err != nil {
+		return nil, err
+	}
+	return chats, nil
+}
+
+// GetChat returns the chat with pins and the newest messages, along with
+// its ETag that chat mutations accept as a precondition.
+func (c *Client) GetChat(ctx context.Context, chatID int64, opts GetChatOptions) (*ChatMessageOutput, string, error) {
+	header := memberHeader(opts.MemberID)
+	if opts.IfNoneMatch != "" {
+		header.Set("If-None-Match", opts.IfNoneMatch)
+	}
+
+	resp, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   chatPath(chatID),
+		query:  limitQuery(opts.Limit),
+		header: header,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer drain(resp)
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, ErrNotModified
+	}
+
+	var chat ChatMessageOutput
+	if err := json.NewDecoder(resp.Body).Decode(&chat); err != nil {
+		return nil, "", fmt.Errorf("chatclient: decode response: %w", err)
+	}
+	return &chat, etag, nil
+}
+
+// DeleteChat soft-deletes the chat and returns the number of deleted messages.
+// A non-empty ifMatch deletes only if the chat ETag still matches,
+// failing with ErrPreconditionFailed otherwise.
+func (c *Client) DeleteChat(ctx context.Context, chatID int64, ifMatch string) (int64, error) {
+	respHeader, err := c.call(ctx, request{
+		method: http.MethodDelete,
+		path:   chatPath(chatID),
+		header: ifMatchHeader(ifMatch),
+	}, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := strconv.ParseInt(respHeader.Get(deletedMessagesHeader), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("chatclient: invalid %s header: %w", deletedMessagesHeader, err)
+	}
+	return deleted, nil
+}
+
+// RestoreChat restores a deleted chat with its messages.
+func (c *Client) RestoreChat(ctx context.Context, chatID int64) (*Chat, error) {
+	var chat Chat
+	_, err := c.call(ctx, request{
+		method: http.MethodPost,
+		path:   chatPath(chatID) + "/restore",
+	}, &chat)
+	if err != nil {
+		return nil, err
+	}
+	return &chat, nil
+}
+
+// MarkChatRead advances the member's read position to messageID,
+// or to the latest message when messageID is zero.
+func (c *Client) MarkChatRead(ctx context.Context, chatID int64, memberID string, messageID int64) (*ReadState, error) {
+	body, err := jsonBody(domain.MarkReadInput{MessageID: messageID})
+	if err != nil {
+		return nil, err
+	}
+
+	var state ReadState
+	_, err = c.call(ctx, request{
+		method:      http.MethodPost,
+		path:        chatPath(chatID) + "/read",
+		header:      memberHeader(memberID),
+		body:        body,
+		contentType: "application/json",
+	}, &state)
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SendMessage posts a message to the chat. Files are uploaded as attachments
+// in a multipart request and are read only once, so the call is never retried.
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string, files ...File) (*Message, error) {
+	req := request{
+		method: http.MethodPost,
+		path:   chatPath(chatID) + "/messages",
+	}
+	if len(files) == 0 {
+		body, err := jsonBody(domain.CreateMessageInput{Text: text})
+		if err != nil {
+			return nil, err
+		}
+		req.body = body
+		req.contentType = "application/json"
+	} else {
+		pr, pw := io.Pipe()
+		form := multipart.NewWriter(pw)
+		go func() {
+			pw.CloseWithError(writeMessageForm(form, text, files))
+		}()
+		defer pr.Close()
+		req.body = func() io.Reader { return pr }
+		req.contentType = form.FormDataContentType()
+	}
+
+	var message Message
+	if _, err := c.call(ctx, req, &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// writeMessageForm streams the message text and files as a multipart form.
+func writeMessageForm(form *multipart.Writer, text string, files []File) error {
+	if err := form.WriteField("text", text); err != nil {
+		return err
+	}
+	for _, file := range files {
+		part, err := form.CreateFormFile("files", file.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, file.Body); err != nil {
+			return err
+		}
+	}
+	return form.Close()
+}
+
+// ListMessages returns up to limit messages of the chat, newest first.
+// A positive beforeID returns messages older than that message.
+func (c *Client) ListMessages(ctx context.Context, chatID int64, limit int, beforeID int64) ([]Message, error) {
+	query := limitQuery(limit)
+	if beforeID > 0 {
+		query.Set("before", strconv.FormatInt(beforeID, 10))
+	}
+
+	var messages []Message
+	_, err := c.call(ctx, request{
+		method: http.MethodGet,
+		path:   chatPath(chatID) + "/messages",
+		query:  query,
+	}, &messages)
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// Messages iterates over all messages of the chat, newest first, fetching
+// pageSize messages per request, or the server default when it is zero.
+// Iteration stops after the first error.
+func (c *Client) Messages(ctx context.Context, chatID int64, pageSize int) iter.Seq2[Message, error] {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	pageSize = min(pageSize, maxPageSize)
+
+	return func(yield func(Message, error) bool) {
+		var beforeID int64
+		for {
+			page, err := c.ListMessages(ctx, chatID, pageSize, beforeID)
+			if err != nil {
+				yield(Message{}, err)
+				return
+			}
+			for _, message := range page {
+				if !yield(message, nil) {
+					return
+				}
+			}
+			if len(page) < pageSize {
+				return
+			}
+			beforeID = page[len(page)-1].ID
+		}
+	}
+}
+
+// ListPins returns the messages pinned in the chat.
+func (c *Client) ListPins(ctx context.Context, chatID int64) ([]PinnedMessage, error) {
+	var pins []PinnedMessage
+	_, err := c.call(ctx, request{
+		method: http.MethodGet,
+		path:   chatPath(chatID) + "/pins",
+	}, &pins)
+	if err != nil {
+		return nil, err
+	}
+	return pins, nil
+}
+
+// PinMessage pins the message on behalf of memberID, which may be empty.
+// Pinning an already pinned message returns the existing pin. A non-empty
+// ifMatch pins only if the chat ETag still matches.
+func (c *Client) PinMessage(ctx context.Context, chatID, messageID int64, memberID, ifMatch string) (*PinnedMessage, error) {
+	header := ifMatchHeader(ifMatch)
+	if memberID != "" {
+		header.Set(memberIDHeader, memberID)
+	}
+
+	var pin PinnedMessage
+	_, err := c.call(ctx, request{
+		method: http.MethodPut,
+		path:   pinPath(chatID, messageID),
+		header: header,
+	}, &pin)
+	if err != nil {
+		return nil, err
+	}
+	return &pin, nil
+}
+
+// UnpinMessage removes the pin of the message. A non-empty ifMatch
+// unpins only if the chat ETag still matches.
+func (c *Client) UnpinMessage(ctx context.Context, chatID, messageID int64, ifMatch string) error {
+	_, err := c.call(ctx, request{
+		method: http.MethodDelete,
+		path:   pinPath(chatID, messageID),
+		header: ifMatchHeader(ifMatch),
+	}, nil)
+	return err
+}
+
+// OpenAttachment streams the attachment content. The caller closes the reader.
+func (c *Client) OpenAttachment(ctx context.Context, chatID, attachmentID int64) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("%s/attachments/%d", chatPath(chatID), attachmentID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func chatPath(chatID int64) string {
+	return "/chats/" + strconv.FormatInt(chatID, 10)
+}
+
+func pinPath(chatID, messageID int64) string {
+	return fmt.Sprintf("%s/pins/%d", chatPath(chatID), messageID)
+}
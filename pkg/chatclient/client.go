@@ -0,0 +1,287 @@
+// Package chatclient provides a typed Go client for the chat API.
+package chatclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Retry defaults.
+const (
+	defaultMaxAttempts = 3
+	defaultBackoff     = 100 * time.Millisecond
+	defaultMaxBackoff  = 2 * time.Second
+)
+
+// apiVersion prefixes every REST path the client calls.
+const apiVersion = "/v1"
+
+const (
+	// memberIDHeader identifies the chat member on whose behalf the request is made.
+	memberIDHeader = "X-Member-ID"
+	// deletedMessagesHeader reports how many messages were deleted with a chat.
+	deletedMessagesHeader = "X-Deleted-Messages"
+	// authorizationKey carries the bot API token as a bearer credential.
+	authorizationKey = "Authorization"
+	bearerPrefix     = "Bearer "
+)
+
+// Client calls the chat API over HTTP. Streaming subscriptions go over gRPC
+// and need a connection set with WithGRPC. Client is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	grpcConn   grpc.ClientConnInterface
+	botToken   string
+
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+// Option configures the Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithBotToken makes the client act as a bot, so sent messages are posted on its behalf.
+func WithBotToken(token string) Option {
+	return func(c *Client) {
+		c.botToken = token
+	}
+}
+
+// WithRetry configures retries of idempotent requests. The delay before
+// attempt n is backoff*2^(n-1) with jitter, capped at maxBackoff.
+// maxAttempts of 1 disables retries.
+func WithRetry(maxAttempts int, backoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = max(maxAttempts, 1)
+		c.backoff = backoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithGRPC sets the gRPC connection used by Subscribe.
+func WithGRPC(conn grpc.ClientConnInterface) Option {
+	return func(c *Client) {
+		c.grpcConn = conn
+	}
+}
+
+// New creates a client for the API served at baseURL, e.g. "http://localhost:8080".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("chatclient: invalid base url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("chatclient: base url %q should use http or https", baseURL)
+	}
+
+	c := &Client{
+		baseURL:     u,
+		httpClient:  http.DefaultClient,
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBackoff,
+		maxBackoff:  defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// request describes a single API call. body is called once per attempt
+// so retried requests resend the full payload.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        func() io.Reader
+	contentType string
+}
+
+// jsonBody encodes v once and replays it on every attempt.
+func jsonBody(v any) (func() io.Reader, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("chatclient: encode request: %w", err)
+	}
+	return func() io.Reader { return bytes.NewReader(data) }, nil
+}
+
+// idempotent reports whether the request may be safely repeated.
+func (r request) idempotent() bool {
+	switch r.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// do sends the request, retrying idempotent ones on transient failures.
+// Non-2xx responses are returned as *APIError. The caller closes the body.
+func (c *Client) do(ctx context.Context, req request) (*http.Response, error) {
+	attempts := 1
+	if req.idempotent() {
+		attempts = c.maxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, req)
+		if attempt >= attempts || !retryable(resp, err) {
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode >= http.StatusBadRequest {
+				return nil, decodeError(resp)
+			}
+			return resp, nil
+		}
+
+		delay := c.delay(attempt)
+		if resp != nil {
+			delay = max(delay, retryAfter(resp))
+			drain(resp)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// send performs one HTTP round trip.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	u := c.baseURL.JoinPath(apiVersion, req.path)
+	u.RawQuery = req.query.Encode()
+
+	var body io.Reader
+	if req.body != nil {
+		body = req.body()
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("chatclient: build request: %w", err)
+	}
+	for key, values := range req.header {
+		httpReq.Header[key] = values
+	}
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if c.botToken != "" {
+		httpReq.Header.Set(authorizationKey, bearerPrefix+c.botToken)
+	}
+
+	return c.httpClient.Do(httpReq)
+}
+
+// delay returns the jittered exponential backoff before the next attempt.
+func (c *Client) delay(attempt int) time.Duration {
+	if c.backoff <= 0 {
+		return 0
+	}
+	d := c.backoff << (attempt - 1)
+	if d <= 0 || d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	// Full jitter in the upper half keeps retries of many clients apart
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryable reports whether a failed attempt is worth repeating.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header given in seconds.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// drain discards the rest of the body so the connection can be reused.
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+// call sends the request and decodes the JSON response into out, if set.
+func (c *Client) call(ctx context.Context, req request, out any) (http.Header, error) {
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer drain(resp)
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return nil, fmt.Errorf("chatclient: decode response: %w", err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// limitQuery returns the limit query parameter, leaving the server default for zero.
+func limitQuery(limit int) url.Values {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	return query
+}
+
+// memberHeader identifies the member, if any, on whose behalf the request is made.
+func memberHeader(memberID string) http.Header {
+	header := http.Header{}
+	if memberID != "" {
+		header.Set(memberIDHeader, memberID)
+	}
+	return header
+}
+
+// ifMatchHeader makes a mutation conditional on the ETag, if any.
+func ifMatchHeader(etag string) http.Header {
+	header := http.Header{}
+	if etag != "" {
+		header.Set("If-Match", etag)
+	}
+	return header
+}
@@ -0,0 +1,334 @@
+package chatclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	chatv1 "github.com/Krokozabra213/test_api/pkg/api/chat/v1"
+)
+
+func newTestClient(t *testing.T, handler http.Handler, opts ...Option) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	opts = append([]Option{WithRetry(3, time.Millisecond, 5*time.Millisecond)}, opts...)
+	client, err := New(server.URL, opts...)
+	require.NoError(t, err)
+	return client
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestNew_InvalidBaseURL(t *testing.T) {
+	_, err := New("localhost:8080")
+	assert.Error(t, err)
+}
+
+func TestClient_TypedErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		target error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusPreconditionFailed, ErrPreconditionFailed},
+		{http.StatusRequestEntityTooLarge, ErrTooLarge},
+		{http.StatusUnsupportedMediaType, ErrUnsupportedType},
+		{http.StatusInternalServerError, ErrInternal},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, tt.status, map[string]string{"error": "boom"})
+			}))
+
+			_, err := client.CreateChat(context.Background(), "general")
+
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, "boom", apiErr.Message)
+			assert.ErrorIs(t, err, tt.target)
+		})
+	}
+}
+
+func TestClient_ErrorWithoutBody(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	_, err := client.GetWebhook(context.Background(), 1)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "Not Found", apiErr.Message)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestClient_RetriesIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "unavailable"})
+			return
+		}
+		writeJSON(w, http.StatusOK, []ChatSummary{{ID: 1, Title: "general"}})
+	}))
+
+	chats, err := client.ListChats(context.Background(), ListChatsOptions{})
+	require.NoError(t, err)
+	assert.Len(t, chats, 1)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeJSON(w, http.StatusGatewayTimeout, map[string]string{"error": "request timeout"})
+	}))
+
+	err := client.UnpinMessage(context.Background(), 1, 2, "")
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestClient_DoesNotRetryPost(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "unavailable"})
+	}))
+
+	_, err := client.SendMessage(context.Background(), 1, "hello")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_RetryStopsOnContextCancel(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "unavailable"})
+	}), WithRetry(10, time.Hour, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.ListBots(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_GetAndDeleteChat(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/chats/{id}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "5", r.URL.Query().Get("limit"))
+		assert.Equal(t, "alice", r.Header.Get("X-Member-ID"))
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		writeJSON(w, http.StatusCreated, ChatMessageOutput{ID: 1, Title: "general"})
+	})
+	mux.HandleFunc("DELETE /v1/chats/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Match") != `"v1"` {
+			writeJSON(w, http.StatusPreconditionFailed, map[string]string{"error": "precondition failed"})
+			return
+		}
+		w.Header().Set("X-Deleted-Messages", "4")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+
+	chat, etag, err := client.GetChat(ctx, 1, GetChatOptions{Limit: 5, MemberID: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "general", chat.Title)
+	assert.Equal(t, `"v1"`, etag)
+
+	_, _, err = client.GetChat(ctx, 1, GetChatOptions{Limit: 5, MemberID: "alice", IfNoneMatch: etag})
+	assert.ErrorIs(t, err, ErrNotModified)
+
+	_, err = client.DeleteChat(ctx, 1, `"stale"`)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	deleted, err := client.DeleteChat(ctx, 1, etag)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), deleted)
+}
+
+func TestClient_SendMessageWithFiles(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "hello", r.FormValue("text"))
+
+		files := r.MultipartForm.File["files"]
+		require.Len(t, files, 2)
+		attachments := make([]Attachment, 0, len(files))
+		for _, header := range files {
+			attachments = append(attachments, Attachment{Filename: header.Filename, Size: header.Size})
+		}
+		writeJSON(w, http.StatusCreated, Message{ID: 9, ChatID: 1, Text: "hello", Attachments: attachments})
+	}), WithBotToken("secret-token"))
+
+	message, err := client.SendMessage(context.Background(), 1, "hello",
+		File{Name: "a.txt", Body: strings.NewReader("first")},
+		File{Name: "b.txt", Body: strings.NewReader("second file")},
+	)
+	require.NoError(t, err)
+	require.Len(t, message.Attachments, 2)
+	assert.Equal(t, "a.txt", message.Attachments[0].Filename)
+	assert.Equal(t, int64(11), message.Attachments[1].Size)
+}
+
+func TestClient_MessagesIterator(t *testing.T) {
+	const total = 7
+	var requests atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		before := int64(total + 1)
+		if cursor := r.URL.Query().Get("before"); cursor != "" {
+			before, _ = strconv.ParseInt(cursor, 10, 64)
+		}
+
+		page := []Message{}
+		for id := before - 1; id > 0 && len(page) < limit; id-- {
+			page = append(page, Message{ID: id, ChatID: 1})
+		}
+		writeJSON(w, http.StatusOK, page)
+	}))
+
+	var ids []int64
+	for message, err := range client.Messages(context.Background(), 1, 3) {
+		require.NoError(t, err)
+		ids = append(ids, message.ID)
+	}
+	assert.Equal(t, []int64{7, 6, 5, 4, 3, 2, 1}, ids)
+	assert.Equal(t, int32(3), requests.Load())
+
+	ids = ids[:0]
+	for message := range client.Messages(context.Background(), 1, 3) {
+		ids = append(ids, message.ID)
+		if len(ids) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []int64{7, 6}, ids)
+}
+
+func TestClient_OpenAttachment(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chats/1/attachments/2", r.URL.Path)
+		w.Write([]byte("file content"))
+	}))
+
+	body, err := client.OpenAttachment(context.Background(), 1, 2)
+	require.NoError(t, err)
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "file content", string(data))
+}
+
+type fakeChatService struct {
+	chatv1.UnimplementedChatServiceServer
+	events []*chatv1.Event
+}
+
+func (s *fakeChatService) SubscribeChat(req *chatv1.SubscribeChatRequest, stream grpc.ServerStreamingServer[chatv1.Event]) error {
+	if req.GetChatId() != 1 {
+		return status.Error(codes.NotFound, "object not found")
+	}
+	for _, event := range s.events {
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newGRPCConn(t *testing.T, service chatv1.ChatServiceServer) *grpc.ClientConn {
+	t.Helper()
+
+	server := grpc.NewServer()
+	chatv1.RegisterChatServiceServer(server, service)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	occurredAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	conn := newGRPCConn(t, &fakeChatService{events: []*chatv1.Event{
+		{Id: 1, Type: string(EventMessageCreated), ChatId: 1, OccurredAt: timestamppb.New(occurredAt), Payload: []byte(`{"id":3}`)},
+		{Id: 2, Type: string(EventChatDeleted), ChatId: 1, OccurredAt: timestamppb.New(occurredAt), Payload: []byte(`{"id":1}`)},
+	}})
+	client, err := New("http://localhost", WithGRPC(conn))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var received []Event
+	for event, err := range client.Subscribe(ctx, 1) {
+		require.NoError(t, err)
+		received = append(received, event)
+	}
+	require.Len(t, received, 2)
+	assert.Equal(t, EventMessageCreated, received[0].Type)
+	assert.Equal(t, occurredAt, received[0].OccurredAt)
+	assert.JSONEq(t, `{"id":3}`, string(received[0].Payload))
+	assert.Equal(t, EventChatDeleted, received[1].Type)
+
+	for _, err := range client.Subscribe(ctx, 2) {
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+}
+
+func TestClient_SubscribeWithoutGRPC(t *testing.T) {
+	client, err := New("http://localhost")
+	require.NoError(t, err)
+
+	for _, err := range client.Subscribe(context.Background(), 1) {
+		assert.True(t, errors.Is(err, ErrNoStream))
+	}
+}
@@ -0,0 +1,126 @@
+package chatclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Errors matched by APIError via errors.Is.
+var (
+	ErrBadRequest         = errors.New("bad request")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrTooLarge           = errors.New("request too large")
+	ErrUnsupportedType    = errors.New("unsupported media type")
+	ErrTimeout            = errors.New("timeout")
+	ErrUnavailable        = errors.New("service unavailable")
+	ErrInternal           = errors.New("internal server error")
+)
+
+// ErrNotModified is returned by GetChat when the chat still matches IfNoneMatch.
+var ErrNotModified = errors.New("chatclient: not modified")
+
+// ErrNoStream is returned by Subscribe when the client has no gRPC connection.
+var ErrNoStream = errors.New("chatclient: subscriptions require a gRPC connection, see WithGRPC")
+
+// APIError is a non-2xx response of the API. Message is the error text
+// reported by the server.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("chatclient: %d %s", e.StatusCode, e.Message)
+}
+
+// Is matches the sentinel error of the status code class.
+func (e *APIError) Is(target error) bool {
+	return statusError(e.StatusCode) == target
+}
+
+// statusError maps HTTP status codes to sentinel errors.
+func statusError(code int) error {
+	switch code {
+	case http.StatusBadRequest, http.StatusRequestedRangeNotSatisfiable:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusPreconditionFailed:
+		return ErrPreconditionFailed
+	case http.StatusRequestEntityTooLarge:
+		return ErrTooLarge
+	case http.StatusUnsupportedMediaType:
+		return ErrUnsupportedType
+	case http.StatusGatewayTimeout:
+		return ErrTimeout
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return ErrUnavailable
+	}
+	if code >= http.StatusInternalServerError {
+		return ErrInternal
+	}
+	return nil
+}
+
+// decodeError reads the {"error": "..."} body of a failed response.
+// Falls back to the status text for bodies of other shapes.
+func decodeError(resp *http.Response) error {
+	defer drain(resp)
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err := json.Unmarshal(data, &body); err != nil || body.Error == "" {
+		body.Error = http.StatusText(resp.StatusCode)
+	}
+
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    body.Error,
+	}
+}
+
+// grpcError converts a gRPC status to an APIError with the matching HTTP status code.
+func grpcError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	var code int
+	switch st.Code() {
+	case codes.InvalidArgument:
+		code = http.StatusBadRequest
+	case codes.Unauthenticated:
+		code = http.StatusUnauthorized
+	case codes.NotFound:
+		code = http.StatusNotFound
+	case codes.FailedPrecondition:
+		code = http.StatusPreconditionFailed
+	case codes.DeadlineExceeded:
+		code = http.StatusGatewayTimeout
+	case codes.Unavailable:
+		code = http.StatusServiceUnavailable
+	default:
+		code = http.StatusInternalServerError
+	}
+
+	return &APIError{
+		StatusCode: code,
+		Message:    st.Message(),
+	}
+}
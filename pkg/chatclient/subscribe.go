@@ -0,0 +1,61 @@
+package chatclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	chatv1 "github.com/Krokozabra213/test_api/pkg/api/chat/v1"
+)
+
+// Subscribe streams events of the chat as they happen. The sequence ends
+// when ctx is cancelled, when the chat is deleted, or after the first error.
+// Breaking out of the loop closes the stream.
+func (c *Client) Subscribe(ctx context.Context, chatID int64) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		if c.grpcConn == nil {
+			yield(Event{}, ErrNoStream)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := chatv1.NewChatServiceClient(c.grpcConn).SubscribeChat(ctx, &chatv1.SubscribeChatRequest{
+			ChatId: chatID,
+		})
+		if err != nil {
+			yield(Event{}, grpcError(err))
+			return
+		}
+
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				if errors.Is(err, io.EOF) || status.Code(err) == codes.Canceled && ctx.Err() != nil {
+					return
+				}
+				yield(Event{}, grpcError(err))
+				return
+			}
+			if !yield(fromEvent(event), nil) {
+				return
+			}
+		}
+	}
+}
+
+// fromEvent converts a streamed event to the domain event.
+func fromEvent(event *chatv1.Event) Event {
+	return Event{
+		ID:         event.GetId(),
+		Type:       EventType(event.GetType()),
+		ChatID:     event.GetChatId(),
+		OccurredAt: event.GetOccurredAt().AsTime(),
+		Payload:    event.GetPayload(),
+	}
+}
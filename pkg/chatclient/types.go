@@ -0,0 +1,46 @@
+package chatclient
+
+import "github.com/Krokozabra213/test_api/internal/domain"
+
+// Types of the API resources, shared with the server so the client stays in
+// sync with its payloads.
+type (
+	Chat              = domain.Chat
+	ChatSummary       = domain.ChatSummary
+	ChatMessageOutput = domain.ChatMessageOutput
+	ReadState         = domain.ReadState
+	Message           = domain.Message
+	Attachment        = domain.Attachment
+	PinnedMessage     = domain.PinnedMessage
+
+	Bot            = domain.Bot
+	CreatedBot     = domain.CreatedBot
+	CreateBotInput = domain.CreateBotInput
+	CommandNames   = domain.CommandNames
+
+	Webhook            = domain.Webhook
+	CreateWebhookInput = domain.CreateWebhookInput
+	WebhookDelivery    = domain.WebhookDelivery
+	DeliveryAttempt    = domain.DeliveryAttempt
+
+	Event      = domain.Event
+	EventType  = domain.EventType
+	EventTypes = domain.EventTypes
+)
+
+// Event types.
+const (
+	EventChatCreated     = domain.EventChatCreated
+	EventChatDeleted     = domain.EventChatDeleted
+	EventChatRestored    = domain.EventChatRestored
+	EventMessageCreated  = domain.EventMessageCreated
+	EventMessagePinned   = domain.EventMessagePinned
+	EventMessageUnpinned = domain.EventMessageUnpinned
+)
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = domain.DeliveryPending
+	DeliveryDelivered = domain.DeliveryDelivered
+	DeliveryFailed    = domain.DeliveryFailed
+)
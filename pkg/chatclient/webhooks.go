@@ -0,0 +1,93 @@
+package chatclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// CreateWebhook subscribes a URL to the chat events.
+func (c *Client) CreateWebhook(ctx context.Context, input CreateWebhookInput) (*Webhook, error) {
+	body, err := jsonBody(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var webhook Webhook
+	_, err = c.call(ctx, request{
+		method:      http.MethodPost,
+		path:        "/webhooks",
+		body:        body,
+		contentType: "application/json",
+	}, &webhook)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// ListWebhooks returns all webhooks.
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	_, err := c.call(ctx, request{
+		method: http.MethodGet,
+		path:   "/webhooks",
+	}, &webhooks)
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// GetWebhook returns the webhook.
+func (c *Client) GetWebhook(ctx context.Context, webhookID int64) (*Webhook, error) {
+	var webhook Webhook
+	_, err := c.call(ctx, request{
+		method: http.MethodGet,
+		path:   webhookPath(webhookID),
+	}, &webhook)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// DeleteWebhook removes the webhook together with its pending deliveries.
+func (c *Client) DeleteWebhook(ctx context.Context, webhookID int64) error {
+	_, err := c.call(ctx, request{
+		method: http.MethodDelete,
+		path:   webhookPath(webhookID),
+	}, nil)
+	return err
+}
+
+// EnableWebhook re-enables a webhook disabled after repeated delivery failures.
+func (c *Client) EnableWebhook(ctx context.Context, webhookID int64) (*Webhook, error) {
+	var webhook Webhook
+	_, err := c.call(ctx, request{
+		method: http.MethodPost,
+		path:   webhookPath(webhookID) + "/enable",
+	}, &webhook)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// ListWebhookDeliveries returns the latest deliveries of the webhook with their attempt log.
+func (c *Client) ListWebhookDeliveries(ctx context.Context, webhookID int64, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	_, err := c.call(ctx, request{
+		method: http.MethodGet,
+		path:   webhookPath(webhookID) + "/deliveries",
+		query:  limitQuery(limit),
+	}, &deliveries)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func webhookPath(webhookID int64) string {
+	return "/webhooks/" + strconv.FormatInt(webhookID, 10)
+}
@@ -0,0 +1,91 @@
+package postgresclient
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// primaryDSN returns the connection string of the primary.
+func (c PGConfig) primaryDSN() (string, error) {
+	dsn := c.url
+	if dsn == "" {
+		dsn = fmt.Sprintf(
+			"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			c.host, c.port, c.user, c.password, c.dbName, c.sslMode,
+		)
+	}
+	return withParams(dsn, c.sessionParams(true))
+}
+
+// replicaDSNs returns the connection strings of the replicas.
+func (c PGConfig) replicaDSNs() ([]string, error) {
+	dsns := make([]string, 0, len(c.replicas))
+	for i, replica := range c.replicas {
+		dsn, err := withParams(replica, c.sessionParams(false))
+		if err != nil {
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		dsns = append(dsns, dsn)
+	}
+	return dsns, nil
+}
+
+func (c PGConfig) sessionParams(primary bool) map[string]string {
+	params := map[string]string{}
+	if c.applicationName != "" {
+		params["application_name"] = c.applicationName
+	}
+	if c.statementTimeout > 0 {
+		params["statement_timeout"] = strconv.FormatInt(c.statementTimeout.Milliseconds(), 10)
+	}
+	if primary && c.targetSessionAttrs != "" {
+		params["target_session_attrs"] = c.targetSessionAttrs
+	}
+	return params
+}
+
+// withParams sets the parameters on the URL or key=value DSN, replacing
+// the ones it already has.
+func withParams(dsn string, params map[string]string) (string, error) {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			// The error quotes the URL, which may hold the password
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			return "", fmt.Errorf("%w: %w", ErrInvalidDSN, err)
+		}
+		query := u.Query()
+		for key, value := range params {
+			query.Set(key, value)
+		}
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+
+	if strings.Contains(dsn, "://") || (dsn != "" && !strings.Contains(dsn, "=")) {
+		return "", fmt.Errorf("%w: want postgres:// url or key=value pairs", ErrInvalidDSN)
+	}
+
+	// Later pairs take precedence over earlier ones
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		dsn += " " + key + "=" + quoteValue(params[key])
+	}
+	return strings.TrimSpace(dsn), nil
+}
+
+// quoteValue quotes a key=value DSN value.
+func quoteValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
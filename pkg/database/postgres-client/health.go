@@ -0,0 +1,151 @@
+package postgresclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// State is the last known state of a connection pool.
+type State int32
+
+const (
+	// StateConnecting is the state before the first check.
+	StateConnecting State = iota
+	// StateUp means the last ping succeeded.
+	StateUp
+	// StateDown means the last ping failed.
+	StateDown
+)
+
+func (s State) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateUp:
+		return "up"
+	case StateDown:
+		return "down"
+	default:
+		return fmt.Sprintf("State(%d)", int32(s))
+	}
+}
+
+// State returns the last known state of the primary.
+func (p *PostgresClient) State() State {
+	return p.primary.State()
+}
+
+// Ready reports whether the primary can serve requests, for readiness checks.
+// It uses the state found by the health monitor, or pings without one.
+func (p *PostgresClient) Ready(ctx context.Context) error {
+	if p.stopMonitor == nil {
+		p.check(ctx, p.primary)
+	}
+	if state := p.State(); state != StateUp {
+		return fmt.Errorf("%w: primary %s", ErrNotReady, state)
+	}
+	return nil
+}
+
+// connect pings the primary up to attempts times. The delay after the first
+// failure is backoff, doubled after every next one up to maxBackoff, with
+// jitter so that restarted instances do not retry in step.
+func (p *PostgresClient) connect(ctx context.Context, attempts int, backoff, maxBackoff time.Duration) error {
+	delay := backoff
+	for attempt := 1; ; attempt++ {
+		err := p.primary.ping(ctx)
+		if err == nil {
+			p.primary.setState(StateUp)
+			return nil
+		}
+		if attempt >= attempts || ctx.Err() != nil {
+			p.primary.setState(StateDown)
+			return fmt.Errorf("%w: ping after %d attempts: %w", ErrFailedConnect, attempt, err)
+		}
+
+		wait := jitter(delay)
+		p.log.Warn("database not ready, retrying",
+			"attempt", attempt,
+			"delay", wait,
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			p.primary.setState(StateDown)
+			return fmt.Errorf("%w: %w", ErrFailedConnect, ctx.Err())
+		case <-time.After(wait):
+		}
+		delay = min(delay*2, maxBackoff)
+	}
+}
+
+// monitor pings every pool at the interval until ctx is done. The pools open
+// new connections in place of broken ones, so a ping after an outage also
+// reconnects.
+func (p *PostgresClient) monitor(ctx context.Context, interval time.Duration) {
+	defer close(p.monitorDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.check(ctx, p.primary)
+		for _, replica := range p.replicas {
+			p.check(ctx, replica)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check pings the pool and logs the change of its state, if any.
+func (p *PostgresClient) check(ctx context.Context, c *conn) {
+	err := c.ping(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+
+	next := StateUp
+	if err != nil {
+		next = StateDown
+	}
+	prev := c.setState(next)
+
+	switch {
+	case prev == next:
+	case next == StateUp && prev == StateDown:
+		p.log.Info("database connection restored", "pool", c.name)
+	case next == StateUp:
+		p.log.Info("database connected", "pool", c.name)
+	case prev == StateUp:
+		p.log.Error("database connection lost", "pool", c.name, "error", err)
+	default:
+		p.log.Warn("database unavailable", "pool", c.name, "error", err)
+	}
+}
+
+// State returns the last known state of the pool.
+func (c *conn) State() State {
+	return State(c.state.Load())
+}
+
+// setState stores the state and returns the previous one.
+func (c *conn) setState(state State) State {
+	return State(c.state.Swap(int32(state)))
+}
+
+func (c *conn) ping(ctx context.Context) error {
+	sqlDB, err := c.db.DB()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedSQLDB, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,138 @@
+package postgresclient
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// fakeDriver is a database that refuses connections while down and
+// the first upAfter connection attempts.
+type fakeDriver struct {
+	down     atomic.Bool
+	upAfter  int32
+	attempts atomic.Int32
+	pings    atomic.Int32
+}
+
+var errRefused = errors.New("connection refused")
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	attempt := d.attempts.Add(1)
+	if d.down.Load() || attempt <= d.upAfter {
+		return nil, errRefused
+	}
+	return fakeConn{d}, nil
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c fakeConn) Ping(context.Context) error {
+	c.d.pings.Add(1)
+	if c.d.down.Load() {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+type fakeConnector struct{ d *fakeDriver }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return c.d.Open("") }
+func (c fakeConnector) Driver() driver.Driver                        { return c.d }
+
+func (d *fakeDriver) dialector() gorm.Dialector {
+	return postgres.New(postgres.Config{Conn: sql.OpenDB(fakeConnector{d})})
+}
+
+func TestNew_RetriesUntilUp(t *testing.T) {
+	primary := &fakeDriver{upAfter: 3}
+	cfg := NewPGConfig("", "", "", "", "", "", 2, 1, 0).WithRetry(5, time.Millisecond, 2*time.Millisecond)
+
+	client, err := newClient(context.Background(), cfg, slog.New(slog.DiscardHandler), primary.dialector(), nil)
+	require.NoError(t, err)
+	defer client.Shutdown(time.Second)
+
+	assert.Equal(t, int32(4), primary.attempts.Load(), "connected on the first attempt after the outage")
+	assert.Equal(t, StateUp, client.State())
+	require.NoError(t, client.Ready(context.Background()))
+}
+
+func TestNew_GivesUp(t *testing.T) {
+	primary := &fakeDriver{}
+	primary.down.Store(true)
+	cfg := NewPGConfig("", "", "", "", "", "", 2, 1, 0).WithRetry(3, time.Millisecond, time.Millisecond)
+
+	_, err := newClient(context.Background(), cfg, slog.New(slog.DiscardHandler), primary.dialector(), nil)
+	require.ErrorIs(t, err, ErrFailedConnect)
+	assert.ErrorContains(t, err, "after 3 attempts")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = newClient(ctx, cfg.WithRetry(100, time.Hour, time.Hour), slog.New(slog.DiscardHandler), primary.dialector(), nil)
+	assert.ErrorIs(t, err, ErrFailedConnect, "cancellation stops retrying")
+}
+
+func TestMonitor_StateTransitions(t *testing.T) {
+	primary, replica := &fakeDriver{}, &fakeDriver{}
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, nil))
+	cfg := NewPGConfig("", "", "", "", "", "", 2, 1, 0).WithHealthCheck(time.Millisecond)
+
+	client, err := newClient(context.Background(), cfg, log, primary.dialector(), []gorm.Dialector{replica.dialector()})
+	require.NoError(t, err)
+	defer client.Shutdown(time.Second)
+
+	require.Eventually(t, func() bool { return client.replicas[0].State() == StateUp }, time.Second, time.Millisecond)
+
+	primary.down.Store(true)
+	replica.down.Store(true)
+	require.Eventually(t, func() bool { return client.State() == StateDown }, time.Second, time.Millisecond)
+	require.ErrorIs(t, client.Ready(context.Background()), ErrNotReady)
+	require.Eventually(t, func() bool { return client.nextReplica() == nil }, time.Second, time.Millisecond,
+		"replicas found down are skipped")
+
+	primary.down.Store(false)
+	require.Eventually(t, func() bool { return client.State() == StateUp }, time.Second, time.Millisecond)
+	require.NoError(t, client.Shutdown(time.Second))
+
+	assert.Contains(t, logs.String(), `msg="database connected" pool="replica 0"`)
+	assert.Contains(t, logs.String(), `msg="database connection lost" pool=primary`)
+	assert.Contains(t, logs.String(), `msg="database connection restored" pool=primary`)
+}
+
+func TestReadReplica_FallsBackToPrimary(t *testing.T) {
+	primary, replica := &fakeDriver{}, &fakeDriver{}
+	cfg := NewPGConfig("", "", "", "", "", "", 2, 1, 0)
+
+	client, err := newClient(context.Background(), cfg, slog.New(slog.DiscardHandler), primary.dialector(), []gorm.Dialector{replica.dialector()})
+	require.NoError(t, err)
+	defer client.Shutdown(time.Second)
+
+	var used []*gorm.DB
+	err = client.ReadReplica(context.Background(), func(db *gorm.DB) error {
+		used = append(used, db)
+		if len(used) == 1 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, used, 2)
+	assert.Same(t, client.replicas[0].db.Statement.ConnPool, used[0].Statement.ConnPool)
+	assert.Same(t, client.DB.Statement.ConnPool, used[1].Statement.ConnPool)
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"gorm.io/driver/postgres"
@@ -19,60 +21,191 @@ var (
 	ErrFailedConnect = errors.New("failed to connect to database")
 	ErrFailedSQLDB   = errors.New("failed to get sql.DB")
 	ErrTimeout       = errors.New("timeout occurred")
+	ErrInvalidDSN    = errors.New("invalid database url")
+	ErrNotReady      = errors.New("database not ready")
+	// ErrNotReplicated is returned by a ReadReplica query whose result lacks rows known to exist.
+	ErrNotReplicated = errors.New("rows not replicated yet")
 )
 
 // PostgresClient wraps gorm.DB with additional functionality.
+// The embedded DB is the primary.
 type PostgresClient struct {
 	*gorm.DB
+
+	log      *slog.Logger
+	primary  *conn
+	replicas []*conn
+	next     atomic.Uint64
+
+	// Transaction retry settings
+	txAttempts   int
+	txBackoff    time.Duration
+	txMaxBackoff time.Duration
+
+	stopMonitor context.CancelFunc
+	monitorDone chan struct{}
+}
+
+// conn is a connection pool with its last known state.
+type conn struct {
+	name  string
+	db    *gorm.DB
+	state atomic.Int32
 }
 
 // New creates and configures PostgreSQL client with connection pool.
-// Verifies connection with ping before returning.
-func New(cfg PGConfig) (*PostgresClient, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.host, cfg.port, cfg.user, cfg.password, cfg.dbName, cfg.sslMode,
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// Verifies connection to the primary with ping before returning, retrying
+// as configured until ctx is done; replicas connect on first use, so an
+// unavailable replica does not fail startup.
+func New(ctx context.Context, cfg PGConfig, log *slog.Logger) (*PostgresClient, error) {
+	dsn, err := cfg.primaryDSN()
+	if err != nil {
+		return nil, err
+	}
+	replicaDSNs, err := cfg.replicaDSNs()
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := make([]gorm.Dialector, 0, len(replicaDSNs))
+	for _, replicaDSN := range replicaDSNs {
+		replicas = append(replicas, postgres.Open(replicaDSN))
+	}
+	return newClient(ctx, cfg, log, postgres.Open(dsn), replicas)
+}
+
+func newClient(ctx context.Context, cfg PGConfig, log *slog.Logger,
+	primary gorm.Dialector, replicas []gorm.Dialector,
+) (*PostgresClient, error) {
+	// Connections are verified by connect below
+	gormConfig := &gorm.Config{DisableAutomaticPing: true}
+
+	db, err := gorm.Open(primary, gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrFailedConnect, err)
 	}
 
+	client := &PostgresClient{
+		DB:           db,
+		log:          log,
+		primary:      &conn{name: "primary", db: db},
+		txAttempts:   cfg.txAttempts,
+		txBackoff:    cfg.txBackoff,
+		txMaxBackoff: cfg.txMaxBackoff,
+	}
+	for i, dialector := range replicas {
+		replica, err := gorm.Open(dialector, gormConfig)
+		if err != nil {
+			_ = client.close()
+			return nil, fmt.Errorf("%w: replica %d: %w", ErrFailedConnect, i, err)
+		}
+		client.replicas = append(client.replicas, &conn{name: fmt.Sprintf("replica %d", i), db: replica})
+	}
+
 	// Configure connection pool
-	sqlDB, err := db.DB()
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrFailedSQLDB, err)
+	if err := client.SetPool(cfg.maxOpenConns, cfg.maxIdleConns, cfg.connMaxLifetime); err != nil {
+		_ = client.close()
+		return nil, err
 	}
 
-	sqlDB.SetMaxOpenConns(cfg.maxOpenConns)
-	sqlDB.SetMaxIdleConns(cfg.maxIdleConns)
-	sqlDB.SetConnMaxLifetime(cfg.connMaxLifetime)
+	if err := client.connect(ctx, cfg.connectAttempts, cfg.connectBackoff, cfg.connectMaxBackoff); err != nil {
+		_ = client.close()
+		return nil, err
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
-	defer cancel()
+	if cfg.healthCheckInterval > 0 {
+		monitorCtx, stop := context.WithCancel(context.Background())
+		client.stopMonitor = stop
+		client.monitorDone = make(chan struct{})
+		go client.monitor(monitorCtx, cfg.healthCheckInterval)
+	}
 
-	if err := sqlDB.PingContext(ctx); err != nil {
-		return nil, fmt.Errorf("ping: %w", err)
+	return client, nil
+}
+
+// ReadReplica runs the read-only query on the replicas in turn, skipping the
+// ones found down, or on the primary without available replicas. A query
+// failed on a replica is run again on the primary unless the context is done;
+// this also covers rows that have not been replicated yet. A query may return
+// ErrNotReplicated to have its result read from the primary, which is accepted
+// as it is there. The query must not write. Within a transaction started by
+// WithTx the query runs in the transaction.
+func (p *PostgresClient) ReadReplica(ctx context.Context, query func(db *gorm.DB) error) error {
+	if tx, ok := txFromContext(ctx); ok {
+		return readPrimary(query(tx.WithContext(ctx)))
+	}
+
+	replica := p.nextReplica()
+	if replica == nil {
+		return readPrimary(query(p.DB.WithContext(ctx)))
 	}
 
-	return &PostgresClient{db}, nil
+	err := query(replica.db.WithContext(ctx))
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	return readPrimary(query(p.DB.WithContext(ctx)))
+}
+
+// readPrimary returns the error of a query run on the primary, where every row exists.
+func readPrimary(err error) error {
+	if errors.Is(err, ErrNotReplicated) {
+		return nil
+	}
+	return err
+}
+
+// nextReplica returns the next replica not known to be down, if any.
+func (p *PostgresClient) nextReplica() *conn {
+	for range p.replicas {
+		replica := p.replicas[p.next.Add(1)%uint64(len(p.replicas))]
+		if replica.State() != StateDown {
+			return replica
+		}
+	}
+	return nil
 }
 
-// Shutdown gracefully closes database connection with timeout.
+// pools returns the primary followed by the replicas.
+func (p *PostgresClient) pools() []*gorm.DB {
+	pools := []*gorm.DB{p.DB}
+	for _, replica := range p.replicas {
+		pools = append(pools, replica.db)
+	}
+	return pools
+}
+
+// SetPool resizes the connection pools of the primary and each replica.
+// Connections over the new limits are closed as they are returned to the pool.
+func (p *PostgresClient) SetPool(maxOpenConns, maxIdleConns int, connMaxLifetime time.Duration) error {
+	for _, pool := range p.pools() {
+		sqlDB, err := pool.DB()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedSQLDB, err)
+		}
+
+		sqlDB.SetMaxOpenConns(maxOpenConns)
+		sqlDB.SetMaxIdleConns(maxIdleConns)
+		sqlDB.SetConnMaxLifetime(connMaxLifetime)
+	}
+	return nil
+}
+
+// Shutdown stops the health monitor and gracefully closes database
+// connections with timeout.
 func (p *PostgresClient) Shutdown(shutDownTimeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(context.Background(), shutDownTimeout)
 	defer cancel()
 
-	sqlDB, err := p.DB.DB()
-	if err != nil {
-		return fmt.Errorf("%w: %w", ErrFailedSQLDB, err)
+	if p.stopMonitor != nil {
+		p.stopMonitor()
+		<-p.monitorDone
 	}
 
 	// Канал для результата закрытия
 	done := make(chan error, 1)
 	go func() {
-		done <- sqlDB.Close()
+		done <- p.close()
 	}()
 
 	select {
@@ -82,3 +215,17 @@ func (p *PostgresClient) Shutdown(shutDownTimeout time.Duration) error {
 		return err
 	}
 }
+
+// close closes the connection pools.
+func (p *PostgresClient) close() error {
+	var errs []error
+	for _, pool := range p.pools() {
+		sqlDB, err := pool.DB()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %w", ErrFailedSQLDB, err))
+			continue
+		}
+		errs = append(errs, sqlDB.Close())
+	}
+	return errors.Join(errs...)
+}
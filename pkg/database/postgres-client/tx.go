@@ -0,0 +1,109 @@
+package postgresclient
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Transaction retry defaults, used unless set by WithTxRetry or TxOptions.
+const (
+	defaultTxAttempts   = 3
+	defaultTxBackoff    = 20 * time.Millisecond
+	defaultTxMaxBackoff = 500 * time.Millisecond
+)
+
+// TxOptions configures a transaction run by WithTx. Zero values keep the
+// server's isolation level, a read-write transaction and the client's retry settings.
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+
+	// MaxAttempts bounds the runs of a transaction failed with a
+	// serialization failure, deadlock or lock timeout.
+	MaxAttempts int
+	// Backoff is the delay after the first failed run, doubled after
+	// every next one up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+type txKey struct{}
+
+// WithTx runs fn in a transaction on the primary, committed when fn returns
+// nil and rolled back otherwise. Queries on WithContext(ctx) of the context
+// passed to fn join the transaction.
+//
+// A transaction failed with a serialization failure, deadlock or lock timeout
+// is run again from the start, so fn must not have side effects outside the
+// database. Called within another transaction, WithTx runs fn in a savepoint
+// of it instead; options do not apply and failures are retried by the outermost call.
+func (p *PostgresClient) WithTx(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+	}
+
+	attempts := cmp.Or(opts.MaxAttempts, p.txAttempts, defaultTxAttempts)
+	delay := cmp.Or(opts.Backoff, p.txBackoff, defaultTxBackoff)
+	maxBackoff := cmp.Or(opts.MaxBackoff, p.txMaxBackoff, defaultTxMaxBackoff)
+	txOpts := &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly}
+
+	for attempt := 1; ; attempt++ {
+		err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		}, txOpts)
+		if err == nil || !isRetryable(err) || attempt >= attempts || ctx.Err() != nil {
+			return err
+		}
+
+		wait := jitter(delay)
+		p.log.Debug("transaction conflict, retrying",
+			"attempt", attempt,
+			"delay", wait,
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay = min(delay*2, maxBackoff)
+	}
+}
+
+// WithContext returns a session of the transaction started by WithTx on ctx,
+// or of the primary outside of one.
+func (p *PostgresClient) WithContext(ctx context.Context) *gorm.DB {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return p.DB.WithContext(ctx)
+}
+
+// InTx reports whether ctx carries a transaction started by WithTx.
+func InTx(ctx context.Context) bool {
+	_, ok := txFromContext(ctx)
+	return ok
+}
+
+func txFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok
+}
+
+// isRetryable reports whether the transaction failed on a conflict with
+// another one and may succeed when run again.
+func isRetryable(err error) bool {
+	var pgError *pgconn.PgError
+	if errors.As(err, &pgError) {
+		return isRetryableTx(pgError)
+	}
+	return errors.Is(err, ErrTransaction)
+}
@@ -0,0 +1,260 @@
+package postgresclient
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// txDriver is a database that records transactions and fails the commits queued in commitErrs.
+// Every query returns its version.
+type txDriver struct {
+	mu         sync.Mutex
+	events     []string
+	opts       []driver.TxOptions
+	commitErrs []error
+	version    int64
+}
+
+func (d *txDriver) record(event string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.events = append(d.events, event)
+}
+
+func (d *txDriver) Events() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.events...)
+}
+
+func (d *txDriver) Open(string) (driver.Conn, error) { return txConn{d}, nil }
+
+type txConn struct{ d *txDriver }
+
+func (txConn) Ping(context.Context) error { return nil }
+
+func (c txConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.d.mu.Lock()
+	c.d.opts = append(c.d.opts, opts)
+	c.d.mu.Unlock()
+	c.d.record("begin")
+	return txTx{c.d}, nil
+}
+
+func (c txConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	// Savepoint names are unique per transaction
+	fields := strings.Fields(query)
+	c.d.record(strings.Join(fields[:len(fields)-1], " "))
+	return driver.RowsAffected(0), nil
+}
+
+func (c txConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &versionRows{version: c.d.version}, nil
+}
+
+type versionRows struct {
+	version int64
+	done    bool
+}
+
+func (*versionRows) Columns() []string { return []string{"version"} }
+func (*versionRows) Close() error      { return nil }
+
+func (r *versionRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0], r.done = r.version, true
+	return nil
+}
+
+func (txConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (txConn) Close() error                        { return nil }
+func (txConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+type txTx struct{ d *txDriver }
+
+func (t txTx) Commit() error {
+	t.d.record("commit")
+	t.d.mu.Lock()
+	defer t.d.mu.Unlock()
+	if len(t.d.commitErrs) == 0 {
+		return nil
+	}
+	err := t.d.commitErrs[0]
+	t.d.commitErrs = t.d.commitErrs[1:]
+	return err
+}
+
+func (t txTx) Rollback() error {
+	t.d.record("rollback")
+	return nil
+}
+
+type txConnector struct{ d *txDriver }
+
+func (c txConnector) Connect(context.Context) (driver.Conn, error) { return c.d.Open("") }
+func (c txConnector) Driver() driver.Driver                        { return c.d }
+
+func (d *txDriver) dialector() gorm.Dialector {
+	return postgres.New(postgres.Config{Conn: sql.OpenDB(txConnector{d})})
+}
+
+func newTxClient(t *testing.T, d *txDriver, cfg PGConfig, replicas ...*txDriver) *PostgresClient {
+	t.Helper()
+	replicaDialectors := make([]gorm.Dialector, 0, len(replicas))
+	for _, replica := range replicas {
+		replicaDialectors = append(replicaDialectors, replica.dialector())
+	}
+	client, err := newClient(context.Background(), cfg, slog.New(slog.DiscardHandler), d.dialector(), replicaDialectors)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Shutdown(time.Second) })
+	return client
+}
+
+func TestWithTx_RetriesConflicts(t *testing.T) {
+	d := &txDriver{commitErrs: []error{&pgconn.PgError{Code: CodeSerializationFailure}}}
+	cfg := NewPGConfig("", "", "", "", "", "", 2, 1, 0).WithTxRetry(3, time.Millisecond, time.Millisecond)
+	client := newTxClient(t, d, cfg)
+
+	runs := 0
+	err := client.WithTx(context.Background(), TxOptions{}, func(context.Context) error {
+		runs++
+		if runs == 1 {
+			return &pgconn.PgError{Code: CodeDeadlockDetected}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, runs, "a failed run and a failed commit are retried")
+	assert.Equal(t, []string{"begin", "rollback", "begin", "commit", "begin", "commit"}, d.Events())
+}
+
+func TestWithTx_GivesUp(t *testing.T) {
+	d := &txDriver{}
+	client := newTxClient(t, d, NewPGConfig("", "", "", "", "", "", 2, 1, 0))
+
+	runs := 0
+	opts := TxOptions{MaxAttempts: 2, Backoff: time.Millisecond}
+	err := client.WithTx(context.Background(), opts, func(context.Context) error {
+		runs++
+		return &pgconn.PgError{Code: CodeLockNotAvailable}
+	})
+	assert.Equal(t, 2, runs)
+	assert.ErrorIs(t, ErrorWrapper(err), ErrTransaction)
+
+	runs = 0
+	errFailed := errors.New("failed")
+	err = client.WithTx(context.Background(), opts, func(context.Context) error {
+		runs++
+		return errFailed
+	})
+	assert.ErrorIs(t, err, errFailed)
+	assert.Equal(t, 1, runs, "other errors are not retried")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runs = 0
+	err = client.WithTx(ctx, TxOptions{MaxAttempts: 5, Backoff: time.Hour}, func(context.Context) error {
+		runs++
+		cancel()
+		return &pgconn.PgError{Code: CodeSerializationFailure}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, runs, "cancellation stops retrying")
+}
+
+func TestWithTx_Options(t *testing.T) {
+	d := &txDriver{}
+	client := newTxClient(t, d, NewPGConfig("", "", "", "", "", "", 2, 1, 0))
+
+	opts := TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}
+	require.NoError(t, client.WithTx(context.Background(), opts, func(context.Context) error { return nil }))
+	require.Len(t, d.opts, 1)
+	assert.Equal(t, driver.IsolationLevel(sql.LevelSerializable), d.opts[0].Isolation)
+	assert.True(t, d.opts[0].ReadOnly)
+}
+
+func TestWithTx_Nested(t *testing.T) {
+	d := &txDriver{}
+	client := newTxClient(t, d, NewPGConfig("", "", "", "", "", "", 2, 1, 0))
+	assert.False(t, InTx(context.Background()))
+
+	errInner := errors.New("inner failed")
+	err := client.WithTx(context.Background(), TxOptions{}, func(ctx context.Context) error {
+		require.True(t, InTx(ctx))
+		outer := client.WithContext(ctx).Statement.ConnPool
+		assert.NotSame(t, client.DB.Statement.ConnPool, outer, "sessions of ctx join the transaction")
+
+		err := client.WithTx(ctx, TxOptions{}, func(ctx context.Context) error {
+			assert.Same(t, outer, client.WithContext(ctx).Statement.ConnPool)
+			return errInner
+		})
+		assert.ErrorIs(t, err, errInner)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"begin", "SAVEPOINT", "ROLLBACK TO SAVEPOINT", "commit"}, d.Events())
+}
+
+func TestReadReplica_InTxReadsPrimary(t *testing.T) {
+	// The replica lags a version behind the primary
+	primary, replica := &txDriver{version: 2}, &txDriver{version: 1}
+	client := newTxClient(t, primary, NewPGConfig("", "", "", "", "", "", 2, 1, 0), replica)
+
+	readVersion := func(ctx context.Context) int64 {
+		var version int64
+		err := client.ReadReplica(ctx, func(db *gorm.DB) error {
+			return db.Raw("SELECT version").Scan(&version).Error
+		})
+		require.NoError(t, err)
+		return version
+	}
+	assert.Equal(t, int64(1), readVersion(context.Background()), "reads outside a transaction go to the replica")
+
+	err := client.WithTx(context.Background(), TxOptions{ReadOnly: true}, func(ctx context.Context) error {
+		var version int64
+		require.NoError(t, client.WithContext(ctx).Raw("SELECT version").Scan(&version).Error)
+		assert.Equal(t, version, readVersion(ctx), "reads in a transaction see the version read before them")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, replica.Events())
+}
+
+func TestReadReplica_NotReplicatedReadsPrimary(t *testing.T) {
+	// The replica lags a version behind the primary
+	primary, replica := &txDriver{version: 2}, &txDriver{version: 1}
+	client := newTxClient(t, primary, NewPGConfig("", "", "", "", "", "", 2, 1, 0), replica)
+
+	readAtLeast := func(want int64) int64 {
+		var version int64
+		err := client.ReadReplica(context.Background(), func(db *gorm.DB) error {
+			if err := db.Raw("SELECT version").Scan(&version).Error; err != nil {
+				return err
+			}
+			if version < want {
+				return ErrNotReplicated
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return version
+	}
+	assert.Equal(t, int64(1), readAtLeast(1), "a replica up to date is read")
+	assert.Equal(t, int64(2), readAtLeast(2), "a lagging replica is bypassed for the primary")
+	assert.Equal(t, int64(2), readAtLeast(3), "the result of the primary is accepted as it is")
+}
@@ -2,6 +2,8 @@ package postgresclient
 
 import (
 	"errors"
+	"math/rand/v2"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
@@ -40,3 +42,9 @@ func isRetryableTx(err *pgconn.PgError) bool {
 		return false
 	}
 }
+
+// jitter returns a random delay between half of d and d, so that clients
+// failed at once do not retry in step.
+func jitter(d time.Duration) time.Duration {
+	return d/2 + rand.N(d/2+1)
+}
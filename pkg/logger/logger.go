@@ -12,28 +12,41 @@ const (
 )
 
 // Init creates logger for given environment and sets it as default.
-// Returns logger instance for dependency injection.
-func Init(env string) *slog.Logger {
-	log := SetupLogger(env)
+// Returns logger instance for dependency injection and its level,
+// which can be changed at runtime.
+func Init(env string) (*slog.Logger, *slog.LevelVar) {
+	level := new(slog.LevelVar)
+	log := SetupLogger(env, level)
 	slog.SetDefault(log)
-	return log
+	return log, level
+}
+
+// DefaultLevel returns the log level of the environment.
+func DefaultLevel(env string) slog.Level {
+	if env == EnvLocal {
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
 }
 
-func SetupLogger(env string) *slog.Logger {
+// SetupLogger creates logger for given environment. The level is set to the
+// environment default and keeps controlling the logger afterwards.
+func SetupLogger(env string, level *slog.LevelVar) *slog.Logger {
 	var log *slog.Logger
 
+	level.Set(DefaultLevel(env))
 	switch env {
 	case EnvLocal:
 		log = slog.New(
-			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level}),
 		)
 	case EnvProd:
 		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level}),
 		)
 	default:
 		return slog.New(
-			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level}),
 		)
 	}
 	return log
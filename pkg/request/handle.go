@@ -19,9 +19,9 @@ type Validator interface {
 // DecodeAndValidate decodes JSON request body and validates it.
 func DecodeAndValidate[T Validator](r *http.Request) (T, error) {
 	var req T
-	
+
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		return req, fmt.Errorf("%w: %v", ErrDecode, err)
+		return req, fmt.Errorf("%w: %w", ErrDecode, err)
 	}
 
 	if err := req.Validate(); err != nil {
@@ -7,9 +7,11 @@ import (
 	"testing"
 
 	"github.com/Krokozabra213/test_api/internal/domain"
+	"github.com/Krokozabra213/test_api/pkg/chatclient"
 	"github.com/Krokozabra213/test_api/tests/app/suite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 type ErrorResponse struct {
@@ -130,3 +132,708 @@ func TestSendMessage(t *testing.T) {
 	assert.NotZero(t, message.ID)
 	assert.NotZero(t, message.CreatedAt)
 }
+
+func TestSendMessage_Attachments(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/chats", map[string]string{
+		"title": "Test Chat",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var chat domain.Chat
+	err = resp.JSON(&chat)
+	require.NoError(t, err)
+
+	content := []byte("attachment content")
+	path := fmt.Sprintf("/chats/%d/messages", chat.ID)
+	resp, err = st.HTTPClient.POSTMultipart(ctx, path,
+		map[string]string{"text": "With file"},
+		map[string][]byte{"notes.txt": content},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var message domain.Message
+	err = resp.JSON(&message)
+	require.NoError(t, err)
+
+	require.Len(t, message.Attachments, 1)
+	attachment := message.Attachments[0]
+	assert.Equal(t, "notes.txt", attachment.Filename)
+	assert.Equal(t, "text/plain", attachment.ContentType)
+	assert.Equal(t, int64(len(content)), attachment.Size)
+	assert.Len(t, attachment.SHA256, 64)
+
+	path = fmt.Sprintf("/chats/%d/attachments/%d", chat.ID, attachment.ID)
+	resp, err = st.HTTPClient.GET(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, content, resp.Body)
+
+	resp, err = st.HTTPClient.GETWithHeaders(ctx, path, map[string]string{"Range": "bytes=11-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, content[11:], resp.Body)
+	assert.Equal(t, fmt.Sprintf("bytes 11-%d/%d", len(content)-1, len(content)), resp.Headers.Get("Content-Range"))
+}
+
+func TestSendMessage_AttachmentTypeNotAllowed(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/chats", map[string]string{
+		"title": "Test Chat",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var chat domain.Chat
+	err = resp.JSON(&chat)
+	require.NoError(t, err)
+
+	path := fmt.Sprintf("/chats/%d/messages", chat.ID)
+	resp, err = st.HTTPClient.POSTMultipart(ctx, path,
+		map[string]string{"text": "With binary"},
+		map[string][]byte{"app.exe": {0x4d, 0x5a, 0x90, 0x00, 0x03, 0x00}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+}
+
+func TestMarkChatRead(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/chats", map[string]string{
+		"title": "Test Chat",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var chat domain.Chat
+	err = resp.JSON(&chat)
+	require.NoError(t, err)
+
+	messages := make([]domain.Message, 0, 3)
+	for i := range 3 {
+		path := fmt.Sprintf("/chats/%d/messages", chat.ID)
+		resp, err = st.HTTPClient.POST(ctx, path, map[string]string{
+			"text": fmt.Sprintf("Message %d", i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var message domain.Message
+		err = resp.JSON(&message)
+		require.NoError(t, err)
+		messages = append(messages, message)
+	}
+
+	member := map[string]string{"X-Member-ID": "alice"}
+	chatPath := fmt.Sprintf("/chats/%d", chat.ID)
+
+	resp, err = st.HTTPClient.GETWithHeaders(ctx, chatPath, member)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output domain.ChatMessageOutput
+	err = resp.JSON(&output)
+	require.NoError(t, err)
+	require.NotNil(t, output.UnreadCount)
+	assert.Equal(t, int64(3), *output.UnreadCount)
+
+	resp, err = st.HTTPClient.POSTWithHeaders(ctx, chatPath+"/read", map[string]int64{
+		"message_id": messages[1].ID,
+	}, member)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var state domain.ReadState
+	err = resp.JSON(&state)
+	require.NoError(t, err)
+
+	require.NotNil(t, state.LastReadMessageID)
+	assert.Equal(t, messages[1].ID, *state.LastReadMessageID)
+	assert.Equal(t, int64(1), state.UnreadCount)
+
+	resp, err = st.HTTPClient.GETWithHeaders(ctx, "/chats", member)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var chats []domain.ChatSummary
+	err = resp.JSON(&chats)
+	require.NoError(t, err)
+
+	require.Len(t, chats, 1)
+	require.NotNil(t, chats[0].UnreadCount)
+	assert.Equal(t, int64(1), *chats[0].UnreadCount)
+}
+
+func TestPinMessage(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/chats", map[string]string{
+		"title": "Test Chat",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var chat domain.Chat
+	err = resp.JSON(&chat)
+	require.NoError(t, err)
+
+	resp, err = st.HTTPClient.POST(ctx, fmt.Sprintf("/chats/%d/messages", chat.ID), map[string]string{
+		"text": "Announcement",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var message domain.Message
+	err = resp.JSON(&message)
+	require.NoError(t, err)
+
+	pinPath := fmt.Sprintf("/chats/%d/pins/%d", chat.ID, message.ID)
+	resp, err = st.HTTPClient.PUT(ctx, pinPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var pin domain.PinnedMessage
+	err = resp.JSON(&pin)
+	require.NoError(t, err)
+
+	assert.Equal(t, message.ID, pin.MessageID)
+	assert.Equal(t, "Announcement", pin.Message.Text)
+
+	resp, err = st.HTTPClient.GET(ctx, fmt.Sprintf("/chats/%d", chat.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output domain.ChatMessageOutput
+	err = resp.JSON(&output)
+	require.NoError(t, err)
+
+	require.Len(t, output.Pinned, 1)
+	assert.Equal(t, message.ID, output.Pinned[0].MessageID)
+
+	resp, err = st.HTTPClient.DELETE(ctx, pinPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = st.HTTPClient.DELETE(ctx, pinPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestDeleteChat_Restore(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/chats", map[string]string{
+		"title": "Test Chat",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var chat domain.Chat
+	err = resp.JSON(&chat)
+	require.NoError(t, err)
+
+	chatPath := fmt.Sprintf("/chats/%d", chat.ID)
+	resp, err = st.HTTPClient.POST(ctx, chatPath+"/messages", map[string]string{
+		"text": "Survivor",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, err = st.HTTPClient.DELETE(ctx, chatPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = st.HTTPClient.GET(ctx, chatPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = st.HTTPClient.POST(ctx, chatPath+"/restore", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = st.HTTPClient.GET(ctx, chatPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output domain.ChatMessageOutput
+	err = resp.JSON(&output)
+	require.NoError(t, err)
+
+	require.Len(t, output.Messages, 1)
+	assert.Equal(t, "Survivor", output.Messages[0].Text)
+}
+
+func TestDeleteChat_NotFound(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.DELETE(ctx, "/chats/999999999")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestDeleteChat_IfMatch(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/chats", map[string]string{
+		"title": "Test Chat",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var chat domain.Chat
+	err = resp.JSON(&chat)
+	require.NoError(t, err)
+
+	chatPath := fmt.Sprintf("/chats/%d", chat.ID)
+	resp, err = st.HTTPClient.GET(ctx, chatPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	etag := resp.Headers.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	resp, err = st.HTTPClient.POST(ctx, chatPath+"/messages", map[string]string{
+		"text": "Changes the version",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, err = st.HTTPClient.DELETEWithHeaders(ctx, chatPath, map[string]string{"If-Match": etag})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	resp, err = st.HTTPClient.GET(ctx, chatPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err = st.HTTPClient.DELETEWithHeaders(ctx, chatPath, map[string]string{"If-Match": resp.Headers.Get("ETag")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "1", resp.Headers.Get("X-Deleted-Messages"))
+}
+
+func TestGetChat_IfNoneMatch(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	chat, err := st.ChatClient.CreateChat(ctx, "Test Chat")
+	require.NoError(t, err)
+
+	_, err = st.ChatClient.SendMessage(ctx, chat.ID, "first")
+	require.NoError(t, err)
+
+	_, etag, err := st.ChatClient.GetChat(ctx, chat.ID, chatclient.GetChatOptions{MemberID: "alice"})
+	require.NoError(t, err)
+
+	_, _, err = st.ChatClient.GetChat(ctx, chat.ID, chatclient.GetChatOptions{MemberID: "alice", IfNoneMatch: etag})
+	assert.ErrorIs(t, err, chatclient.ErrNotModified)
+
+	// Reading changes the member's unread counter and so the tag
+	_, err = st.ChatClient.MarkChatRead(ctx, chat.ID, "alice", 0)
+	require.NoError(t, err)
+
+	output, newTag, err := st.ChatClient.GetChat(ctx, chat.ID, chatclient.GetChatOptions{MemberID: "alice", IfNoneMatch: etag})
+	require.NoError(t, err)
+	assert.NotEqual(t, etag, newTag)
+	require.NotNil(t, output.UnreadCount)
+	assert.Equal(t, int64(0), *output.UnreadCount)
+
+	// The member tag is a valid precondition for chat mutations
+	_, err = st.ChatClient.DeleteChat(ctx, chat.ID, newTag)
+	require.NoError(t, err)
+}
+
+func TestWebhooks(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/webhooks", map[string]any{
+		"url":         "ftp://example.com/hook",
+		"secret":      "0123456789abcdef",
+		"event_types": []string{"message.created"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = st.HTTPClient.POST(ctx, "/webhooks", map[string]any{
+		"url":         "http://example.com/hook",
+		"secret":      "0123456789abcdef",
+		"event_types": []string{"message.created", "chat.created", "message.created"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var webhook domain.Webhook
+	err = resp.JSON(&webhook)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EventTypes{"chat.created", "message.created"}, webhook.EventTypes)
+	assert.NotContains(t, string(resp.Body), "0123456789abcdef")
+
+	webhookPath := fmt.Sprintf("/webhooks/%d", webhook.ID)
+	resp, err = st.HTTPClient.GET(ctx, webhookPath+"/deliveries")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = st.HTTPClient.DELETE(ctx, webhookPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = st.HTTPClient.GET(ctx, webhookPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestBots_PostMessageAndCommands(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/bots", map[string]any{
+		"name": "greeter",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var bot domain.CreatedBot
+	err = resp.JSON(&bot)
+	require.NoError(t, err)
+	require.NotEmpty(t, bot.Token)
+
+	resp, err = st.HTTPClient.POST(ctx, "/bots", map[string]any{
+		"name": "greeter",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, err = st.HTTPClient.POST(ctx, "/chats", map[string]string{
+		"title": "Test Chat",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chat domain.Chat
+	err = resp.JSON(&chat)
+	require.NoError(t, err)
+
+	messagesPath := fmt.Sprintf("/chats/%d/messages", chat.ID)
+	resp, err = st.HTTPClient.POSTWithHeaders(ctx, messagesPath, map[string]string{
+		"text": "Hello from bot",
+	}, map[string]string{"Authorization": "Bearer " + bot.Token})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var message domain.Message
+	err = resp.JSON(&message)
+	require.NoError(t, err)
+	require.NotNil(t, message.BotID)
+	assert.Equal(t, bot.ID, *message.BotID)
+
+	resp, err = st.HTTPClient.POSTWithHeaders(ctx, messagesPath, map[string]string{
+		"text": "Hello",
+	}, map[string]string{"Authorization": "Bearer wrong"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = st.HTTPClient.POST(ctx, messagesPath, map[string]string{
+		"text": "/stats",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, err = st.HTTPClient.GET(ctx, fmt.Sprintf("/chats/%d", chat.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output domain.ChatMessageOutput
+	err = resp.JSON(&output)
+	require.NoError(t, err)
+	require.Len(t, output.Messages, 3)
+	assert.NotNil(t, output.Messages[0].BotID)
+	assert.Contains(t, output.Messages[0].Text, "Messages: 2")
+}
+
+func TestGraphQL_ChatWithMessages(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/chats", map[string]string{
+		"title": "Test Chat",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chat domain.Chat
+	err = resp.JSON(&chat)
+	require.NoError(t, err)
+
+	messagesPath := fmt.Sprintf("/chats/%d/messages", chat.ID)
+	for _, text := range []string{"first", "second", "third"} {
+		resp, err = st.HTTPClient.POST(ctx, messagesPath, map[string]string{
+			"text": text,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	resp, err = st.HTTPClient.POST(ctx, "/graphql", map[string]any{
+		"query":     `query($id: Int!) { chat(id: $id) { title messages(first: 2) { nodes { text } hasMore endCursor } } }`,
+		"variables": map[string]any{"id": chat.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data struct {
+			Chat struct {
+				Title    string `json:"title"`
+				Messages struct {
+					Nodes []struct {
+						Text string `json:"text"`
+					} `json:"nodes"`
+					HasMore bool `json:"hasMore"`
+				} `json:"messages"`
+			} `json:"chat"`
+		} `json:"data"`
+	}
+	err = resp.JSON(&result)
+	require.NoError(t, err)
+	assert.Equal(t, "Test Chat", result.Data.Chat.Title)
+	require.Len(t, result.Data.Chat.Messages.Nodes, 2)
+	assert.Equal(t, "third", result.Data.Chat.Messages.Nodes[0].Text)
+	assert.True(t, result.Data.Chat.Messages.HasMore)
+}
+
+func TestChatClient_PaginatesMessages(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	chat, err := st.ChatClient.CreateChat(ctx, "Test Chat")
+	require.NoError(t, err)
+
+	for i := range 5 {
+		_, err = st.ChatClient.SendMessage(ctx, chat.ID, fmt.Sprintf("message %d", i))
+		require.NoError(t, err)
+	}
+
+	var texts []string
+	for message, err := range st.ChatClient.Messages(ctx, chat.ID, 2) {
+		require.NoError(t, err)
+		texts = append(texts, message.Text)
+	}
+	assert.Equal(t, []string{"message 4", "message 3", "message 2", "message 1", "message 0"}, texts)
+
+	_, err = st.ChatClient.ListMessages(ctx, chat.ID+1000, 10, 0)
+	assert.ErrorIs(t, err, chatclient.ErrNotFound)
+}
+
+func TestLegacyRoutes_Deprecated(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	resp, err := st.HTTPClient.GET(ctx, "/v1/chats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Headers.Get("Deprecation"))
+
+	resp, err = st.HTTPClient.GET(ctx, "/chats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, resp.Headers.Get("Deprecation"))
+	assert.NotEmpty(t, resp.Headers.Get("Sunset"))
+	assert.Equal(t, `</v1/chats>; rel="successor-version"`, resp.Headers.Get("Link"))
+}
+
+func TestResponses_NegotiatedEncoding(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	chat, err := st.ChatClient.CreateChat(ctx, "Test Chat")
+	require.NoError(t, err)
+	_, err = st.ChatClient.SendMessage(ctx, chat.ID, strings.Repeat("long message ", 200))
+	require.NoError(t, err)
+
+	path := fmt.Sprintf("/v1/chats/%d", chat.ID)
+	resp, err := st.HTTPClient.GETWithHeaders(ctx, path, map[string]string{"Accept-Encoding": "gzip"})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "gzip", resp.Headers.Get("Content-Encoding"))
+
+	resp, err = st.HTTPClient.GETWithHeaders(ctx, path, map[string]string{"Accept": "application/msgpack"})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/msgpack", resp.Headers.Get("Content-Type"))
+
+	var output struct {
+		ID    int64  `msgpack:"id"`
+		Title string `msgpack:"title"`
+	}
+	require.NoError(t, msgpack.Unmarshal(resp.Body, &output))
+	assert.Equal(t, chat.ID, output.ID)
+	assert.Equal(t, "Test Chat", output.Title)
+
+	resp, err = st.HTTPClient.GETWithHeaders(ctx, "/v1/chats/abc", map[string]string{"Accept": "application/msgpack"})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var errResp map[string]string
+	require.NoError(t, msgpack.Unmarshal(resp.Body, &errResp))
+	assert.NotEmpty(t, errResp["error"])
+}
@@ -2,11 +2,13 @@ package suite
 
 import (
 	"context"
+	"log/slog"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/Krokozabra213/test_api/internal/config"
+	"github.com/Krokozabra213/test_api/pkg/chatclient"
 	postgresclient "github.com/Krokozabra213/test_api/pkg/database/postgres-client"
 )
 
@@ -20,6 +22,7 @@ type APISuite struct {
 	Config     *config.Config
 	DB         *postgresclient.PostgresClient
 	HTTPClient *Client
+	ChatClient *chatclient.Client
 }
 
 func New(t *testing.T) (context.Context, *APISuite) {
@@ -27,7 +30,7 @@ func New(t *testing.T) (context.Context, *APISuite) {
 	configFile := filepath.Join("..", "..", "configs", "main.yml")
 	envFile := filepath.Join("..", "..", ".env")
 
-	cfg, err := config.Init(configFile, envFile)
+	cfg, err := config.Init(configFile, envFile, nil)
 	if err != nil {
 		t.Fatalf("config init err: %v", err)
 	}
@@ -44,7 +47,7 @@ func New(t *testing.T) (context.Context, *APISuite) {
 		cfg.Postgres.ConnMaxLifetime,
 	)
 
-	db, err := postgresclient.New(pgConfig)
+	db, err := postgresclient.New(context.Background(), pgConfig, slog.New(slog.DiscardHandler))
 	if err != nil {
 		t.Fatalf("db connect err: %v", err)
 	}
@@ -57,11 +60,17 @@ func New(t *testing.T) (context.Context, *APISuite) {
 	})
 	client := NewClient(localHTTPAddress, nil)
 
+	chatClient, err := chatclient.New(localHTTPAddress)
+	if err != nil {
+		t.Fatalf("chat client init err: %v", err)
+	}
+
 	return ctx, &APISuite{
 		T:          t,
 		Config:     cfg,
 		DB:         db,
 		HTTPClient: client,
+		ChatClient: chatClient,
 	}
 }
 
@@ -71,9 +80,36 @@ func (s *APISuite) CleanupTestData() error {
 		return err
 	}
 
+	err = s.CleanupWebhooks()
+	if err != nil {
+		return err
+	}
+
+	err = s.CleanupBots()
+	if err != nil {
+		return err
+	}
+
 	return s.CleanupChats()
 }
 
+// CleanupBots removes bots created by tests, keeping the built-in system bot.
+func (s *APISuite) CleanupBots() error {
+	result := s.DB.Exec("DELETE FROM bots WHERE token_hash IS NOT NULL")
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (s *APISuite) CleanupWebhooks() error {
+	result := s.DB.Exec("TRUNCATE TABLE webhooks CASCADE")
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
 func (s *APISuite) CleanupMessages() error {
 	result := s.DB.Exec("TRUNCATE TABLE messages CASCADE")
 	if result.Error != nil {
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 )
 
@@ -50,12 +51,67 @@ func (c *Client) POST(ctx context.Context, path string, body any) (*Response, er
 	return c.do(ctx, http.MethodPost, path, body)
 }
 
+// PUT запрос с JSON body
+func (c *Client) PUT(ctx context.Context, path string, body any) (*Response, error) {
+	return c.do(ctx, http.MethodPut, path, body)
+}
+
 // DELETE запрос
 func (c *Client) DELETE(ctx context.Context, path string) (*Response, error) {
 	return c.do(ctx, http.MethodDelete, path, nil)
 }
 
+// GETWithHeaders запрос с дополнительными заголовками
+func (c *Client) GETWithHeaders(ctx context.Context, path string, headers map[string]string) (*Response, error) {
+	return c.doWithHeaders(ctx, http.MethodGet, path, nil, headers)
+}
+
+// DELETEWithHeaders запрос с дополнительными заголовками
+func (c *Client) DELETEWithHeaders(ctx context.Context, path string, headers map[string]string) (*Response, error) {
+	return c.doWithHeaders(ctx, http.MethodDelete, path, nil, headers)
+}
+
+// POSTWithHeaders запрос с JSON body и дополнительными заголовками
+func (c *Client) POSTWithHeaders(ctx context.Context, path string, body any, headers map[string]string) (*Response, error) {
+	return c.doWithHeaders(ctx, http.MethodPost, path, body, headers)
+}
+
+// POSTMultipart запрос с multipart/form-data body: текстовые поля и файлы в поле "files"
+func (c *Client) POSTMultipart(ctx context.Context, path string, fields map[string]string, files map[string][]byte) (*Response, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, fmt.Errorf("write field: %w", err)
+		}
+	}
+	for filename, content := range files {
+		part, err := writer.CreateFormFile("files", filename)
+		if err != nil {
+			return nil, fmt.Errorf("create form file: %w", err)
+		}
+		if _, err := part.Write(content); err != nil {
+			return nil, fmt.Errorf("write form file: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("close multipart: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, &buf)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return c.send(req)
+}
+
 func (c *Client) do(ctx context.Context, method, path string, body any) (*Response, error) {
+	return c.doWithHeaders(ctx, method, path, body, nil)
+}
+
+func (c *Client) doWithHeaders(ctx context.Context, method, path string, body any, headers map[string]string) (*Response, error) {
 	var bodyReader io.Reader
 	if body != nil {
 		data, err := json.Marshal(body)
@@ -73,7 +129,14 @@ func (c *Client) do(ctx context.Context, method, path string, body any) (*Respon
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	return c.send(req)
+}
 
+func (c *Client) send(req *http.Request) (*Response, error) {
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)